@@ -2,19 +2,45 @@ package main
 
 import (
 	"context"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/imlargo/go-api/internal/config"
 	"github.com/imlargo/go-api/internal/database"
+	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/routes"
+	"github.com/imlargo/go-api/internal/service"
 	"github.com/imlargo/go-api/internal/store"
+	"github.com/imlargo/go-api/pkg/auth"
 	"github.com/imlargo/go-api/pkg/medusa/core/app"
+	"github.com/imlargo/go-api/pkg/medusa/core/audit"
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/health"
+	"github.com/imlargo/go-api/pkg/medusa/core/jwt"
 	"github.com/imlargo/go-api/pkg/medusa/core/logger"
+	"github.com/imlargo/go-api/pkg/medusa/core/metrics"
+	"github.com/imlargo/go-api/pkg/medusa/core/querytag"
 	"github.com/imlargo/go-api/pkg/medusa/core/ratelimiter"
 	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
 	"github.com/imlargo/go-api/pkg/medusa/core/responses"
 	"github.com/imlargo/go-api/pkg/medusa/core/server/http"
+	medusaservice "github.com/imlargo/go-api/pkg/medusa/core/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
 	"github.com/imlargo/go-api/pkg/medusa/services/cache"
+	"github.com/imlargo/go-api/pkg/medusa/services/chat"
+	"github.com/imlargo/go-api/pkg/medusa/services/degrade"
+	"github.com/imlargo/go-api/pkg/medusa/services/email"
+	mockemail "github.com/imlargo/go-api/pkg/medusa/services/email/mock"
+	resend "github.com/imlargo/go-api/pkg/medusa/services/email/resend"
+	"github.com/imlargo/go-api/pkg/medusa/services/lock"
+	"github.com/imlargo/go-api/pkg/medusa/services/sse"
 	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+	"github.com/imlargo/go-api/pkg/medusa/services/webhook"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -23,7 +49,9 @@ func main() {
 	logger := logger.NewLogger()
 	defer logger.Sync()
 
-	router := gin.Default()
+	// gin.Default would add its own text access log next to ours
+	router := gin.New()
+	router.Use(gin.Recovery())
 	srv := http.NewServer(
 		router,
 		logger,
@@ -34,6 +62,7 @@ func main() {
 	app := app.NewApp(
 		app.WithName("butter"),
 		app.WithServer(srv),
+		app.WithShutdownTimeout(cfg.Server.ShutdownTimeout),
 	)
 
 	Mount(app, cfg, router, logger)
@@ -43,45 +72,388 @@ func main() {
 
 func Mount(app *app.App, cfg config.Config, router *gin.Engine, logger *logger.Logger) {
 
+	router.Use(middleware.RequestIDMiddleware())
+	// Before the error handler, so the recorded status is the final one
+	router.Use(middleware.NewMetricsMiddleware(metrics.NewPrometheusMetrics()))
+	router.Use(middleware.RequestLoggerMiddleware(logger))
+	router.Use(middleware.ErrorHandlerMiddleware(logger))
+	router.Use(middleware.WarningsMiddleware())
+
 	// Ping
 	router.GET("/ping", func(c *gin.Context) {
 		responses.SuccessOK(c, "hello")
 	})
 
-	// Rate Limiter
-	if cfg.RateLimiter.Enabled {
-		logger.Info("Rate Limiter is enabled")
+	// Encryption
+	if cfg.Encryption.Key != "" {
+		encryptor, err := encryption.NewEncryptorFromBase64(cfg.Encryption.Key)
+		if err != nil {
+			logger.Fatal("Could not initialize encryption: " + err.Error())
+			return
+		}
+		encryption.SetDefault(encryptor)
 	}
-	_ = ratelimiter.NewTokenBucketLimiter(ratelimiter.Config{
-		RequestsPerTimeFrame: cfg.RateLimiter.RequestsPerTimeFrame,
-		TimeFrame:            cfg.RateLimiter.TimeFrame,
-	})
 
 	// Database
-	db, err := database.NewPostgresDatabase(cfg.Database.URL)
+	db, err := database.NewPostgresDatabase(cfg.Database.URL, database.PoolConfigFrom(cfg.Database))
 	if err != nil {
 		logger.Fatal("Could not connect to the database: " + err.Error())
 		return
 	}
+	app.OnShutdown(func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+	if cfg.QueryTags.Enabled {
+		if err := db.Use(querytag.New("api")); err != nil {
+			logger.Fatal("Could not enable query tags: " + err.Error())
+			return
+		}
+	}
+	// Writes of mutating requests are recorded in the audit log
+	if err := db.Use(metrics.NewGormPlugin()); err != nil {
+		logger.Fatal("Could not enable query metrics: " + err.Error())
+		return
+	}
+	if err := db.Use(audit.New()); err != nil {
+		logger.Fatal("Could not enable the audit log: " + err.Error())
+		return
+	}
+	if cfg.Tenancy.Enabled {
+		logger.Info("Tenant isolation is enabled")
+		if err := db.Use(tenancy.New(true)); err != nil {
+			logger.Fatal("Could not enable tenant isolation: " + err.Error())
+			return
+		}
+	}
 
-	// Storage
-	_, err = storage.NewFileStorage(storage.StorageProviderR2, cfg.Storage)
+	// Read replica, used through the connections of db so its plugins apply
+	var repoOptions []medusarepo.RepositoryOption
+	var replica *gorm.DB
+	if cfg.Database.ReplicaURL != "" {
+		replica, err = database.NewPostgresDatabase(cfg.Database.ReplicaURL, database.PoolConfigFrom(cfg.Database))
+		if err != nil {
+			logger.Fatal("Could not connect to the database replica: " + err.Error())
+			return
+		}
+		app.OnShutdown(func(ctx context.Context) error {
+			sqlDB, err := replica.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		})
+		repoOptions = append(repoOptions, medusarepo.WithReplica(replica))
+	}
+
+	// Schema
+	schemaPolicy, err := database.ParseSchemaPolicy(cfg.Schema.Policy)
 	if err != nil {
-		logger.Fatal("Could not initialize storage: " + err.Error())
+		logger.Fatal(err.Error())
 		return
 	}
 
+	schemaStatus, err := database.CheckSchema(db)
+	if err != nil {
+		logger.Fatal("Could not check the schema version: " + err.Error())
+		return
+	}
+
+	readOnly := false
+	if !schemaStatus.Compatible {
+		switch schemaPolicy {
+		case database.SchemaPolicyFail:
+			logger.Fatal("Incompatible schema: " + schemaStatus.Reason)
+			return
+		case database.SchemaPolicyReadOnly:
+			logger.Warn("Incompatible schema, starting in read-only mode: " + schemaStatus.Reason)
+			readOnly = true
+		default:
+			logger.Warn("Incompatible schema: " + schemaStatus.Reason)
+		}
+	}
+	if readOnly {
+		router.Use(middleware.ReadOnlyMiddleware())
+	}
+
+	if cfg.Mock.Externals {
+		logger.Warn("MOCK_EXTERNALS is set: storage, emails, chat alerts and webhooks are kept in memory")
+	}
+
+	// Storage
+	var fileStorage storage.FileStorage
+	if cfg.Mock.Externals {
+		fileStorage = storage.NewMemoryStorage()
+	} else {
+		fileStorage, err = storage.NewFileStorage(storage.StorageProviderR2, cfg.Storage)
+		if err != nil {
+			logger.Fatal("Could not initialize storage: " + err.Error())
+			return
+		}
+	}
+
 	// Redis
 	redisClient, err := database.NewRedisClient(cfg.Redis.RedisURL)
 	if err != nil {
 		logger.Fatal("Could not connect to Redis: " + err.Error())
 		return
 	}
+	app.OnShutdown(func(ctx context.Context) error {
+		return redisClient.Close()
+	})
+
+	// Rate limiter
+	newRateLimiter := func(name string, limit ratelimiter.Config) ratelimiter.RateLimiter {
+		if cfg.RateLimiter.Backend == "redis" {
+			return ratelimiter.NewSlidingWindowLimiter(redisClient, name, limit)
+		}
+		return ratelimiter.NewTokenBucketLimiter(limit)
+	}
+	routeLimiters := map[string]ratelimiter.RateLimiter{}
+	for prefix, limit := range cfg.RateLimiter.Routes {
+		routeLimiters[prefix] = newRateLimiter(prefix, limit)
+	}
+	rateLimiterMiddleware := middleware.NewRouteRateLimiterMiddleware(newRateLimiter("default", ratelimiter.Config{
+		RequestsPerTimeFrame: cfg.RateLimiter.RequestsPerTimeFrame,
+		TimeFrame:            cfg.RateLimiter.TimeFrame,
+	}), routeLimiters)
+	if cfg.RateLimiter.Enabled {
+		logger.Info("Rate Limiter is enabled, backend: " + cfg.RateLimiter.Backend)
+	}
+
+	// SSE relay
+	ssePublisher := sse.NewRedisPublisher(redisClient)
 
 	// Cache
-	_ = cache.NewRedisCache(redisClient)
+	cacheService := cache.NewRedisCache(redisClient)
+	cacheWarmer := cache.NewRedisWarmer(redisClient, cache.WarmerConfig{
+		TopN: cfg.CacheWarming.TopN,
+		Lead: cfg.CacheWarming.Lead,
+	})
+
+	// Usage history reads bypass the cache when warming is off
+	var usageCache cache.Warmer
+	if cfg.CacheWarming.Enabled {
+		usageCache = cacheWarmer
+	}
+
+	// Locks
+	lockManager := lock.NewManager(redisClient, lock.Config{LeaseTTL: cfg.Lock.LeaseTTL})
+
+	// External providers
+	providerGuard := degrade.NewGuard(cacheService, degrade.DefaultConfig())
+
+	// Health probes
+	healthRegistry := health.NewRegistry(cfg.Health.Timeout)
+	healthRegistry.Register("postgres", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	healthRegistry.Register("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	healthRegistry.Register("storage", fileStorage.Ping)
+	if replica != nil {
+		// Only listings and analytics read from the replica, its outage
+		// degrades the process without taking it out of rotation
+		healthRegistry.RegisterOptional("postgres_replica", func(ctx context.Context) error {
+			sqlDB, err := replica.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		})
+	}
+	healthRegistry.RegisterOptional("external_providers", providerGuard.Check)
+
+	// Chat alerts
+	var chatNotifier chat.Notifier
+	if cfg.Mock.Externals {
+		chatNotifier = chat.NewMemoryNotifier()
+	} else {
+		chatNotifier = chat.NewWebhookNotifier(ratelimiter.Config{
+			RequestsPerTimeFrame: cfg.ChatAlerts.RequestsPerTimeFrame,
+			TimeFrame:            cfg.ChatAlerts.TimeFrame,
+		})
+	}
+
+	// Webhooks
+	var webhookSender webhook.Sender
+	if cfg.Mock.Externals {
+		webhookSender = webhook.NewMemorySender()
+	} else {
+		webhookSender = webhook.NewHTTPSender()
+	}
+
+	// Email
+	var emailService email.EmailService
+	switch {
+	case cfg.Mock.Externals:
+		emailService = mockemail.NewEmailClient()
+	case cfg.Email.ResendApiKey != "":
+		dispatcherConfig := email.DefaultDispatcherConfig()
+		dispatcherConfig.Workers = cfg.Email.Workers
+		dispatcherConfig.QueueSize = cfg.Email.QueueSize
+		dispatcherConfig.DomainRate = cfg.Email.DomainRatePerSecond
+		dispatcher := email.NewDispatcher(resend.NewResendEmailClient(cfg.Email.ResendApiKey), dispatcherConfig, logger)
+		app.OnShutdown(dispatcher.Close)
+		emailService = dispatcher
+	}
 
 	// Repositories
-	medusaStore := medusarepo.NewStore(db, logger)
-	_ = store.NewStore(medusaStore)
+	medusaStore := medusarepo.NewStore(db, logger, repoOptions...)
+	appStore := store.NewStore(medusaStore)
+
+	// Services
+	serviceContainer := service.NewService(*medusaservice.NewService(logger), appStore, &cfg)
+	syncService := service.NewSyncService(serviceContainer)
+	chatAlertService := service.NewChatAlertService(serviceContainer, chatNotifier)
+	apiUsageService := service.NewApiUsageService(serviceContainer, redisClient, chatAlertService, usageCache)
+	commissionService := service.NewCommissionService(serviceContainer)
+	cacheWarmingService := service.NewCacheWarmingService(serviceContainer, cacheWarmer)
+	backupService := service.NewBackupService(serviceContainer, fileStorage)
+	storageConfigService := service.NewStorageConfigService(serviceContainer, fileStorage)
+	adminActionService := service.NewAdminActionService(serviceContainer)
+	seedService := service.NewSeedService(serviceContainer)
+	activityService := service.NewActivityService(serviceContainer)
+	storageLifecycleService := service.NewStorageLifecycleService(serviceContainer, fileStorage)
+	jwtAuthenticator := jwt.NewJwt(jwt.Config{Secret: cfg.Auth.JwtSecret})
+	sessionService := service.NewSessionService(serviceContainer, redisClient, ssePublisher, jwtAuthenticator)
+
+	// Social sign in, each provider is offered once its app is configured
+	var oauthProviders []auth.Provider
+	if cfg.OAuth.Google.ClientID != "" {
+		google := cfg.OAuth.Google
+		google.RedirectURL = cfg.OAuth.CallbackBaseURL + "/auth/oauth/google/callback"
+		oauthProviders = append(oauthProviders, auth.NewGoogleProvider(google))
+	}
+	if cfg.OAuth.GitHub.ClientID != "" {
+		github := cfg.OAuth.GitHub
+		github.RedirectURL = cfg.OAuth.CallbackBaseURL + "/auth/oauth/github/callback"
+		oauthProviders = append(oauthProviders, auth.NewGitHubProvider(github))
+	}
+	oauthService := service.NewOAuthService(serviceContainer, redisClient, sessionService, oauthProviders...)
+	tenantService := service.NewTenantService(serviceContainer, db)
+	supportService := service.NewSupportService(serviceContainer, fileStorage, emailService, chatAlertService)
+	auditLogService := service.NewAuditLogService(serviceContainer)
+	statusService := service.NewStatusService(serviceContainer, healthRegistry)
+
+	// Change events, relayed to the SSE server and the webhooks subscribed to them
+	changeRegistry := changes.NewRegistry(models.ChangeSchemas...)
+	webhookService := service.NewWebhookService(serviceContainer, webhookSender, changeRegistry)
+	err = db.Use(changes.New(changeRegistry, func(ctx context.Context, event *changes.Event) {
+		message := &sse.Message{Event: event.Name, Data: gin.H{
+			"entity": event.Entity,
+			"action": event.Action,
+			"data":   event.Model,
+		}}
+		for _, userID := range event.Audience {
+			if err := ssePublisher.Send(ctx, userID, message); err != nil {
+				logger.Warn("Could not publish change event " + event.Name + ": " + err.Error())
+			}
+		}
+		if err := webhookService.Publish(ctx, event.Name, event.Model); err != nil {
+			logger.Warn("Could not queue webhooks for " + event.Name + ": " + err.Error())
+		}
+	}))
+	if err != nil {
+		logger.Fatal("Could not enable change events: " + err.Error())
+		return
+	}
+
+	service.RegisterBuiltinAdminActions(adminActionService, redisClient, lockManager, apiUsageService, storageConfigService)
+
+	if cfg.Seed.OnStartup && cfg.Tenancy.Enabled {
+		logger.Warn("Skipping startup seeding, defaults are not seeded into tenant schemas")
+	}
+	if cfg.Seed.OnStartup && cfg.Seed.DefaultsFile != "" && !readOnly && !cfg.Tenancy.Enabled {
+		defaults, err := seedService.LoadDefaults(cfg.Seed.DefaultsFile)
+		if err != nil {
+			logger.Fatal(err.Error())
+			return
+		}
+		if _, err := seedService.SeedDefaults(app.Context(), defaults); err != nil {
+			logger.Fatal("Could not seed defaults: " + err.Error())
+			return
+		}
+	}
+
+	if cfg.ApiUsage.Enabled {
+		apiUsageService.StartRollupWorker(app.Context())
+	}
+	cacheWarmingService.StartWarmingWorker(app.Context())
+	backupService.StartScheduler(app.Context())
+	storageConfigService.StartHealthChecker(app.Context())
+	activityService.StartRetentionWorker(app.Context())
+	storageLifecycleService.StartDeletionWorker(app.Context())
+	supportService.StartSLAWorker(app.Context())
+	webhookService.StartDeliveryWorker(app.Context())
+
+	// Handlers
+	handlerContainer := handler.NewHandler(logger)
+	syncHandler := handlers.NewSyncHandler(handlerContainer, syncService)
+	apiUsageHandler := handlers.NewApiUsageHandler(handlerContainer, apiUsageService)
+	commissionHandler := handlers.NewCommissionHandler(handlerContainer, commissionService)
+	chatHandler := handlers.NewChatHandler(handlerContainer, chatAlertService)
+	healthHandler := handlers.NewHealthHandler(handlerContainer, healthRegistry, cfg.Mock.Externals)
+	backupHandler := handlers.NewBackupHandler(handlerContainer, backupService)
+	providerHandler := handlers.NewProviderHandler(handlerContainer, providerGuard)
+	storageConfigHandler := handlers.NewStorageConfigHandler(handlerContainer, storageConfigService)
+	adminActionHandler := handlers.NewAdminActionHandler(handlerContainer, adminActionService)
+	lockHandler := handlers.NewLockHandler(handlerContainer, lockManager)
+	sessionHandler := handlers.NewSessionHandler(handlerContainer, sessionService)
+	oauthHandler := handlers.NewOAuthHandler(handlerContainer, oauthService, cfg.OAuth.SuccessRedirectURL, strings.HasPrefix(cfg.OAuth.CallbackBaseURL, "https://"))
+	activityHandler := handlers.NewActivityHandler(handlerContainer, activityService)
+	storageLifecycleHandler := handlers.NewStorageLifecycleHandler(handlerContainer, storageLifecycleService)
+	tenantHandler := handlers.NewTenantHandler(handlerContainer, tenantService)
+	supportHandler := handlers.NewSupportHandler(handlerContainer, supportService)
+	webhookHandler := handlers.NewWebhookHandler(handlerContainer, webhookService)
+	auditLogHandler := handlers.NewAuditLogHandler(handlerContainer, auditLogService)
+	statusHandler := handlers.NewStatusHandler(handlerContainer, statusService)
+	databases := map[string]*gorm.DB{"primary": db}
+	if replica != nil {
+		databases["replica"] = replica
+	}
+	databaseHandler := handlers.NewDatabaseHandler(handlerContainer, databases)
+	schemaHandler := handlers.NewSchemaHandler(handlerContainer, func() (*database.SchemaStatus, error) {
+		return database.CheckSchema(db)
+	}, schemaPolicy, readOnly, cfg.Mock.Externals)
+
+	// Routes
+	routes.RegisterAPI(router, &cfg, &routes.API{
+		Activity:         activityHandler,
+		AdminAction:      adminActionHandler,
+		ApiUsage:         apiUsageHandler,
+		AuditLog:         auditLogHandler,
+		Backup:           backupHandler,
+		Chat:             chatHandler,
+		Commission:       commissionHandler,
+		Database:         databaseHandler,
+		Health:           healthHandler,
+		Lock:             lockHandler,
+		OAuth:            oauthHandler,
+		Provider:         providerHandler,
+		Schema:           schemaHandler,
+		Session:          sessionHandler,
+		Status:           statusHandler,
+		StorageConfig:    storageConfigHandler,
+		StorageLifecycle: storageLifecycleHandler,
+		Support:          supportHandler,
+		Sync:             syncHandler,
+		Tenant:           tenantHandler,
+		Webhook:          webhookHandler,
+
+		Audit:       middleware.NewAuditMiddleware(auditLogService),
+		Auth:        middleware.AuthTokenMiddleware(jwtAuthenticator, sessionService),
+		ErrorRate:   middleware.NewErrorRateMiddleware(statusService),
+		RateLimiter: rateLimiterMiddleware,
+		TenantScope: middleware.TenantMiddleware(cfg.Tenancy.Header, tenantService.Exists),
+		Usage:       middleware.NewUsageMiddleware(apiUsageService),
+	})
 }
@@ -1,5 +1,358 @@
-package cli
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/apidocs"
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/database"
+	"github.com/imlargo/go-api/internal/routes"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/internal/store"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/core/logger"
+	"github.com/imlargo/go-api/pkg/medusa/core/querytag"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	medusaservice "github.com/imlargo/go-api/pkg/medusa/core/service"
+	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+)
+
+const usage = `usage: cli <command> [flags]
+
+commands:
+  backup run                       export critical tables to object storage
+  backup list                      list the backup catalog
+  backup restore -id N [-dry-run]  diff a backup against the database and restore it
+  storage migrate -user N [-prefix P] [-delete-source]
+                                   copy a user's files into their own bucket
+  schema migrate                   apply pending schema migrations, and to every
+                                   tenant schema when TENANCY_ENABLED is set
+  schema status                    compare the database schema with this build
+  schema rollback -to N            revert the migrations above version N, tenant
+                                   schemas first when TENANCY_ENABLED is set
+  schema create -name N [-dir D]   write the up and down SQL files of the next
+                                   migration (no database needed)
+  schema seed-defaults [-file F]   write missing system defaults, keeping manual edits
+  schema verify -previous N        check migrations are safe to run under the
+                                   release at schema version N (no database needed)
+  docs verify [-spec S]            check the spec generated by swag documents every
+                                   registered route and no other
+  docs diff -base B [-head H] [-allow A] [-changelog C]
+                                   compare the spec of the last release with the
+                                   current one, write the changelog and fail on
+                                   breaking changes missing from the allow list`
 
 func main() {
+	if len(os.Args) < 3 {
+		exit(usage)
+	}
+
+	if os.Args[1] == "schema" {
+		runSchema()
+		return
+	}
+
+	if os.Args[1] == "docs" {
+		runDocs()
+		return
+	}
+
+	cfg := config.LoadConfig()
+
+	logger := logger.NewLogger()
+	defer logger.Sync()
+
+	container, fileStorage, err := newContainer(cfg, logger)
+	if err != nil {
+		exit(err.Error())
+	}
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "backup":
+		runBackup(ctx, service.NewBackupService(container, fileStorage))
+	case "storage":
+		runStorage(ctx, service.NewStorageConfigService(container, fileStorage))
+	default:
+		exit(usage)
+	}
+}
+
+func runBackup(ctx context.Context, backupService service.BackupService) {
+	switch os.Args[2] {
+	case "run":
+		record, err := backupService.Run(ctx, "cli")
+		if err != nil {
+			exit(err.Error())
+		}
+		printJSON(record)
+
+	case "list":
+		records, err := backupService.List(ctx, 50)
+		if err != nil {
+			exit(err.Error())
+		}
+		printJSON(records)
+
+	case "restore":
+		flags := flag.NewFlagSet("restore", flag.ExitOnError)
+		id := flags.Uint("id", 0, "backup id")
+		dryRun := flags.Bool("dry-run", true, "only print the diff, set -dry-run=false to apply")
+		flags.Parse(os.Args[3:])
+
+		if *id == 0 {
+			exit("restore requires -id")
+		}
+
+		report, err := backupService.Restore(ctx, *id, *dryRun)
+		if err != nil {
+			exit(err.Error())
+		}
+		printJSON(report)
+
+	default:
+		exit(usage)
+	}
+}
+
+func runStorage(ctx context.Context, storageConfigService service.StorageConfigService) {
+	switch os.Args[2] {
+	case "migrate":
+		flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+		userID := flags.Uint("user", 0, "user id")
+		prefix := flags.String("prefix", "", "key prefix to migrate, defaults to the user's prefix")
+		deleteSource := flags.Bool("delete-source", false, "delete the source objects once every copy succeeded")
+		flags.Parse(os.Args[3:])
+
+		if *userID == 0 {
+			exit("migrate requires -user")
+		}
+
+		report, err := storageConfigService.Migrate(ctx, *userID, *prefix, *deleteSource)
+		if report != nil {
+			printJSON(report)
+		}
+		if err != nil {
+			exit(err.Error())
+		}
+
+	default:
+		exit(usage)
+	}
+}
+
+func runSchema() {
+	if os.Args[2] == "verify" {
+		flags := flag.NewFlagSet("verify", flag.ExitOnError)
+		previous := flags.Int("previous", 0, "schema version of the release currently deployed")
+		flags.Parse(os.Args[3:])
+
+		if err := database.VerifyMigrations(*previous); err != nil {
+			exit(err.Error())
+		}
+		fmt.Printf("migrations up to version %d are compatible with version %d\n", database.ExpectedSchemaVersion(), *previous)
+		return
+	}
+	if os.Args[2] == "create" {
+		flags := flag.NewFlagSet("create", flag.ExitOnError)
+		name := flags.String("name", "", "migration name, e.g. add_orders_index")
+		dir := flags.String("dir", "internal/database/sql", "directory of the SQL migrations")
+		flags.Parse(os.Args[3:])
+
+		paths, err := database.CreateSQLMigration(*dir, *name)
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		if err != nil {
+			exit(err.Error())
+		}
+		return
+	}
+
+	cfg := config.LoadConfig()
+	db, err := database.NewPostgresDatabase(cfg.Database.URL, database.PoolConfigFrom(cfg.Database))
+	if err != nil {
+		exit("could not connect to the database: " + err.Error())
+	}
+
+	switch os.Args[2] {
+	case "seed-defaults":
+		flags := flag.NewFlagSet("seed-defaults", flag.ExitOnError)
+		file := flags.String("file", cfg.Seed.DefaultsFile, "defaults file, defaults to SEED_DEFAULTS_FILE")
+		flags.Parse(os.Args[3:])
+
+		if *file == "" {
+			exit("seed-defaults requires -file or SEED_DEFAULTS_FILE")
+		}
+
+		logger := logger.NewLogger()
+		defer logger.Sync()
+
+		appStore := store.NewStore(medusarepo.NewStore(db, logger))
+		seedService := service.NewSeedService(service.NewService(*medusaservice.NewService(logger), appStore, &cfg))
+
+		defaults, err := seedService.LoadDefaults(*file)
+		if err != nil {
+			exit(err.Error())
+		}
+		results, err := seedService.SeedDefaults(context.Background(), defaults)
+		if err != nil {
+			exit(err.Error())
+		}
+		printJSON(results)
+
+	case "migrate":
+		if err := database.Migrate(db); err != nil {
+			exit(err.Error())
+		}
+		if cfg.Tenancy.Enabled {
+			if err := database.MigrateTenants(db); err != nil {
+				exit(err.Error())
+			}
+		}
+		fallthrough
+
+	case "rollback":
+		flags := flag.NewFlagSet("rollback", flag.ExitOnError)
+		to := flags.Int("to", -1, "schema version to roll back to")
+		flags.Parse(os.Args[3:])
+
+		if *to < 0 {
+			exit("rollback requires -to")
+		}
+		// Tenant schemas first, the registry they are listed in is public
+		if cfg.Tenancy.Enabled {
+			if err := database.RollbackTenants(db, *to); err != nil {
+				exit(err.Error())
+			}
+		}
+		if err := database.Rollback(db, *to); err != nil {
+			exit(err.Error())
+		}
+
+		status, err := database.CheckSchema(db)
+		if err != nil {
+			exit(err.Error())
+		}
+		printJSON(status)
+
+	case "status":
+		status, err := database.CheckSchema(db)
+		if err != nil {
+			exit(err.Error())
+		}
+		printJSON(status)
+
+	default:
+		exit(usage)
+	}
+}
+
+func runDocs() {
+	if os.Args[2] == "diff" {
+		runDocsDiff()
+		return
+	}
+	if os.Args[2] != "verify" {
+		exit(usage)
+	}
+
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	specPath := flags.String("spec", "api/docs/swagger.json", "swagger.json of this build")
+	flags.Parse(os.Args[3:])
+
+	// The routes are only listed, gin doesn't need to log them
+	gin.SetMode(gin.ReleaseMode)
+	report, err := apidocs.Verify(routes.Registered(), *specPath)
+	if err != nil {
+		exit(err.Error())
+	}
+
+	printJSON(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+func runDocsDiff() {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	base := flags.String("base", "", "swagger.json of the last release")
+	head := flags.String("head", "api/docs/swagger.json", "swagger.json of this build")
+	allow := flags.String("allow", "api/breaking-changes.txt", "acknowledged breaking changes, one per line")
+	changelog := flags.String("changelog", "", "file the markdown changelog is written to, stdout when empty")
+	flags.Parse(os.Args[3:])
+
+	if *base == "" {
+		exit("docs diff requires -base")
+	}
+
+	allowed, err := apidocs.ReadAllowList(*allow)
+	if err != nil {
+		exit(err.Error())
+	}
+
+	report, err := apidocs.Diff(*base, *head, allowed)
+	if err != nil {
+		exit(err.Error())
+	}
+
+	if *changelog == "" {
+		fmt.Print(report.Changelog())
+	} else if err := os.WriteFile(*changelog, []byte(report.Changelog()), 0o644); err != nil {
+		exit(err.Error())
+	}
+
+	if !report.OK() {
+		fmt.Fprintln(os.Stderr, "undocumented breaking changes, add them to "+*allow+" once announced:")
+		for _, change := range report.Undocumented {
+			fmt.Fprintln(os.Stderr, "  "+change)
+		}
+		os.Exit(1)
+	}
+}
+
+func newContainer(cfg config.Config, logger *logger.Logger) (*service.Service, storage.FileStorage, error) {
+	if cfg.Encryption.Key != "" {
+		encryptor, err := encryption.NewEncryptorFromBase64(cfg.Encryption.Key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not initialize encryption: %w", err)
+		}
+		encryption.SetDefault(encryptor)
+	}
+
+	db, err := database.NewPostgresDatabase(cfg.Database.URL, database.PoolConfigFrom(cfg.Database))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to the database: %w", err)
+	}
+	if cfg.QueryTags.Enabled {
+		if err := db.Use(querytag.New("cli")); err != nil {
+			return nil, nil, fmt.Errorf("could not enable query tags: %w", err)
+		}
+	}
+
+	fileStorage, err := storage.NewFileStorage(storage.StorageProviderR2, cfg.Storage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not initialize storage: %w", err)
+	}
+
+	appStore := store.NewStore(medusarepo.NewStore(db, logger))
+	container := service.NewService(*medusaservice.NewService(logger), appStore, &cfg)
+
+	return container, fileStorage, nil
+}
+
+func printJSON(v any) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
 
+func exit(message string) {
+	fmt.Fprintln(os.Stderr, message)
+	os.Exit(1)
 }
@@ -5,11 +5,17 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/database"
 	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/routes"
 	"github.com/imlargo/go-api/pkg/medusa/core/app"
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
 	"github.com/imlargo/go-api/pkg/medusa/core/handler"
 	"github.com/imlargo/go-api/pkg/medusa/core/logger"
 	"github.com/imlargo/go-api/pkg/medusa/core/server/http"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
+	"github.com/imlargo/go-api/pkg/medusa/services/sse"
 )
 
 func main() {
@@ -29,6 +35,7 @@ func main() {
 	app := app.NewApp(
 		app.WithName("butter"),
 		app.WithServer(srv),
+		app.WithShutdownTimeout(cfg.Server.ShutdownTimeout),
 	)
 
 	Mount(app, cfg, router, logger)
@@ -38,9 +45,41 @@ func main() {
 
 func Mount(app *app.App, cfg config.Config, router *gin.Engine, logger *logger.Logger) {
 
-	handlerContainer := handler.NewHandler(logger)
-	sseHandler := handlers.NewSSEHandler(handlerContainer)
+	router.Use(middleware.ErrorHandlerMiddleware(logger))
+
+	// Redis
+	redisClient, err := database.NewRedisClient(cfg.Redis.RedisURL)
+	if err != nil {
+		logger.Fatal("Could not connect to Redis: " + err.Error())
+		return
+	}
+	app.OnShutdown(func(ctx context.Context) error {
+		return redisClient.Close()
+	})
+
+	// SSE
+	eventLog := sse.NewRedisEventLog(redisClient, cfg.SSE.StreamMaxLen, cfg.SSE.Retention)
+	sseManager := sse.NewSSEManager(sse.Config{
+		BufferSize:        cfg.SSE.BufferSize,
+		HeartbeatInterval: cfg.SSE.HeartbeatInterval,
+		IdleTimeout:       sse.DefaultConfig().IdleTimeout,
+		ReplayLimit:       cfg.SSE.ReplayLimit,
+
+		MaxConnectionsPerUser: cfg.SSE.MaxConnectionsPerUser,
+		MaxConnections:        cfg.SSE.MaxConnections,
+	}, eventLog)
 
-	router.GET("/sse/listen", sseHandler.Listen)
-	router.POST("/sse/publish", sseHandler.Publish)
+	// Messages published by the API, e.g. session refresh and change events
+	sse.StartRelay(app.Context(), redisClient, sseManager)
+
+	// Open streams never go idle, they are closed for the server to drain
+	context.AfterFunc(app.Context(), sseManager.Close)
+
+	dispatcher := sse.NewDispatcher(sseManager, redisClient, sse.DefaultDispatchConfig())
+
+	handlerContainer := handler.NewHandler(logger)
+	routes.RegisterSSE(router, &cfg, &routes.SSE{
+		SSE:      handlers.NewSSEHandler(handlerContainer, sseManager, changes.NewRegistry(models.ChangeSchemas...)),
+		Dispatch: handlers.NewNotificationDispatchHandler(handlerContainer, sseManager, dispatcher),
+	})
 }
@@ -0,0 +1,284 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ChangeKind groups the changes of a changelog
+type ChangeKind string
+
+const (
+	ChangeAdded      ChangeKind = "added"
+	ChangeChanged    ChangeKind = "changed"
+	ChangeDeprecated ChangeKind = "deprecated"
+	ChangeRemoved    ChangeKind = "removed"
+)
+
+// Change is a difference between two specs. Breaking changes can fail
+// clients built against the old spec.
+type Change struct {
+	Kind        ChangeKind `json:"kind"`
+	Breaking    bool       `json:"breaking"`
+	Description string     `json:"description"`
+}
+
+type DiffReport struct {
+	Changes []Change `json:"changes"`
+	// Undocumented are the breaking changes missing from the allow list
+	Undocumented []string `json:"undocumented"`
+}
+
+func (r *DiffReport) OK() bool {
+	return len(r.Undocumented) == 0
+}
+
+// spec is the part of a swagger 2.0 document the diff reads, as swag
+// writes it
+type spec struct {
+	Paths       map[string]map[string]specOperation `json:"paths"`
+	Definitions map[string]specSchema               `json:"definitions"`
+}
+
+type specOperation struct {
+	Deprecated bool            `json:"deprecated"`
+	Parameters []specParameter `json:"parameters"`
+}
+
+type specParameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Type     string      `json:"type"`
+	Schema   *specSchema `json:"schema"`
+}
+
+type specSchema struct {
+	Ref        string                `json:"$ref"`
+	Type       string                `json:"type"`
+	Items      *specSchema           `json:"items"`
+	Properties map[string]specSchema `json:"properties"`
+	Required   []string              `json:"required"`
+}
+
+// Diff compares the spec of the last release with the current one. Every
+// breaking change must be acknowledged with its description, one per line,
+// in allowed; removing an operation deprecated in base needs no entry.
+func Diff(basePath, headPath string, allowed []string) (*DiffReport, error) {
+	base, err := readSpec(basePath)
+	if err != nil {
+		return nil, err
+	}
+	head, err := readSpec(headPath)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffOperations(base, head)
+	changes = append(changes, diffDefinitions(base, head)...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Description < changes[j].Description
+	})
+
+	acknowledged := map[string]bool{}
+	for _, line := range allowed {
+		acknowledged[strings.TrimSpace(line)] = true
+	}
+
+	report := &DiffReport{Changes: changes, Undocumented: []string{}}
+	for _, change := range changes {
+		if change.Breaking && !acknowledged[change.Description] {
+			report.Undocumented = append(report.Undocumented, change.Description)
+		}
+	}
+	return report, nil
+}
+
+// ReadAllowList reads the acknowledged breaking changes of path, skipping
+// blank lines and # comments. A missing file acknowledges nothing.
+func ReadAllowList(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// Changelog renders the changes as markdown, breaking ones flagged
+func (r *DiffReport) Changelog() string {
+	var b strings.Builder
+	for _, kind := range []ChangeKind{ChangeAdded, ChangeChanged, ChangeDeprecated, ChangeRemoved} {
+		var lines []string
+		for _, change := range r.Changes {
+			if change.Kind != kind {
+				continue
+			}
+			line := "- " + change.Description
+			if change.Breaking {
+				line += " **(breaking)**"
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s%s\n\n%s\n\n", strings.ToUpper(string(kind[:1])), kind[1:], strings.Join(lines, "\n"))
+	}
+	if b.Len() == 0 {
+		return "No API changes.\n"
+	}
+	return b.String()
+}
+
+func readSpec(path string) (*spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var parsed spec
+	if err := json.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return &parsed, nil
+}
+
+func diffOperations(base, head *spec) []Change {
+	var changes []Change
+	for path, methods := range base.Paths {
+		for method, baseOperation := range methods {
+			name := strings.ToUpper(method) + " " + path
+			headOperation, exists := head.Paths[path][method]
+			if !exists {
+				// Removing what was announced as deprecated is the expected
+				// end of a deprecation
+				changes = append(changes, Change{Kind: ChangeRemoved, Breaking: !baseOperation.Deprecated, Description: "removed " + name})
+				continue
+			}
+			if headOperation.Deprecated && !baseOperation.Deprecated {
+				changes = append(changes, Change{Kind: ChangeDeprecated, Description: "deprecated " + name})
+			}
+			changes = append(changes, diffParameters(name, baseOperation, headOperation)...)
+		}
+	}
+
+	for path, methods := range head.Paths {
+		for method := range methods {
+			if _, exists := base.Paths[path][method]; !exists {
+				changes = append(changes, Change{Kind: ChangeAdded, Description: "added " + strings.ToUpper(method) + " " + path})
+			}
+		}
+	}
+	return changes
+}
+
+func diffParameters(operation string, base, head specOperation) []Change {
+	baseParameters := map[string]specParameter{}
+	for _, parameter := range base.Parameters {
+		baseParameters[parameter.In+"."+parameter.Name] = parameter
+	}
+
+	var changes []Change
+	for _, parameter := range head.Parameters {
+		key := parameter.In + "." + parameter.Name
+		previous, exists := baseParameters[key]
+		delete(baseParameters, key)
+
+		switch {
+		case !exists && parameter.Required:
+			changes = append(changes, Change{Kind: ChangeChanged, Breaking: true, Description: fmt.Sprintf("%s: added required parameter %s", operation, key)})
+		case !exists:
+			changes = append(changes, Change{Kind: ChangeAdded, Description: fmt.Sprintf("%s: added parameter %s", operation, key)})
+		case parameter.Required && !previous.Required:
+			changes = append(changes, Change{Kind: ChangeChanged, Breaking: true, Description: fmt.Sprintf("%s: parameter %s is now required", operation, key)})
+		case parameterType(parameter) != parameterType(previous):
+			changes = append(changes, Change{Kind: ChangeChanged, Breaking: true, Description: fmt.Sprintf("%s: parameter %s changed from %s to %s", operation, key, parameterType(previous), parameterType(parameter))})
+		}
+	}
+
+	// Servers ignore parameters they don't read, dropping one is safe
+	for key := range baseParameters {
+		changes = append(changes, Change{Kind: ChangeRemoved, Description: fmt.Sprintf("%s: removed parameter %s", operation, key)})
+	}
+	return changes
+}
+
+func diffDefinitions(base, head *spec) []Change {
+	var changes []Change
+	for name, baseSchema := range base.Definitions {
+		headSchema, exists := head.Definitions[name]
+		if !exists {
+			// Operations still returning it would be reported as changed
+			changes = append(changes, Change{Kind: ChangeRemoved, Description: "removed type " + name})
+			continue
+		}
+
+		// Types are shared by requests and responses, a removed field is
+		// assumed to be read by some client
+		for field, property := range baseSchema.Properties {
+			headProperty, exists := headSchema.Properties[field]
+			if !exists {
+				changes = append(changes, Change{Kind: ChangeRemoved, Breaking: true, Description: fmt.Sprintf("%s: removed field %s", name, field)})
+				continue
+			}
+			if schemaType(property) != schemaType(headProperty) {
+				changes = append(changes, Change{Kind: ChangeChanged, Breaking: true, Description: fmt.Sprintf("%s: field %s changed from %s to %s", name, field, schemaType(property), schemaType(headProperty))})
+			}
+		}
+		for field := range headSchema.Properties {
+			if _, exists := baseSchema.Properties[field]; !exists {
+				changes = append(changes, Change{Kind: ChangeAdded, Description: fmt.Sprintf("%s: added field %s", name, field)})
+			}
+		}
+
+		wasRequired := map[string]bool{}
+		for _, field := range baseSchema.Required {
+			wasRequired[field] = true
+		}
+		for _, field := range headSchema.Required {
+			if !wasRequired[field] {
+				changes = append(changes, Change{Kind: ChangeChanged, Breaking: true, Description: fmt.Sprintf("%s: field %s is now required", name, field)})
+			}
+		}
+	}
+
+	for name := range head.Definitions {
+		if _, exists := base.Definitions[name]; !exists {
+			changes = append(changes, Change{Kind: ChangeAdded, Description: "added type " + name})
+		}
+	}
+	return changes
+}
+
+func parameterType(parameter specParameter) string {
+	if parameter.Schema != nil {
+		return schemaType(*parameter.Schema)
+	}
+	return parameter.Type
+}
+
+func schemaType(schema specSchema) string {
+	switch {
+	case schema.Ref != "":
+		return strings.TrimPrefix(schema.Ref, "#/definitions/")
+	case schema.Type == "array" && schema.Items != nil:
+		return "[]" + schemaType(*schema.Items)
+	case schema.Type == "":
+		return "object"
+	default:
+		return schema.Type
+	}
+}
@@ -0,0 +1,86 @@
+// Package apidocs checks the spec swag generates against the routes the
+// servers register, so the published spec can't drift from the code.
+package apidocs
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Report struct {
+	Routes     int `json:"routes"`
+	Operations int `json:"operations"`
+	// Undocumented are registered routes missing from the spec
+	Undocumented []string `json:"undocumented"`
+	// Stale are operations of the spec no route serves
+	Stale []string `json:"stale"`
+}
+
+func (r *Report) OK() bool {
+	return len(r.Undocumented) == 0 && len(r.Stale) == 0
+}
+
+// handlersPackage marks the routes that belong in the spec, the ones served
+// by a handler. Metrics and ping are registered inline and aren't part of
+// the API.
+const handlersPackage = "/internal/handlers."
+
+var ginParam = regexp.MustCompile(`[:*](\w+)`)
+
+// Verify compares the registered routes with the operations of the spec at
+// specPath. Annotations that reference missing types already fail swag, so
+// only the paths are compared.
+func Verify(routes gin.RoutesInfo, specPath string) (*Report, error) {
+	spec, err := readSpec(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	documented := make(map[string]bool)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	report := &Report{
+		Operations:   len(documented),
+		Undocumented: []string{},
+		Stale:        []string{},
+	}
+
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		if !strings.Contains(route.Handler, handlersPackage) {
+			continue
+		}
+		report.Routes++
+
+		key := route.Method + " " + ginParam.ReplaceAllString(route.Path, "{$1}")
+		registered[key] = true
+		if !documented[key] {
+			report.Undocumented = append(report.Undocumented, key+" ("+handlerName(route.Handler)+")")
+		}
+	}
+
+	for key := range documented {
+		if !registered[key] {
+			report.Stale = append(report.Stale, key)
+		}
+	}
+
+	sort.Strings(report.Undocumented)
+	sort.Strings(report.Stale)
+
+	return report, nil
+}
+
+// handlerName shortens the function name gin reports, e.g.
+// handlers.(*SyncHandler).Sync
+func handlerName(name string) string {
+	name = name[strings.LastIndex(name, "/")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
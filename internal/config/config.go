@@ -1,30 +1,196 @@
 package config
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/imlargo/go-api/pkg/auth"
 	"github.com/imlargo/go-api/pkg/medusa/core/app"
 	"github.com/imlargo/go-api/pkg/medusa/core/env"
+	"github.com/imlargo/go-api/pkg/medusa/core/ratelimiter"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
 	"github.com/imlargo/go-api/pkg/medusa/services/storage"
 )
 
 type Config struct {
 	app.Config
-	RateLimiter RateLimiterConfig
-	Storage     storage.StorageConfig
-	Redis       RedisConfig
+	RateLimiter     RateLimiterConfig
+	Storage         storage.StorageConfig
+	Redis           RedisConfig
+	Admin           AdminConfig
+	ApiUsage        ApiUsageConfig
+	Commission      CommissionConfig
+	Encryption      EncryptionConfig
+	Backup          BackupConfig
+	CustomerStorage CustomerStorageConfig
+	SSE             SSEConfig
+	Lock            LockConfig
+	Schema          SchemaConfig
+	Seed            SeedConfig
+	QueryTags       QueryTagsConfig
+	Bulkheads       BulkheadsConfig
+	Activity        ActivityConfig
+	Tenancy         TenancyConfig
+	ChatAlerts      ChatAlertsConfig
+	CacheWarming    CacheWarmingConfig
+	Health          HealthConfig
+	Email           EmailConfig
+	Support         SupportConfig
+	Mock            MockConfig
+	OAuth           OAuthConfig
 }
 
+// RateLimiterConfig limits the requests of each client. The memory
+// backend counts per instance, redis counts across every replica. Routes
+// overrides the limit for the paths starting with a prefix.
 type RateLimiterConfig struct {
 	Enabled              bool
+	Backend              string
 	RequestsPerTimeFrame int
 	TimeFrame            time.Duration
+	Routes               map[string]ratelimiter.Config
 }
 
 type RedisConfig struct {
 	RedisURL string
 }
 
+type AdminConfig struct {
+	ApiKey         string
+	OperatorApiKey string
+}
+
+type CommissionConfig struct {
+	DefaultRateBps int
+}
+
+type EncryptionConfig struct {
+	Key string
+}
+
+type BackupConfig struct {
+	Interval time.Duration
+}
+
+type CustomerStorageConfig struct {
+	HealthCheckInterval time.Duration
+}
+
+type LockConfig struct {
+	LeaseTTL time.Duration
+}
+
+type SchemaConfig struct {
+	// Policy is what the API does at startup when the schema doesn't match
+	// the build: fail, warn or read_only
+	Policy string
+}
+
+type SeedConfig struct {
+	DefaultsFile string
+	OnStartup    bool
+}
+
+type QueryTagsConfig struct {
+	Enabled bool
+}
+
+type ActivityConfig struct {
+	// Retention is how long feed entries are kept. Zero keeps them forever.
+	Retention time.Duration
+}
+
+// TenancyConfig enables schema-per-tenant isolation. Each request names
+// its tenant in Header and only sees the tables of that tenant's schema.
+type TenancyConfig struct {
+	Enabled bool
+	Header  string
+}
+
+// ChatAlertsConfig caps the messages sent to each Slack or Discord
+// channel, so a burst of alerts can't get the webhook blocked
+type ChatAlertsConfig struct {
+	RequestsPerTimeFrame int
+	TimeFrame            time.Duration
+}
+
+// CacheWarmingConfig caches the usage history reads and recomputes the TopN
+// most read of each cohort when they are within Lead of expiring
+type CacheWarmingConfig struct {
+	Enabled  bool
+	TTL      time.Duration
+	TopN     int
+	Lead     time.Duration
+	Interval time.Duration
+}
+
+// HealthConfig caps how long the readiness probe waits on the dependencies
+type HealthConfig struct {
+	Timeout time.Duration
+}
+
+// EmailConfig enables transactional emails through Resend. Emails are
+// skipped when ResendApiKey is empty. They are sent by Workers in the
+// background, at most DomainRatePerSecond to each recipient domain.
+type EmailConfig struct {
+	ResendApiKey        string
+	From                string
+	Workers             int
+	QueueSize           int
+	DomainRatePerSecond float64
+}
+
+// MockConfig swaps the external services for in-memory ones. With
+// Externals set storage, emails, chat alerts and webhooks never leave the
+// process.
+type MockConfig struct {
+	Externals bool
+}
+
+// OAuthConfig enables social sign in. A provider is offered when its
+// client id is set, with the callback CallbackBaseURL/auth/oauth/<name>/callback.
+// When SuccessRedirectURL is set the callback redirects there with the
+// tokens in the fragment instead of answering with JSON.
+type OAuthConfig struct {
+	Google             auth.Config
+	GitHub             auth.Config
+	CallbackBaseURL    string
+	SuccessRedirectURL string
+}
+
+// SupportConfig routes support ticket notifications. TeamEmail receives new
+// tickets and customer replies, SLA breaches are looked for every
+// SLACheckInterval.
+type SupportConfig struct {
+	TeamEmail        string
+	SLACheckInterval time.Duration
+}
+
+// BulkheadsConfig caps the concurrent requests per route group. Analytics
+// covers the usage reporting endpoints, Api the rest of /api/v1.
+type BulkheadsConfig struct {
+	Api       middleware.BulkheadConfig
+	Analytics middleware.BulkheadConfig
+}
+
+type SSEConfig struct {
+	BufferSize        int
+	HeartbeatInterval time.Duration
+	ReplayLimit       int
+	StreamMaxLen      int64
+	Retention         time.Duration
+
+	MaxConnectionsPerUser int
+	MaxConnections        int
+}
+
+type ApiUsageConfig struct {
+	Enabled        bool
+	AlertThreshold int64
+	TopConsumers   int
+}
+
 func LoadConfig() Config {
 	err := env.CheckEnv([]string{
 		HOST,
@@ -44,9 +210,16 @@ func LoadConfig() Config {
 			Server: app.ServerConfig{
 				Host: env.GetEnvString(HOST, "localhost"),
 				Port: env.GetEnvInt(PORT, 8000),
+
+				ShutdownTimeout: time.Duration(env.GetEnvInt(SHUTDOWN_TIMEOUT_SECONDS, 15)) * time.Second,
 			},
 			Database: app.DbConfig{
-				URL: env.GetEnvString(DATABASE_URL, ""),
+				URL:        env.GetEnvString(DATABASE_URL, ""),
+				ReplicaURL: env.GetEnvString(DATABASE_REPLICA_URL, ""),
+
+				MaxOpenConns:    env.GetEnvInt(DATABASE_MAX_OPEN_CONNS, 25),
+				MaxIdleConns:    env.GetEnvInt(DATABASE_MAX_IDLE_CONNS, 10),
+				ConnMaxLifetime: time.Duration(env.GetEnvInt(DATABASE_CONN_MAX_LIFETIME_MINUTES, 30)) * time.Minute,
 			},
 			Auth: app.AuthConfig{
 				JwtSecret:         env.GetEnvString(JWT_SECRET, "your-secret-key"),
@@ -56,8 +229,154 @@ func LoadConfig() Config {
 		},
 		RateLimiter: RateLimiterConfig{
 			Enabled:              env.GetEnvBool(RATE_LIMITER_ENABLED, true),
+			Backend:              env.GetEnvString(RATE_LIMITER_BACKEND, "memory"),
 			RequestsPerTimeFrame: env.GetEnvInt(RATE_LIMITER_REQUESTS_PER_TIME_FRAME, 100),
 			TimeFrame:            time.Duration(env.GetEnvInt(RATE_LIMITER_TIME_FRAME_MINUTES, 1)) * time.Minute,
+			Routes:               parseRateLimiterRoutes(env.GetEnvString(RATE_LIMITER_ROUTES, "")),
+		},
+		Storage: storage.StorageConfig{
+			BucketName:      env.GetEnvString(STORAGE_BUCKET_NAME, ""),
+			AccountID:       env.GetEnvString(STORAGE_ACCOUNT_ID, ""),
+			AccessKeyID:     env.GetEnvString(STORAGE_ACCESS_KEY_ID, ""),
+			SecretAccessKey: env.GetEnvString(STORAGE_SECRET_ACCESS_KEY, ""),
+			PublicDomain:    env.GetEnvString(STORAGE_PUBLIC_DOMAIN, ""),
+			UsePublicURL:    env.GetEnvBool(STORAGE_USE_PUBLIC_URL, false),
+		},
+		Redis: RedisConfig{
+			RedisURL: env.GetEnvString(REDIS_URL, ""),
+		},
+		Admin: AdminConfig{
+			ApiKey:         env.GetEnvString(ADMIN_API_KEY, ""),
+			OperatorApiKey: env.GetEnvString(ADMIN_OPERATOR_API_KEY, ""),
+		},
+		ApiUsage: ApiUsageConfig{
+			Enabled:        env.GetEnvBool(API_USAGE_ENABLED, true),
+			AlertThreshold: int64(env.GetEnvInt(API_USAGE_ALERT_THRESHOLD, 100000)),
+			TopConsumers:   env.GetEnvInt(API_USAGE_TOP_CONSUMERS, 10),
+		},
+		Commission: CommissionConfig{
+			DefaultRateBps: env.GetEnvInt(COMMISSION_DEFAULT_RATE_BPS, 1000),
+		},
+		Encryption: EncryptionConfig{
+			Key: env.GetEnvString(ENCRYPTION_KEY, ""),
+		},
+		Backup: BackupConfig{
+			Interval: time.Duration(env.GetEnvInt(BACKUP_INTERVAL_HOURS, 0)) * time.Hour,
+		},
+		CustomerStorage: CustomerStorageConfig{
+			HealthCheckInterval: time.Duration(env.GetEnvInt(CUSTOMER_STORAGE_HEALTH_CHECK_MINUTES, 60)) * time.Minute,
+		},
+		Lock: LockConfig{
+			LeaseTTL: time.Duration(env.GetEnvInt(LOCK_LEASE_TTL_SECONDS, 30)) * time.Second,
+		},
+		SSE: SSEConfig{
+			BufferSize:        env.GetEnvInt(SSE_BUFFER_SIZE, 100),
+			HeartbeatInterval: time.Duration(env.GetEnvInt(SSE_HEARTBEAT_SECONDS, 15)) * time.Second,
+			ReplayLimit:       env.GetEnvInt(SSE_REPLAY_LIMIT, 500),
+			StreamMaxLen:      int64(env.GetEnvInt(SSE_STREAM_MAX_LEN, 1000)),
+			Retention:         time.Duration(env.GetEnvInt(SSE_RETENTION_HOURS, 24)) * time.Hour,
+
+			MaxConnectionsPerUser: env.GetEnvInt(SSE_MAX_CONNECTIONS_PER_USER, 10),
+			MaxConnections:        env.GetEnvInt(SSE_MAX_CONNECTIONS, 10000),
+		},
+		Schema: SchemaConfig{
+			Policy: env.GetEnvString(SCHEMA_POLICY, "warn"),
+		},
+		Seed: SeedConfig{
+			DefaultsFile: env.GetEnvString(SEED_DEFAULTS_FILE, ""),
+			OnStartup:    env.GetEnvBool(SEED_DEFAULTS_ON_STARTUP, false),
+		},
+		QueryTags: QueryTagsConfig{
+			Enabled: env.GetEnvBool(QUERY_TAGS_ENABLED, true),
+		},
+		Activity: ActivityConfig{
+			Retention: time.Duration(env.GetEnvInt(ACTIVITY_RETENTION_DAYS, 90)) * 24 * time.Hour,
 		},
+		Tenancy: TenancyConfig{
+			Enabled: env.GetEnvBool(TENANCY_ENABLED, false),
+			Header:  env.GetEnvString(TENANCY_HEADER, "X-Tenant"),
+		},
+		ChatAlerts: ChatAlertsConfig{
+			RequestsPerTimeFrame: env.GetEnvInt(CHAT_ALERTS_REQUESTS_PER_TIME_FRAME, 20),
+			TimeFrame:            time.Duration(env.GetEnvInt(CHAT_ALERTS_TIME_FRAME_MINUTES, 1)) * time.Minute,
+		},
+		CacheWarming: CacheWarmingConfig{
+			Enabled:  env.GetEnvBool(CACHE_WARMING_ENABLED, true),
+			TTL:      time.Duration(env.GetEnvInt(CACHE_WARMING_TTL_MINUTES, 10)) * time.Minute,
+			TopN:     env.GetEnvInt(CACHE_WARMING_TOP_N, 100),
+			Lead:     time.Duration(env.GetEnvInt(CACHE_WARMING_LEAD_MINUTES, 3)) * time.Minute,
+			Interval: time.Duration(env.GetEnvInt(CACHE_WARMING_INTERVAL_MINUTES, 1)) * time.Minute,
+		},
+		Health: HealthConfig{
+			Timeout: time.Duration(env.GetEnvInt(HEALTH_TIMEOUT_SECONDS, 3)) * time.Second,
+		},
+		Email: EmailConfig{
+			ResendApiKey:        env.GetEnvString(RESEND_API_KEY, ""),
+			From:                env.GetEnvString(EMAIL_FROM, ""),
+			Workers:             env.GetEnvInt(EMAIL_WORKERS, 4),
+			QueueSize:           env.GetEnvInt(EMAIL_QUEUE_SIZE, 1000),
+			DomainRatePerSecond: float64(env.GetEnvInt(EMAIL_DOMAIN_RATE_PER_SECOND, 2)),
+		},
+		Support: SupportConfig{
+			TeamEmail:        env.GetEnvString(SUPPORT_TEAM_EMAIL, ""),
+			SLACheckInterval: time.Duration(env.GetEnvInt(SUPPORT_SLA_CHECK_MINUTES, 5)) * time.Minute,
+		},
+		Mock: MockConfig{
+			Externals: env.GetEnvBool(MOCK_EXTERNALS, false),
+		},
+		OAuth: OAuthConfig{
+			Google: auth.Config{
+				ClientID:     env.GetEnvString(OAUTH_GOOGLE_CLIENT_ID, ""),
+				ClientSecret: env.GetEnvString(OAUTH_GOOGLE_CLIENT_SECRET, ""),
+			},
+			GitHub: auth.Config{
+				ClientID:     env.GetEnvString(OAUTH_GITHUB_CLIENT_ID, ""),
+				ClientSecret: env.GetEnvString(OAUTH_GITHUB_CLIENT_SECRET, ""),
+			},
+			CallbackBaseURL:    strings.TrimSuffix(env.GetEnvString(OAUTH_CALLBACK_BASE_URL, "http://localhost:8000"), "/"),
+			SuccessRedirectURL: env.GetEnvString(OAUTH_SUCCESS_REDIRECT_URL, ""),
+		},
+		Bulkheads: BulkheadsConfig{
+			Api: middleware.BulkheadConfig{
+				MaxInFlight: env.GetEnvInt(BULKHEAD_API_MAX_IN_FLIGHT, 0),
+				MaxQueue:    env.GetEnvInt(BULKHEAD_API_MAX_QUEUE, 100),
+				MaxWait:     time.Duration(env.GetEnvInt(BULKHEAD_API_MAX_WAIT_MS, 500)) * time.Millisecond,
+			},
+			Analytics: middleware.BulkheadConfig{
+				MaxInFlight: env.GetEnvInt(BULKHEAD_ANALYTICS_MAX_IN_FLIGHT, 4),
+				MaxQueue:    env.GetEnvInt(BULKHEAD_ANALYTICS_MAX_QUEUE, 8),
+				MaxWait:     time.Duration(env.GetEnvInt(BULKHEAD_ANALYTICS_MAX_WAIT_MS, 2000)) * time.Millisecond,
+			},
+		},
+	}
+}
+
+// parseRateLimiterRoutes reads prefix=requests/window entries, e.g.
+// "/auth=10/1m,/api/v1/support=30/1h"
+func parseRateLimiterRoutes(value string) map[string]ratelimiter.Config {
+	routes := map[string]ratelimiter.Config{}
+
+	for _, entry := range strings.Split(value, ",") {
+		prefix, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || prefix == "" {
+			continue
+		}
+
+		requests, window, ok := strings.Cut(limit, "/")
+		if !ok {
+			continue
+		}
+		count, err := strconv.Atoi(requests)
+		if err != nil || count <= 0 {
+			continue
+		}
+		timeFrame, err := time.ParseDuration(window)
+		if err != nil || timeFrame <= 0 {
+			continue
+		}
+
+		routes[prefix] = ratelimiter.Config{RequestsPerTimeFrame: count, TimeFrame: timeFrame}
 	}
+
+	return routes
 }
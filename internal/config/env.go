@@ -2,13 +2,79 @@ package config
 
 // Define enums for environment variable keys
 const (
-	HOST                                 = "HOST"
-	PORT                                 = "PORT"
-	DATABASE_URL                         = "DATABASE_URL"
-	JWT_SECRET                           = "JWT_SECRET"
-	JWT_TOKEN_EXPIRATION                 = "JWT_TOKEN_EXPIRATION"
-	JWT_REFRESH_EXPIRATION               = "JWT_REFRESH_EXPIRATION"
-	RATE_LIMITER_ENABLED                 = "RATE_LIMITER_ENABLED"
-	RATE_LIMITER_REQUESTS_PER_TIME_FRAME = "RATE_LIMITER_REQUESTS_PER_TIME_FRAME"
-	RATE_LIMITER_TIME_FRAME_MINUTES      = "RATE_LIMITER_TIME_FRAME_MINUTES"
+	HOST                                  = "HOST"
+	PORT                                  = "PORT"
+	DATABASE_URL                          = "DATABASE_URL"
+	DATABASE_REPLICA_URL                  = "DATABASE_REPLICA_URL"
+	DATABASE_MAX_OPEN_CONNS               = "DATABASE_MAX_OPEN_CONNS"
+	DATABASE_MAX_IDLE_CONNS               = "DATABASE_MAX_IDLE_CONNS"
+	DATABASE_CONN_MAX_LIFETIME_MINUTES    = "DATABASE_CONN_MAX_LIFETIME_MINUTES"
+	JWT_SECRET                            = "JWT_SECRET"
+	JWT_TOKEN_EXPIRATION                  = "JWT_TOKEN_EXPIRATION"
+	JWT_REFRESH_EXPIRATION                = "JWT_REFRESH_EXPIRATION"
+	RATE_LIMITER_ENABLED                  = "RATE_LIMITER_ENABLED"
+	RATE_LIMITER_REQUESTS_PER_TIME_FRAME  = "RATE_LIMITER_REQUESTS_PER_TIME_FRAME"
+	RATE_LIMITER_TIME_FRAME_MINUTES       = "RATE_LIMITER_TIME_FRAME_MINUTES"
+	RATE_LIMITER_BACKEND                  = "RATE_LIMITER_BACKEND"
+	RATE_LIMITER_ROUTES                   = "RATE_LIMITER_ROUTES"
+	REDIS_URL                             = "REDIS_URL"
+	ADMIN_API_KEY                         = "ADMIN_API_KEY"
+	ADMIN_OPERATOR_API_KEY                = "ADMIN_OPERATOR_API_KEY"
+	API_USAGE_ENABLED                     = "API_USAGE_ENABLED"
+	API_USAGE_ALERT_THRESHOLD             = "API_USAGE_ALERT_THRESHOLD"
+	API_USAGE_TOP_CONSUMERS               = "API_USAGE_TOP_CONSUMERS"
+	COMMISSION_DEFAULT_RATE_BPS           = "COMMISSION_DEFAULT_RATE_BPS"
+	ENCRYPTION_KEY                        = "ENCRYPTION_KEY"
+	STORAGE_BUCKET_NAME                   = "STORAGE_BUCKET_NAME"
+	STORAGE_ACCOUNT_ID                    = "STORAGE_ACCOUNT_ID"
+	STORAGE_ACCESS_KEY_ID                 = "STORAGE_ACCESS_KEY_ID"
+	STORAGE_SECRET_ACCESS_KEY             = "STORAGE_SECRET_ACCESS_KEY"
+	STORAGE_PUBLIC_DOMAIN                 = "STORAGE_PUBLIC_DOMAIN"
+	STORAGE_USE_PUBLIC_URL                = "STORAGE_USE_PUBLIC_URL"
+	BACKUP_INTERVAL_HOURS                 = "BACKUP_INTERVAL_HOURS"
+	CUSTOMER_STORAGE_HEALTH_CHECK_MINUTES = "CUSTOMER_STORAGE_HEALTH_CHECK_MINUTES"
+	SSE_BUFFER_SIZE                       = "SSE_BUFFER_SIZE"
+	SSE_HEARTBEAT_SECONDS                 = "SSE_HEARTBEAT_SECONDS"
+	SSE_REPLAY_LIMIT                      = "SSE_REPLAY_LIMIT"
+	SSE_STREAM_MAX_LEN                    = "SSE_STREAM_MAX_LEN"
+	SSE_RETENTION_HOURS                   = "SSE_RETENTION_HOURS"
+	SSE_MAX_CONNECTIONS_PER_USER          = "SSE_MAX_CONNECTIONS_PER_USER"
+	SSE_MAX_CONNECTIONS                   = "SSE_MAX_CONNECTIONS"
+	LOCK_LEASE_TTL_SECONDS                = "LOCK_LEASE_TTL_SECONDS"
+	SCHEMA_POLICY                         = "SCHEMA_POLICY"
+	SEED_DEFAULTS_FILE                    = "SEED_DEFAULTS_FILE"
+	SEED_DEFAULTS_ON_STARTUP              = "SEED_DEFAULTS_ON_STARTUP"
+	QUERY_TAGS_ENABLED                    = "QUERY_TAGS_ENABLED"
+	BULKHEAD_API_MAX_IN_FLIGHT            = "BULKHEAD_API_MAX_IN_FLIGHT"
+	BULKHEAD_API_MAX_QUEUE                = "BULKHEAD_API_MAX_QUEUE"
+	BULKHEAD_API_MAX_WAIT_MS              = "BULKHEAD_API_MAX_WAIT_MS"
+	BULKHEAD_ANALYTICS_MAX_IN_FLIGHT      = "BULKHEAD_ANALYTICS_MAX_IN_FLIGHT"
+	BULKHEAD_ANALYTICS_MAX_QUEUE          = "BULKHEAD_ANALYTICS_MAX_QUEUE"
+	BULKHEAD_ANALYTICS_MAX_WAIT_MS        = "BULKHEAD_ANALYTICS_MAX_WAIT_MS"
+	ACTIVITY_RETENTION_DAYS               = "ACTIVITY_RETENTION_DAYS"
+	TENANCY_ENABLED                       = "TENANCY_ENABLED"
+	TENANCY_HEADER                        = "TENANCY_HEADER"
+	CHAT_ALERTS_REQUESTS_PER_TIME_FRAME   = "CHAT_ALERTS_REQUESTS_PER_TIME_FRAME"
+	CHAT_ALERTS_TIME_FRAME_MINUTES        = "CHAT_ALERTS_TIME_FRAME_MINUTES"
+	CACHE_WARMING_ENABLED                 = "CACHE_WARMING_ENABLED"
+	CACHE_WARMING_TTL_MINUTES             = "CACHE_WARMING_TTL_MINUTES"
+	CACHE_WARMING_TOP_N                   = "CACHE_WARMING_TOP_N"
+	CACHE_WARMING_LEAD_MINUTES            = "CACHE_WARMING_LEAD_MINUTES"
+	CACHE_WARMING_INTERVAL_MINUTES        = "CACHE_WARMING_INTERVAL_MINUTES"
+	HEALTH_TIMEOUT_SECONDS                = "HEALTH_TIMEOUT_SECONDS"
+	SHUTDOWN_TIMEOUT_SECONDS              = "SHUTDOWN_TIMEOUT_SECONDS"
+	RESEND_API_KEY                        = "RESEND_API_KEY"
+	EMAIL_FROM                            = "EMAIL_FROM"
+	EMAIL_WORKERS                         = "EMAIL_WORKERS"
+	EMAIL_QUEUE_SIZE                      = "EMAIL_QUEUE_SIZE"
+	EMAIL_DOMAIN_RATE_PER_SECOND          = "EMAIL_DOMAIN_RATE_PER_SECOND"
+	SUPPORT_TEAM_EMAIL                    = "SUPPORT_TEAM_EMAIL"
+	SUPPORT_SLA_CHECK_MINUTES             = "SUPPORT_SLA_CHECK_MINUTES"
+	MOCK_EXTERNALS                        = "MOCK_EXTERNALS"
+	OAUTH_GOOGLE_CLIENT_ID                = "OAUTH_GOOGLE_CLIENT_ID"
+	OAUTH_GOOGLE_CLIENT_SECRET            = "OAUTH_GOOGLE_CLIENT_SECRET"
+	OAUTH_GITHUB_CLIENT_ID                = "OAUTH_GITHUB_CLIENT_ID"
+	OAUTH_GITHUB_CLIENT_SECRET            = "OAUTH_GITHUB_CLIENT_SECRET"
+	OAUTH_CALLBACK_BASE_URL               = "OAUTH_CALLBACK_BASE_URL"
+	OAUTH_SUCCESS_REDIRECT_URL            = "OAUTH_SUCCESS_REDIRECT_URL"
 )
@@ -1,15 +1,281 @@
 package database
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
 	"gorm.io/gorm"
 )
 
+// Migration is a versioned schema change. Breaking migrations drop or
+// rename something the previous release still reads, so they can't be
+// applied while that release is serving traffic.
+type Migration struct {
+	Version  int
+	Name     string
+	Breaking bool
+	Up       func(tx *gorm.DB) error
+
+	// Down reverts Up for schema rollback. Migrations without one, like
+	// the baseline, can't be rolled back.
+	Down func(tx *gorm.DB) error
+
+	// PublicOnly migrations change shared tables and are skipped in tenant
+	// schemas, which still record them to keep versions aligned
+	PublicOnly bool
+}
+
+// migrations are applied in order. Append new migrations to the end and
+// never edit one that already shipped. Migrations written as SQL files are
+// added from the sql directory, see sql_migrations.go.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&models.User{},
+				&models.Tombstone{},
+				&models.ApiUsageDaily{},
+				&models.CommissionRule{},
+				&models.BackupRecord{},
+				&models.UserStorageConfig{},
+				&models.AdminActionExecution{},
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "seed_records",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.SeedRecord{})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "user_claims_version",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.User{})
+		},
+		Down: dropColumn(&models.User{}, "ClaimsVersion"),
+	},
+	{
+		Version: 4,
+		Name:    "activities",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Activity{})
+		},
+		Down: dropTables(&models.Activity{}),
+	},
+	{
+		Version: 5,
+		Name:    "storage_deletions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.StorageDeletion{})
+		},
+		Down: dropTables(&models.StorageDeletion{}),
+	},
+	{
+		Version:    6,
+		Name:       "tenants",
+		PublicOnly: true,
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Tenant{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "chat_channels",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.ChatChannel{})
+		},
+		Down: dropTables(&models.ChatChannel{}),
+	},
+	{
+		Version: 8,
+		Name:    "support_tickets",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&models.User{},
+				&models.SupportTicket{},
+				&models.SupportTicketMessage{},
+				&models.SupportTicketAttachment{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			err := tx.Migrator().DropTable(
+				&models.SupportTicket{},
+				&models.SupportTicketMessage{},
+				&models.SupportTicketAttachment{},
+			)
+			if err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&models.User{}, "SupportTier")
+		},
+	},
+	{
+		Version: 9,
+		Name:    "refresh_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.RefreshToken{})
+		},
+		Down: dropTables(&models.RefreshToken{}),
+	},
+	{
+		Version: 10,
+		Name:    "user_identities",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.UserIdentity{})
+		},
+		Down: dropTables(&models.UserIdentity{}),
+	},
+	{
+		Version: 11,
+		Name:    "webhooks",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{})
+		},
+		Down: dropTables(&models.Webhook{}, &models.WebhookDelivery{}),
+	},
+	{
+		Version: 12,
+		Name:    "audit_logs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.AuditLog{})
+		},
+		Down: dropTables(&models.AuditLog{}),
+	},
+	{
+		Version:    13,
+		Name:       "incidents",
+		PublicOnly: true,
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Incident{}, &models.IncidentUpdate{})
+		},
+		Down: dropTables(&models.Incident{}, &models.IncidentUpdate{}),
+	},
+}
+
+// ExpectedSchemaVersion is the schema version this build was written for
+func ExpectedSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrations returns the migrations known to this build
+func Migrations() []Migration {
+	return migrations
+}
+
+// Migrate applies the pending migrations, each in its own transaction
 func Migrate(db *gorm.DB) error {
+	return migrate(db, false)
+}
+
+func migrate(db *gorm.DB, tenant bool) error {
+	// The schema is chosen by the connection's search_path, not the tenant
+	// plugin
+	db = db.WithContext(tenancy.Public(db.Statement.Context))
+
+	if err := db.AutoMigrate(&models.SchemaMigration{}); err != nil {
+		return err
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return err
+	}
 
-	err := db.AutoMigrate(
-		&models.User{},
-	)
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if !(tenant && migration.PublicOnly) {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&models.SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Breaking:  migration.Breaking,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Rollback reverts the applied migrations above version to, newest first,
+// each in its own transaction. Nothing is reverted unless every one of them
+// has a Down. Rolling back under a running release breaks it like a
+// breaking migration would, stop or roll back the release first.
+func Rollback(db *gorm.DB, to int) error {
+	return rollback(db, to, false)
+}
+
+func rollback(db *gorm.DB, to int, tenant bool) error {
+	db = db.WithContext(tenancy.Public(db.Statement.Context))
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > ExpectedSchemaVersion() {
+		return fmt.Errorf("schema is at version %d, newer than this build (%d); roll back with the release that applied it", current, ExpectedSchemaVersion())
+	}
+
+	var pending []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > current || migration.Version <= to {
+			continue
+		}
+		if migration.Down == nil {
+			return fmt.Errorf("migration %d (%s) can't be rolled back", migration.Version, migration.Name)
+		}
+		pending = append(pending, migration)
+	}
+
+	for _, migration := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if !(tenant && migration.PublicOnly) {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Delete(&models.SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func dropTables(tables ...any) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(tables...)
+	}
+}
+
+func dropColumn(model any, field string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(model, field)
+	}
+}
 
-	return err
+func currentVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.WithContext(tenancy.Public(db.Statement.Context)).Model(&models.SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
 }
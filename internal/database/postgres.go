@@ -1,15 +1,43 @@
 package database
 
 import (
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/app"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func NewPostgresDatabase(url string) (*gorm.DB, error) {
+// PoolConfig bounds the connections of a database. Zero values keep the
+// database/sql defaults: unlimited open connections and lifetime, 2 idle.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// PoolConfigFrom returns the pool limits of the database configuration
+func PoolConfigFrom(cfg app.DbConfig) PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+	}
+}
+
+func NewPostgresDatabase(url string, pool PoolConfig) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+
 	return db, nil
 }
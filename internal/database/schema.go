@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/imlargo/go-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// SchemaPolicy decides what the API does when the schema doesn't match
+// the code
+type SchemaPolicy string
+
+const (
+	SchemaPolicyFail     SchemaPolicy = "fail"
+	SchemaPolicyWarn     SchemaPolicy = "warn"
+	SchemaPolicyReadOnly SchemaPolicy = "read_only"
+)
+
+func ParseSchemaPolicy(value string) (SchemaPolicy, error) {
+	switch policy := SchemaPolicy(value); policy {
+	case SchemaPolicyFail, SchemaPolicyWarn, SchemaPolicyReadOnly:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown schema policy %q", value)
+	}
+}
+
+// SchemaStatus compares the applied schema with the one this build expects
+type SchemaStatus struct {
+	Current    int    `json:"current"`
+	Expected   int    `json:"expected"`
+	Compatible bool   `json:"compatible"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// CheckSchema reports whether this build can run against the database.
+// A newer schema is fine as long as none of the migrations this build
+// doesn't know about are breaking, which is what happens during a blue/green
+// deploy when the new release migrated first.
+func CheckSchema(db *gorm.DB) (*SchemaStatus, error) {
+	status := &SchemaStatus{Expected: ExpectedSchemaVersion()}
+
+	if !db.Migrator().HasTable(&models.SchemaMigration{}) {
+		status.Reason = "schema_migrations table is missing, run the migrations"
+		return status, nil
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	status.Current = current
+
+	if current < status.Expected {
+		status.Reason = fmt.Sprintf("schema is at version %d, this build needs %d", current, status.Expected)
+		return status, nil
+	}
+
+	var breaking []models.SchemaMigration
+	err = db.Where("version > ? AND breaking = ?", status.Expected, true).Order("version").Find(&breaking).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(breaking) > 0 {
+		status.Reason = fmt.Sprintf("breaking migration %d (%s) was applied after version %d", breaking[0].Version, breaking[0].Name, status.Expected)
+		return status, nil
+	}
+
+	status.Compatible = true
+	return status, nil
+}
+
+// VerifyMigrations checks the migration list for CI: versions must be
+// contiguous from 1, and nothing applied after previousVersion, the schema
+// of the release currently in production, may be breaking.
+func VerifyMigrations(previousVersion int) error {
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return fmt.Errorf("migration %q has version %d, expected %d", migration.Name, migration.Version, i+1)
+		}
+		if migration.Name == "" || migration.Up == nil {
+			return fmt.Errorf("migration %d is missing a name or an Up function", migration.Version)
+		}
+	}
+
+	if previousVersion > ExpectedSchemaVersion() {
+		return fmt.Errorf("previous release is at version %d, ahead of this build (%d)", previousVersion, ExpectedSchemaVersion())
+	}
+
+	for _, migration := range migrations {
+		if migration.Version > previousVersion && migration.Breaking {
+			return fmt.Errorf("migration %d (%s) is breaking for the release at version %d; ship it in a later release", migration.Version, migration.Name, previousVersion)
+		}
+	}
+
+	return nil
+}
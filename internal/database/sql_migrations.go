@@ -0,0 +1,153 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SQL migrations are files in the sql directory named
+// <version>_<name>.up.sql, with an optional <version>_<name>.down.sql to
+// roll them back. They take the next version after the migrations above
+// and are applied like them, each in a transaction. Directive comments in
+// the up file flag them:
+//
+//	-- migrate:breaking      the previous release can't run on the result
+//	-- migrate:public-only   changes shared tables, skipped in tenant schemas
+//
+// Statements must not qualify tables with a schema, tenant schemas run
+// them with their own search_path.
+//
+//go:embed sql
+var sqlFiles embed.FS
+
+const sqlMigrationsDir = "sql"
+
+var sqlMigrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var sqlMigrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func init() {
+	loaded, err := loadSQLMigrations(sqlFiles, sqlMigrationsDir)
+	if err != nil {
+		panic("database: " + err.Error())
+	}
+
+	migrations = append(migrations, loaded...)
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+// loadSQLMigrations reads the migrations of dir. Version clashes with the
+// Go migrations are reported by VerifyMigrations.
+func loadSQLMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := sqlMigrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("sql migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("sql migration %d has files named %s and %s", version, migration.Name, match[2])
+		}
+
+		statements := string(content)
+		switch match[3] {
+		case "up":
+			migration.Up = execSQL(statements)
+			migration.Breaking = hasDirective(statements, "breaking")
+			migration.PublicOnly = hasDirective(statements, "public-only")
+		case "down":
+			migration.Down = execSQL(statements)
+		}
+	}
+
+	loaded := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil {
+			return nil, fmt.Errorf("sql migration %d (%s) has no up file", migration.Version, migration.Name)
+		}
+		loaded = append(loaded, *migration)
+	}
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].Version < loaded[j].Version
+	})
+	return loaded, nil
+}
+
+func execSQL(statements string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(statements).Error
+	}
+}
+
+func hasDirective(statements, directive string) bool {
+	for _, line := range strings.Split(statements, "\n") {
+		if strings.TrimSpace(line) == "-- migrate:"+directive {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSQLMigration writes empty up and down files for the next version
+// in dir, the sql directory of this package, and returns their paths
+func CreateSQLMigration(dir, name string) ([]string, error) {
+	if !sqlMigrationName.MatchString(name) {
+		return nil, fmt.Errorf("migration name %q must be lower case letters, digits and underscores", name)
+	}
+
+	version := ExpectedSchemaVersion() + 1
+	files := map[string]string{
+		"up":   fmt.Sprintf("-- Migration %d: %s\n-- Add -- migrate:breaking or -- migrate:public-only lines when they apply\n\n", version, name),
+		"down": fmt.Sprintf("-- Reverts migration %d: %s\n\n", version, name),
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		filePath := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+		file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, err
+		}
+		_, err = file.WriteString(files[direction])
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, filePath)
+	}
+	return paths, nil
+}
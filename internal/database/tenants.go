@@ -0,0 +1,107 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MigrateTenant applies the pending migrations inside the schema of a
+// tenant. The migrations run on one connection whose search_path points
+// to the tenant schema, so they need no changes to support tenants.
+func MigrateTenant(db *gorm.DB, schema string) error {
+	return inSchema(db, schema, func(conn *gorm.DB) error {
+		return migrate(conn, true)
+	})
+}
+
+// RollbackTenant reverts the migrations above version to inside the schema
+// of a tenant, see Rollback
+func RollbackTenant(db *gorm.DB, schema string, to int) error {
+	return inSchema(db, schema, func(conn *gorm.DB) error {
+		return rollback(conn, to, true)
+	})
+}
+
+func inSchema(db *gorm.DB, schema string, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET search_path TO ?", clause.Table{Name: schema}).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("RESET search_path")
+
+		if err := fn(conn); err != nil {
+			return fmt.Errorf("tenant schema %s: %w", schema, err)
+		}
+		return nil
+	})
+}
+
+// MigrateTenants applies the pending migrations to every tenant schema and
+// records the resulting version in the registry. It stops at the first
+// failure so the remaining tenants stay on a known version.
+func MigrateTenants(db *gorm.DB) error {
+	var tenants []*models.Tenant
+	if err := db.Order("id ASC").Find(&tenants).Error; err != nil {
+		return err
+	}
+
+	for _, tenant := range tenants {
+		if err := MigrateTenant(db, tenant.Schema); err != nil {
+			return err
+		}
+		err := db.Model(tenant).UpdateColumn("schema_version", ExpectedSchemaVersion()).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RollbackTenants reverts every tenant schema to version to and records it
+// in the registry. Like MigrateTenants it stops at the first failure.
+func RollbackTenants(db *gorm.DB, to int) error {
+	var tenants []*models.Tenant
+	if err := db.Order("id ASC").Find(&tenants).Error; err != nil {
+		return err
+	}
+
+	for _, tenant := range tenants {
+		if err := RollbackTenant(db, tenant.Schema, to); err != nil {
+			return err
+		}
+		if err := db.Model(tenant).UpdateColumn("schema_version", to).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateTenantSchema creates the schema of a tenant and migrates it
+func CreateTenantSchema(db *gorm.DB, slug string) (string, error) {
+	if err := tenancy.ValidateSlug(slug); err != nil {
+		return "", err
+	}
+
+	schema := tenancy.SchemaName(slug)
+	if err := db.Exec("CREATE SCHEMA IF NOT EXISTS ?", clause.Table{Name: schema}).Error; err != nil {
+		return "", err
+	}
+	if err := MigrateTenant(db, schema); err != nil {
+		return "", err
+	}
+	return schema, nil
+}
+
+// DropTenantSchema deletes the schema of a tenant with all its data
+func DropTenantSchema(db *gorm.DB, slug string) error {
+	if err := tenancy.ValidateSlug(slug); err != nil {
+		return err
+	}
+	return db.Exec("DROP SCHEMA IF EXISTS ? CASCADE", clause.Table{Name: tenancy.SchemaName(slug)}).Error
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+)
+
+type ActivityItem struct {
+	ID        uint                `json:"id"`
+	Type      models.ActivityType `json:"type"`
+	CreatedAt time.Time           `json:"created_at"`
+	Data      json.RawMessage     `json:"data"`
+}
+
+type ActivityFeedResponse struct {
+	Items      []*ActivityItem `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+type AdminActionParamInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+type AdminActionInfo struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	RequiredRole string                 `json:"required_role"`
+	Params       []AdminActionParamInfo `json:"params"`
+	RateLimit    string                 `json:"rate_limit"`
+}
+
+type ExecuteAdminActionRequest struct {
+	Params map[string]string `json:"params"`
+	DryRun bool              `json:"dry_run"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/imlargo/go-api/internal/models"
+
+type ApiUsageResponse struct {
+	Daily []*models.ApiUsageDaily `json:"daily"`
+	Today []*models.ApiUsageDaily `json:"today"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogItem struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Actor      string    `json:"actor"`
+	UserID     *uint     `json:"user_id"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Path       string    `json:"path"`
+	Status     int       `json:"status"`
+	IP         string    `json:"ip"`
+	RequestID  string    `json:"request_id"`
+	EntityType string    `json:"entity_type,omitempty"`
+	EntityID   string    `json:"entity_id,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	// Changes maps each changed column to its before and after value
+	Changes json.RawMessage `json:"changes,omitempty"`
+}
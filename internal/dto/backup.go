@@ -0,0 +1,14 @@
+package dto
+
+type TableRestoreDiff struct {
+	Insert         int `json:"insert"`
+	Update         int `json:"update"`
+	Unchanged      int `json:"unchanged"`
+	OnlyInDatabase int `json:"only_in_database"`
+}
+
+type RestoreReport struct {
+	BackupID uint                         `json:"backup_id"`
+	DryRun   bool                         `json:"dry_run"`
+	Tables   map[string]*TableRestoreDiff `json:"tables"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type CreateChatChannelRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Provider   string   `json:"provider" binding:"required,oneof=slack discord"`
+	WebhookURL string   `json:"webhook_url" binding:"required"`
+	Events     []string `json:"events" binding:"required,min=1"`
+}
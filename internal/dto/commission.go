@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/money"
+)
+
+type CreateCommissionRuleRequest struct {
+	Category           string     `json:"category"`
+	SellerID           *uint      `json:"seller_id"`
+	MinCompletedOrders int        `json:"min_completed_orders" binding:"min=0"`
+	RateBps            int        `json:"rate_bps" binding:"min=0,max=10000"`
+	EffectiveFrom      *time.Time `json:"effective_from"`
+}
+
+type CommissionQuote struct {
+	RuleID      *uint       `json:"rule_id"`
+	RateBps     int         `json:"rate_bps"`
+	GrossAmount money.Money `json:"gross_amount"`
+	Commission  money.Money `json:"commission"`
+	NetAmount   money.Money `json:"net_amount"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+type SendNotificationRequestPayload struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Event  string `json:"event"`
+	Data   any    `json:"data" binding:"required"`
+}
+
+type NotificationAudience string
+
+const (
+	NotificationAudienceUsers     NotificationAudience = "users"
+	NotificationAudienceConnected NotificationAudience = "connected"
+)
+
+// BulkNotificationRequest sends one templated notification to an audience.
+// String values in Data may use {{.var}} placeholders, filled per recipient
+// from Vars, or from Defaults when a recipient has no entry.
+type BulkNotificationRequest struct {
+	Audience NotificationAudience       `json:"audience" binding:"required,oneof=users connected"`
+	UserIDs  []uint                     `json:"user_ids"`
+	Event    string                     `json:"event"`
+	Data     map[string]any             `json:"data" binding:"required"`
+	Vars     map[uint]map[string]string `json:"vars"`
+	Defaults map[string]string          `json:"defaults"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/imlargo/go-api/internal/database"
+
+type SchemaStatusResponse struct {
+	database.SchemaStatus
+	Policy   string `json:"policy"`
+	ReadOnly bool   `json:"read_only"`
+	// MockExternals is set when external services are replaced by
+	// in-memory ones
+	MockExternals bool `json:"mock_externals"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+// SeedDefaults are the system defaults a fresh environment needs, read
+// from the file at SEED_DEFAULTS_FILE
+type SeedDefaults struct {
+	CommissionRules []SeedCommissionRule `json:"commission_rules"`
+}
+
+// SeedCommissionRule is a default rate for every seller of a category and
+// tier. An empty category is the global default.
+type SeedCommissionRule struct {
+	Category           string `json:"category"`
+	MinCompletedOrders int    `json:"min_completed_orders"`
+	RateBps            int    `json:"rate_bps"`
+}
+
+type SeedOutcome string
+
+const (
+	SeedOutcomeCreated   SeedOutcome = "created"
+	SeedOutcomeUpdated   SeedOutcome = "updated"
+	SeedOutcomeUnchanged SeedOutcome = "unchanged"
+	SeedOutcomeKept      SeedOutcome = "kept_manual_edit"
+)
+
+type SeedResult struct {
+	Key     string      `json:"key"`
+	Outcome SeedOutcome `json:"outcome"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type RevokeSessionsRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type RevokeSessionsResponse struct {
+	UserID        uint  `json:"user_id"`
+	ClaimsVersion int64 `json:"claims_version"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenPairResponse struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
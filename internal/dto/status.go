@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+)
+
+// CreateIncidentRequest opens an incident with its first update. StartedAt
+// defaults to now.
+type CreateIncidentRequest struct {
+	Title      string     `json:"title" binding:"required,max=200"`
+	Impact     string     `json:"impact" binding:"required,oneof=minor major critical"`
+	Status     string     `json:"status" binding:"omitempty,oneof=investigating identified monitoring"`
+	Components []string   `json:"components" binding:"required,min=1"`
+	Message    string     `json:"message" binding:"required"`
+	StartedAt  *time.Time `json:"started_at"`
+}
+
+// PostIncidentUpdateRequest moves an incident forward. Resolved closes it;
+// Impact is only changed when given.
+type PostIncidentUpdateRequest struct {
+	Status  string  `json:"status" binding:"required,oneof=investigating identified monitoring resolved"`
+	Message string  `json:"message" binding:"required"`
+	Impact  *string `json:"impact" binding:"omitempty,oneof=minor major critical"`
+}
+
+// StatusComponent is the current state of a part of the platform:
+// operational, degraded_performance, partial_outage or major_outage
+type StatusComponent struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+// StatusDay is the worst state each component had on a UTC day, from the
+// incidents published for it
+type StatusDay struct {
+	Date       string            `json:"date"`
+	Components map[string]string `json:"components"`
+}
+
+// StatusPageResponse is the public status feed. Incidents are the active
+// ones and those resolved within the history, newest first.
+type StatusPageResponse struct {
+	Status     string             `json:"status"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	Components []StatusComponent  `json:"components"`
+	Incidents  []*models.Incident `json:"incidents"`
+	History    []StatusDay        `json:"history"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+type SetStorageConfigRequest struct {
+	Provider        string `json:"provider" binding:"required"`
+	BucketName      string `json:"bucket_name" binding:"required"`
+	AccountID       string `json:"account_id" binding:"required"`
+	AccessKeyID     string `json:"access_key_id" binding:"required"`
+	SecretAccessKey string `json:"secret_access_key" binding:"required"`
+	PublicDomain    string `json:"public_domain"`
+	UsePublicURL    bool   `json:"use_public_url"`
+}
+
+type StorageMigrationReport struct {
+	UserID        uint     `json:"user_id"`
+	Prefix        string   `json:"prefix"`
+	Total         int      `json:"total"`
+	Copied        int      `json:"copied"`
+	Failed        []string `json:"failed"`
+	DeletedSource bool     `json:"deleted_source"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// OrphanScanReport compares the objects under a bucket prefix with the rows
+// referencing them
+type OrphanScanReport struct {
+	Source     string    `json:"source"`
+	Prefix     string    `json:"prefix"`
+	ScannedAt  time.Time `json:"scanned_at"`
+	Objects    int       `json:"objects"`
+	Referenced int       `json:"referenced"`
+
+	// Orphans are objects no row references and no deletion is queued for
+	Orphans []string `json:"orphans"`
+	// Missing are keys referenced by a row with no object in the bucket
+	Missing []string `json:"missing"`
+	// Enqueued is how many orphans were queued for deletion
+	Enqueued int `json:"enqueued"`
+}
+
+type StorageConsistencyStats struct {
+	PendingDeletions int64               `json:"pending_deletions"`
+	FailedDeletions  int64               `json:"failed_deletions"`
+	DeletedObjects   int64               `json:"deleted_objects"`
+	LastScans        []*OrphanScanReport `json:"last_scans"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "io"
+
+// CreateSupportTicketRequest is sent as multipart form data when the ticket
+// has attachments, in the attachments field
+type CreateSupportTicketRequest struct {
+	Category    string `json:"category" form:"category" binding:"required,oneof=billing technical account other"`
+	Subject     string `json:"subject" form:"subject" binding:"required,max=200"`
+	Description string `json:"description" form:"description" binding:"required"`
+}
+
+// SupportAttachmentUpload is a file of a new ticket, read by the service
+// while uploading it
+type SupportAttachmentUpload struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Content     io.Reader
+}
+
+type ReplySupportTicketRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// UpdateSupportTicketRequest changes a ticket from the admin queue. Fields
+// left out are unchanged; an empty assigned_to unassigns the ticket.
+type UpdateSupportTicketRequest struct {
+	Status     *string `json:"status" binding:"omitempty,oneof=open in_progress waiting_on_customer resolved closed"`
+	AssignedTo *string `json:"assigned_to"`
+	Message    string  `json:"message"`
+}
+
+type SetSupportTierRequest struct {
+	Tier string `json:"tier" binding:"required,oneof=standard priority enterprise"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type SyncEntityChanges struct {
+	Updated []any  `json:"updated"`
+	Deleted []uint `json:"deleted"`
+	HasMore bool   `json:"has_more"`
+}
+
+type SyncResponse struct {
+	Changes    map[string]*SyncEntityChanges `json:"changes"`
+	NextCursor string                        `json:"next_cursor"`
+	HasMore    bool                          `json:"has_more"`
+}
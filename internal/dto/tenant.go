@@ -0,0 +1,5 @@
+package dto
+
+type ProvisionTenantRequest struct {
+	Slug string `json:"slug" binding:"required"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+)
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" binding:"required,min=1"`
+}
+
+// UpdateWebhookRequest changes a webhook. Fields left out are unchanged.
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url"`
+	Description *string  `json:"description"`
+	Events      []string `json:"events" binding:"omitempty,min=1"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// WebhookCreatedResponse is the only response that includes the secret
+// deliveries are signed with
+type WebhookCreatedResponse struct {
+	Webhook *models.Webhook `json:"webhook"`
+	Secret  string          `json:"secret"`
+}
+
+type WebhookDeliveryItem struct {
+	ID             uint                         `json:"id"`
+	Event          string                       `json:"event"`
+	Status         models.WebhookDeliveryStatus `json:"status"`
+	CreatedAt      time.Time                    `json:"created_at"`
+	Attempts       int                          `json:"attempts"`
+	NextAttemptAt  time.Time                    `json:"next_attempt_at"`
+	ResponseStatus int                          `json:"response_status,omitempty"`
+	LastError      string                       `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                   `json:"delivered_at"`
+	Payload        json.RawMessage              `json:"payload"`
+}
+
+// WebhookPayload is the body posted to webhooks
+type WebhookPayload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
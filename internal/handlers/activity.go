@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type ActivityHandler struct {
+	*handler.Handler
+	activityService service.ActivityService
+}
+
+func NewActivityHandler(handler *handler.Handler, activityService service.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		Handler:         handler,
+		activityService: activityService,
+	}
+}
+
+// @Summary		Activity feed
+// @Description	Returns what happened on the account, newest first
+// @Tags			activity
+// @Produce		json
+// @Param			types	query		string	false	"Comma separated activity types"
+// @Param			cursor	query		string	false	"Cursor returned by the previous page"
+// @Param			limit	query		int		false	"Max entries, up to 100"
+// @Success		200		{object}	dto.ActivityFeedResponse
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/api/v1/activity [get]
+// @Security		BearerAuth
+func (h *ActivityHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	var types []string
+	if value := c.Query("types"); value != "" {
+		for _, activityType := range strings.Split(value, ",") {
+			if activityType = strings.TrimSpace(activityType); activityType != "" {
+				types = append(types, activityType)
+			}
+		}
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			responses.ErrorBadRequest(c, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	feed, err := h.activityService.List(c.Request.Context(), userID, types, c.Query("cursor"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, feed)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+const adminActorHeader = "X-Admin-Actor"
+
+type AdminActionHandler struct {
+	*handler.Handler
+	adminActionService service.AdminActionService
+}
+
+func NewAdminActionHandler(handler *handler.Handler, adminActionService service.AdminActionService) *AdminActionHandler {
+	return &AdminActionHandler{
+		Handler:            handler,
+		adminActionService: adminActionService,
+	}
+}
+
+// @Summary		List admin actions
+// @Description	Returns the actions the caller's role is allowed to run
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	dto.AdminActionInfo
+// @Router			/admin/actions [get]
+// @Security		ApiKeyAuth
+func (h *AdminActionHandler) List(c *gin.Context) {
+	responses.SuccessOK(c, h.adminActionService.List(getAdminRole(c)))
+}
+
+// @Summary		Execute admin action
+// @Description	Runs a registered action. Set dry_run to preview the effect without changing anything. Every attempt is audited.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			name			path		string							true	"Action name"
+// @Param			X-Admin-Actor	header		string							false	"Person running the action"
+// @Param			payload			body		dto.ExecuteAdminActionRequest	true	"Params"
+// @Success		200				{object}	models.AdminActionExecution
+// @Failure		400				{object}	responses.ErrorResponse
+// @Failure		403				{object}	responses.ErrorResponse
+// @Failure		429				{object}	responses.ErrorResponse
+// @Router			/admin/actions/{name} [post]
+// @Security		ApiKeyAuth
+func (h *AdminActionHandler) Execute(c *gin.Context) {
+	var payload dto.ExecuteAdminActionRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	role := getAdminRole(c)
+	actor := c.GetHeader(adminActorHeader)
+	if actor == "" {
+		actor = string(role)
+	}
+
+	execution, err := h.adminActionService.Execute(c.Request.Context(), actor, role, c.Param("name"), payload.Params, payload.DryRun)
+	if err != nil {
+		// Failed runs return the recorded execution with the error
+		if _, ok := apperrors.As(err); !ok {
+			responses.ErrorInternalServerWithMessage(c, err.Error(), execution)
+			return
+		}
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, execution)
+}
+
+// @Summary		List admin action executions
+// @Description	Returns the audit log of admin actions, newest first. Filters take an operator in brackets, e.g. created_at[gte]=2025-01-01 or status[in]=failed,rejected.
+// @Tags			admin
+// @Produce		json
+// @Param			action		query	string	false	"Filter by action name (eq, in)"
+// @Param			actor		query	string	false	"Filter by actor"
+// @Param			status		query	string	false	"Filter by status (eq, ne, in)"
+// @Param			dry_run		query	bool	false	"Filter dry runs"
+// @Param			sort		query	string	false	"created_at or duration_ms, prefixed with - for descending (default -created_at)"
+// @Param			limit		query	int		false	"Max records (default 50)"
+// @Success		200			{array}		models.AdminActionExecution
+// @Failure		400			{object}	responses.ErrorResponse
+// @Router			/admin/actions/executions [get]
+// @Security		ApiKeyAuth
+func (h *AdminActionHandler) ListExecutions(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		responses.ErrorBadRequest(c, "limit must be between 1 and 500")
+		return
+	}
+
+	executions, err := h.adminActionService.ListExecutions(c.Request.Context(), c.Request.URL.Query(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, executions)
+}
+
+// getAdminRole returns the role set by the API key roles middleware
+func getAdminRole(c *gin.Context) service.AdminRole {
+	return service.AdminRole(c.GetString("apiKeyRole"))
+}
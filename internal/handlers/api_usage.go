@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type ApiUsageHandler struct {
+	*handler.Handler
+	apiUsageService service.ApiUsageService
+}
+
+func NewApiUsageHandler(handler *handler.Handler, apiUsageService service.ApiUsageService) *ApiUsageHandler {
+	return &ApiUsageHandler{
+		Handler:         handler,
+		apiUsageService: apiUsageService,
+	}
+}
+
+// @Summary		Get my API usage
+// @Description	Returns the daily API usage of the authenticated user plus today's live counters
+// @Tags			usage
+// @Produce		json
+// @Param			from	query		string	false	"Start date (YYYY-MM-DD)"
+// @Param			to		query		string	false	"End date (YYYY-MM-DD)"
+// @Success		200		{object}	dto.ApiUsageResponse
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/api/v1/usage/api [get]
+// @Security		BearerAuth
+func (h *ApiUsageHandler) GetMyUsage(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	from, to, err := parseDateRange(c, 30)
+	if err != nil {
+		responses.ErrorBadRequest(c, err.Error())
+		return
+	}
+
+	usage, err := h.apiUsageService.GetUserUsage(c.Request.Context(), userID, from, to)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, usage)
+}
+
+// @Summary		Get API usage breakdown
+// @Description	Returns API usage aggregated per user and route, optionally for a single user
+// @Tags			admin
+// @Produce		json
+// @Param			user_id	query		int		false	"Filter by user"
+// @Param			from	query		string	false	"Start date (YYYY-MM-DD)"
+// @Param			to		query		string	false	"End date (YYYY-MM-DD)"
+// @Success		200		{array}		models.ApiUsageTotal
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/usage/api [get]
+// @Security		ApiKeyAuth
+func (h *ApiUsageHandler) GetBreakdown(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30)
+	if err != nil {
+		responses.ErrorBadRequest(c, err.Error())
+		return
+	}
+
+	var userID uint
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		parsed, err := strconv.ParseUint(userIDStr, 10, 64)
+		if err != nil {
+			responses.ErrorBadRequest(c, "invalid user_id")
+			return
+		}
+		userID = uint(parsed)
+	}
+
+	breakdown, err := h.apiUsageService.GetBreakdown(c.Request.Context(), userID, from, to)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, breakdown)
+}
+
+// @Summary		Get top API consumers
+// @Description	Returns the users with the most requests in the date range
+// @Tags			admin
+// @Produce		json
+// @Param			limit	query		int		false	"Number of users"
+// @Param			from	query		string	false	"Start date (YYYY-MM-DD)"
+// @Param			to		query		string	false	"End date (YYYY-MM-DD)"
+// @Success		200		{array}		models.ApiUsageTotal
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/usage/api/top [get]
+// @Security		ApiKeyAuth
+func (h *ApiUsageHandler) GetTopConsumers(c *gin.Context) {
+	from, to, err := parseDateRange(c, 30)
+	if err != nil {
+		responses.ErrorBadRequest(c, err.Error())
+		return
+	}
+
+	limit := 10
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			responses.ErrorBadRequest(c, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	consumers, err := h.apiUsageService.GetTopConsumers(c.Request.Context(), from, to, limit)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, consumers)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type AuditLogHandler struct {
+	*handler.Handler
+	auditLogService service.AuditLogService
+}
+
+func NewAuditLogHandler(handler *handler.Handler, auditLogService service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{
+		Handler:         handler,
+		auditLogService: auditLogService,
+	}
+}
+
+// @Summary		List audit logs
+// @Description	Returns the writes made by POST, PUT, PATCH and DELETE requests, newest first, with the before and after value of the changed columns when known. Filters take an operator in brackets, e.g. created_at[gte]=2025-01-01&created_at[lt]=2025-02-01 or entity_type[in]=webhooks,support_tickets.
+// @Tags			admin
+// @Produce		json
+// @Param			user_id		query	int		false	"Filter by user"
+// @Param			actor		query	string	false	"Filter by actor, e.g. user:12 or api_key:admin (eq, in)"
+// @Param			entity_type	query	string	false	"Filter by table, e.g. support_tickets (eq, in)"
+// @Param			entity_id	query	string	false	"Filter by entity id"
+// @Param			action		query	string	false	"created, updated or deleted (eq, in)"
+// @Param			created_at	query	string	false	"Filter by date (gte, lt)"
+// @Param			sort		query	string	false	"created_at, prefixed with - for descending (default -created_at)"
+// @Param			limit		query	int		false	"Max records (default 50)"
+// @Success		200			{array}		dto.AuditLogItem
+// @Failure		400			{object}	responses.ErrorResponse
+// @Router			/admin/audit-logs [get]
+// @Security		ApiKeyAuth
+func (h *AuditLogHandler) List(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		responses.ErrorBadRequest(c, "limit must be between 1 and 500")
+		return
+	}
+
+	logs, err := h.auditLogService.List(c.Request.Context(), c.Request.URL.Query(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, logs)
+}
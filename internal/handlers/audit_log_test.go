@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/audit"
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
+)
+
+func TestCommissionRuleChangeIsAudited(t *testing.T) {
+	api := setup(t, &config.Config{}, audit.New())
+
+	commission := handlers.NewCommissionHandler(handler.NewHandler(api.logger), service.NewCommissionService(api.container))
+	admin := api.router.Group("/admin", middleware.NewAuditMiddleware(service.NewAuditLogService(api.container)))
+	admin.POST("/commission/rules", commission.CreateRule)
+	admin.POST("/commission/rules/:id/expire", commission.ExpireRule)
+
+	rec := api.do(http.MethodPost, "/admin/commission/rules", map[string]any{"category": "audit_test", "rate_bps": 900}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+
+	var created struct {
+		Data models.CommissionRule `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("could not decode the rule: %v", err)
+	}
+
+	requestID := fmt.Sprintf("audit-%d", created.Data.ID)
+	rec = api.do(http.MethodPost, fmt.Sprintf("/admin/commission/rules/%d/expire", created.Data.ID), nil, map[string]string{"X-Request-ID": requestID})
+	expectStatus(t, rec, http.StatusOK)
+
+	var logs []models.AuditLog
+	if err := api.db.Where("request_id = ?", requestID).Find(&logs).Error; err != nil {
+		t.Fatalf("could not read the audit log: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expire request has %d audit entries, want 1: %+v", len(logs), logs)
+	}
+
+	log := logs[0]
+	if log.EntityType != "commission_rules" || log.EntityID != fmt.Sprint(created.Data.ID) || log.Action != changes.ActionUpdated {
+		t.Errorf("audit entry = %s %s %s, want an update of commission_rules %d", log.Action, log.EntityType, log.EntityID, created.Data.ID)
+	}
+
+	var diff map[string]audit.Change
+	if err := json.Unmarshal([]byte(log.Changes), &diff); err != nil {
+		t.Fatalf("could not decode the changes %q: %v", log.Changes, err)
+	}
+	if change, ok := diff["effective_to"]; !ok || change.After == nil {
+		t.Errorf("changes = %s, want effective_to set", log.Changes)
+	}
+}
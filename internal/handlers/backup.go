@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type BackupHandler struct {
+	*handler.Handler
+	backupService service.BackupService
+}
+
+func NewBackupHandler(handler *handler.Handler, backupService service.BackupService) *BackupHandler {
+	return &BackupHandler{
+		Handler:       handler,
+		backupService: backupService,
+	}
+}
+
+// @Summary		Run backup
+// @Description	Exports the critical tables to an encrypted archive in object storage
+// @Tags			admin
+// @Produce		json
+// @Success		201	{object}	models.BackupRecord
+// @Failure		400	{object}	responses.ErrorResponse
+// @Router			/admin/backups [post]
+// @Security		ApiKeyAuth
+func (h *BackupHandler) Run(c *gin.Context) {
+	record, err := h.backupService.Run(c.Request.Context(), "admin")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessCreated(c, record)
+}
+
+// @Summary		List backups
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	models.BackupRecord
+// @Router			/admin/backups [get]
+// @Security		ApiKeyAuth
+func (h *BackupHandler) List(c *gin.Context) {
+	records, err := h.backupService.List(c.Request.Context(), 50)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, records)
+}
+
+// @Summary		Get backup
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Backup ID"
+// @Success		200	{object}	models.BackupRecord
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/backups/{id} [get]
+// @Security		ApiKeyAuth
+func (h *BackupHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid backup id")
+		return
+	}
+
+	record, err := h.backupService.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "backup"))
+		return
+	}
+
+	responses.SuccessOK(c, record)
+}
+
+// @Summary		Diff backup
+// @Description	Dry-run restore: compares the backup with the current database without writing. Use the CLI to apply a restore.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Backup ID"
+// @Success		200	{object}	dto.RestoreReport
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/backups/{id}/diff [get]
+// @Security		ApiKeyAuth
+func (h *BackupHandler) Diff(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid backup id")
+		return
+	}
+
+	report, err := h.backupService.Restore(c.Request.Context(), id, true)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "backup"))
+		return
+	}
+
+	responses.SuccessOK(c, report)
+}
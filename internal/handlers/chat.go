@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/services/chat"
+	"gorm.io/gorm"
+)
+
+type ChatHandler struct {
+	*handler.Handler
+	chatAlertService service.ChatAlertService
+}
+
+func NewChatHandler(handler *handler.Handler, chatAlertService service.ChatAlertService) *ChatHandler {
+	return &ChatHandler{
+		Handler:          handler,
+		chatAlertService: chatAlertService,
+	}
+}
+
+// @Summary		List my chat channels
+// @Description	Returns the Slack and Discord channels the authenticated user receives alerts in
+// @Tags			chat
+// @Produce		json
+// @Success		200	{array}	models.ChatChannel
+// @Router			/api/v1/chat/channels [get]
+// @Security		BearerAuth
+func (h *ChatHandler) ListMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+	h.list(c, &userID)
+}
+
+// @Summary		Create chat channel
+// @Description	Registers a Slack or Discord incoming webhook for the authenticated user
+// @Tags			chat
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.CreateChatChannelRequest	true	"Channel"
+// @Success		201		{object}	models.ChatChannel
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/api/v1/chat/channels [post]
+// @Security		BearerAuth
+func (h *ChatHandler) CreateMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+	h.create(c, &userID)
+}
+
+// @Summary		Delete chat channel
+// @Tags			chat
+// @Produce		json
+// @Param			id	path	int	true	"Channel ID"
+// @Success		200
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/api/v1/chat/channels/{id} [delete]
+// @Security		BearerAuth
+func (h *ChatHandler) DeleteMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+	h.delete(c, &userID)
+}
+
+// @Summary		Send test message
+// @Description	Posts a test message to the channel and returns the webhook error if it fails
+// @Tags			chat
+// @Produce		json
+// @Param			id	path	int	true	"Channel ID"
+// @Success		200
+// @Failure		400	{object}	responses.ErrorResponse
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/api/v1/chat/channels/{id}/test [post]
+// @Security		BearerAuth
+func (h *ChatHandler) TestMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+	h.test(c, &userID)
+}
+
+// @Summary		List operations chat channels
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	models.ChatChannel
+// @Router			/admin/chat/channels [get]
+// @Security		ApiKeyAuth
+func (h *ChatHandler) ListOperations(c *gin.Context) {
+	h.list(c, nil)
+}
+
+// @Summary		Create operations chat channel
+// @Description	Registers a Slack or Discord incoming webhook for operational alerts
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.CreateChatChannelRequest	true	"Channel"
+// @Success		201		{object}	models.ChatChannel
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/chat/channels [post]
+// @Security		ApiKeyAuth
+func (h *ChatHandler) CreateOperations(c *gin.Context) {
+	h.create(c, nil)
+}
+
+// @Summary		Delete operations chat channel
+// @Tags			admin
+// @Produce		json
+// @Param			id	path	int	true	"Channel ID"
+// @Success		200
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/chat/channels/{id} [delete]
+// @Security		ApiKeyAuth
+func (h *ChatHandler) DeleteOperations(c *gin.Context) {
+	h.delete(c, nil)
+}
+
+// @Summary		Send test message to operations channel
+// @Tags			admin
+// @Produce		json
+// @Param			id	path	int	true	"Channel ID"
+// @Success		200
+// @Failure		400	{object}	responses.ErrorResponse
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/chat/channels/{id}/test [post]
+// @Security		ApiKeyAuth
+func (h *ChatHandler) TestOperations(c *gin.Context) {
+	h.test(c, nil)
+}
+
+func (h *ChatHandler) list(c *gin.Context, userID *uint) {
+	channels, err := h.chatAlertService.ListChannels(c.Request.Context(), userID)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, channels)
+}
+
+func (h *ChatHandler) create(c *gin.Context, userID *uint) {
+	var payload dto.CreateChatChannelRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	channel, err := h.chatAlertService.CreateChannel(c.Request.Context(), userID, &payload)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "chat channel"))
+		return
+	}
+
+	responses.SuccessCreated(c, channel)
+}
+
+func (h *ChatHandler) delete(c *gin.Context, userID *uint) {
+	channelID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid channel id")
+		return
+	}
+
+	if err := h.chatAlertService.DeleteChannel(c.Request.Context(), userID, channelID); err != nil {
+		c.Error(apperrors.NotFoundIf(err, "chat channel"))
+		return
+	}
+
+	responses.SuccessDeleted(c)
+}
+
+func (h *ChatHandler) test(c *gin.Context, userID *uint) {
+	channelID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid channel id")
+		return
+	}
+
+	err := h.chatAlertService.TestChannel(c.Request.Context(), userID, channelID)
+	switch {
+	case err == nil:
+		responses.SuccessOK(c, "test message sent")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.ErrorNotFound(c, "chat channel")
+	case errors.Is(err, chat.ErrRateLimited):
+		responses.ErrorTooManyRequests(c, err.Error())
+	default:
+		// The webhook rejected the message, the channel is misconfigured
+		responses.ErrorBadRequest(c, err.Error())
+	}
+}
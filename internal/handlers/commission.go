@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/money"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type CommissionHandler struct {
+	*handler.Handler
+	commissionService service.CommissionService
+}
+
+func NewCommissionHandler(handler *handler.Handler, commissionService service.CommissionService) *CommissionHandler {
+	return &CommissionHandler{
+		Handler:           handler,
+		commissionService: commissionService,
+	}
+}
+
+// @Summary		Create commission rule
+// @Description	Creates a new commission rule version, closing the previous version of the same scope
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.CreateCommissionRuleRequest	true	"Commission rule"
+// @Success		201		{object}	models.CommissionRule
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/commission/rules [post]
+// @Security		ApiKeyAuth
+func (h *CommissionHandler) CreateRule(c *gin.Context) {
+	var payload dto.CreateCommissionRuleRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	rule, err := h.commissionService.CreateRule(c.Request.Context(), &payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessCreated(c, rule)
+}
+
+// @Summary		List commission rules
+// @Tags			admin
+// @Produce		json
+// @Param			active	query		bool	false	"Only rules in effect now"
+// @Success		200		{array}		models.CommissionRule
+// @Router			/admin/commission/rules [get]
+// @Security		ApiKeyAuth
+func (h *CommissionHandler) ListRules(c *gin.Context) {
+	activeOnly := c.Query("active") == "true"
+
+	rules, err := h.commissionService.ListRules(c.Request.Context(), activeOnly)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, rules)
+}
+
+// @Summary		Expire commission rule
+// @Description	Ends a commission rule now. Past quotes keep resolving to it.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Rule ID"
+// @Success		200	{object}	responses.SuccessResponse
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/commission/rules/{id}/expire [post]
+// @Security		ApiKeyAuth
+func (h *CommissionHandler) ExpireRule(c *gin.Context) {
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		responses.ErrorBadRequest(c, "invalid rule id")
+		return
+	}
+
+	if err := h.commissionService.ExpireRule(c.Request.Context(), uint(ruleID)); err != nil {
+		c.Error(apperrors.NotFoundIf(err, "commission rule"))
+		return
+	}
+
+	responses.SuccessUpdated(c, nil)
+}
+
+// @Summary		Quote commission
+// @Description	Resolves the commission and net earnings of a sale
+// @Tags			admin
+// @Produce		json
+// @Param			seller_id			query		int		true	"Seller ID"
+// @Param			category			query		string	false	"Category"
+// @Param			completed_orders	query		int		false	"Seller completed orders"
+// @Param			amount				query		int		true	"Gross amount in minor units"
+// @Param			currency			query		string	true	"ISO 4217 currency code"
+// @Param			at					query		string	false	"RFC3339 time, defaults to now"
+// @Success		200					{object}	dto.CommissionQuote
+// @Failure		400					{object}	responses.ErrorResponse
+// @Router			/admin/commission/quote [get]
+// @Security		ApiKeyAuth
+func (h *CommissionHandler) Quote(c *gin.Context) {
+	sellerID, err := strconv.ParseUint(c.Query("seller_id"), 10, 64)
+	if err != nil {
+		responses.ErrorBadRequest(c, "invalid seller_id")
+		return
+	}
+
+	amount, err := strconv.ParseInt(c.Query("amount"), 10, 64)
+	if err != nil || amount < 0 {
+		responses.ErrorBadRequest(c, "invalid amount")
+		return
+	}
+
+	currency, err := money.ParseCurrency(c.Query("currency"))
+	if err != nil {
+		responses.ErrorBadRequest(c, "invalid currency")
+		return
+	}
+
+	completedOrders := 0
+	if value := c.Query("completed_orders"); value != "" {
+		completedOrders, err = strconv.Atoi(value)
+		if err != nil || completedOrders < 0 {
+			responses.ErrorBadRequest(c, "invalid completed_orders")
+			return
+		}
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			responses.ErrorBadRequest(c, "invalid at, expected RFC3339")
+			return
+		}
+	}
+
+	quote, err := h.commissionService.Quote(c.Request.Context(), uint(sellerID), c.Query("category"), completedOrders, money.New(amount, currency), at)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, quote)
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+// getUserID returns the authenticated user id set by the auth middleware
+func getUserID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+
+	userID, ok := value.(uint)
+	if !ok || userID == 0 {
+		return 0, false
+	}
+
+	return userID, true
+}
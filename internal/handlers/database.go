@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"gorm.io/gorm"
+)
+
+type DatabaseHandler struct {
+	*handler.Handler
+	databases map[string]*gorm.DB
+}
+
+// NewDatabaseHandler reports on the given databases by name, e.g. primary
+// and replica
+func NewDatabaseHandler(handler *handler.Handler, databases map[string]*gorm.DB) *DatabaseHandler {
+	return &DatabaseHandler{
+		Handler:   handler,
+		databases: databases,
+	}
+}
+
+// @Summary		Database pool statistics
+// @Description	Returns the connection pool statistics of this instance for the primary and, when configured, the replica. A growing wait_count with in_use at max_open_connections means requests queue for connections.
+// @Tags			internal
+// @Produce		json
+// @Success		200	{object}	map[string]dto.DatabasePoolStats
+// @Router			/internal/db/stats [get]
+// @Security		ApiKeyAuth
+func (h *DatabaseHandler) Stats(c *gin.Context) {
+	stats := make(map[string]dto.DatabasePoolStats, len(h.databases))
+	for name, db := range h.databases {
+		sqlDB, err := db.DB()
+		if err != nil {
+			responses.ErrorInternalServer(c, err.Error())
+			return
+		}
+
+		s := sqlDB.Stats()
+		stats[name] = dto.DatabasePoolStats{
+			MaxOpenConnections: s.MaxOpenConnections,
+			OpenConnections:    s.OpenConnections,
+			InUse:              s.InUse,
+			Idle:               s.Idle,
+			WaitCount:          s.WaitCount,
+			WaitDurationMs:     s.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      s.MaxIdleClosed,
+			MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  s.MaxLifetimeClosed,
+		}
+	}
+
+	responses.SuccessOK(c, stats)
+}
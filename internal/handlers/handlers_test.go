@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/internal/store"
+	"github.com/imlargo/go-api/internal/testfactory"
+	"github.com/imlargo/go-api/pkg/medusa/core/logger"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	medusaservice "github.com/imlargo/go-api/pkg/medusa/core/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
+	"gorm.io/gorm"
+)
+
+// testAPI is the database, service container and router of a handler test,
+// built the way cmd/api builds them
+type testAPI struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	container *service.Service
+	router    *gin.Engine
+}
+
+// setup connects to the scratch database with plugins installed, see
+// testfactory.OpenDB. Routes are registered by the test.
+func setup(t *testing.T, cfg *config.Config, plugins ...gorm.Plugin) *testAPI {
+	t.Helper()
+	return newTestAPI(t, testfactory.OpenDB(t), cfg, plugins...)
+}
+
+func newTestAPI(t *testing.T, db *gorm.DB, cfg *config.Config, plugins ...gorm.Plugin) *testAPI {
+	t.Helper()
+
+	for _, plugin := range plugins {
+		if err := db.Use(plugin); err != nil {
+			t.Fatalf("could not install %s: %v", plugin.Name(), err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger()
+
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.ErrorHandlerMiddleware(log))
+
+	return &testAPI{
+		db:        db,
+		logger:    log,
+		container: service.NewService(*medusaservice.NewService(log), store.NewStore(medusarepo.NewStore(db, log)), cfg),
+		router:    router,
+	}
+}
+
+// provisionTenant creates a tenant schema deleted when the test ends and
+// returns its slug
+func (api *testAPI) provisionTenant(t *testing.T) string {
+	t.Helper()
+
+	tenants := service.NewTenantService(api.container, api.db)
+	ctx := tenancy.Public(context.Background())
+
+	// Unique, the scratch database is shared by the test packages
+	slug := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := tenants.Provision(ctx, slug); err != nil {
+		t.Fatalf("could not provision tenant %s: %v", slug, err)
+	}
+	t.Cleanup(func() {
+		tenants.Deprovision(ctx, slug)
+	})
+	return slug
+}
+
+// do serves a request with a JSON body, or none when body is nil
+func (api *testAPI) do(method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body.String())
+	}
+}
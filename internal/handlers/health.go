@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/health"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type HealthHandler struct {
+	*handler.Handler
+	registry      *health.Registry
+	mockExternals bool
+}
+
+func NewHealthHandler(handler *handler.Handler, registry *health.Registry, mockExternals bool) *HealthHandler {
+	return &HealthHandler{
+		Handler:       handler,
+		registry:      registry,
+		mockExternals: mockExternals,
+	}
+}
+
+// @Summary		Liveness probe
+// @Description	Reports the process is running. Dependencies are not checked, a database outage must not restart the pods.
+// @Tags			health
+// @Produce		json
+// @Success		200	{object}	health.Report
+// @Router			/health/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	responses.SuccessOK(c, &health.Report{
+		Status:        health.StatusUp,
+		Checks:        map[string]health.Result{},
+		MockExternals: h.mockExternals,
+	})
+}
+
+// @Summary		Readiness probe
+// @Description	Probes every dependency. Returns 503 when a required one is down, optional ones only degrade the status.
+// @Tags			health
+// @Produce		json
+// @Success		200	{object}	health.Report
+// @Failure		503	{object}	responses.ErrorResponse
+// @Router			/health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context())
+	report.MockExternals = h.mockExternals
+	if report.Status == health.StatusDown {
+		responses.WriteErrorResponse(c, http.StatusServiceUnavailable, responses.ErrServiceUnavailable, "a required dependency is down", report)
+		return
+	}
+
+	responses.SuccessOK(c, report)
+}
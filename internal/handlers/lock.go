@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/services/lock"
+)
+
+type LockHandler struct {
+	*handler.Handler
+	lockManager lock.Manager
+}
+
+func NewLockHandler(handler *handler.Handler, lockManager lock.Manager) *LockHandler {
+	return &LockHandler{
+		Handler:     handler,
+		lockManager: lockManager,
+	}
+}
+
+// @Summary		List locks
+// @Description	Returns every held distributed lock with its holder and remaining lease
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	lock.LockInfo
+// @Router			/admin/locks [get]
+// @Security		ApiKeyAuth
+func (h *LockHandler) List(c *gin.Context) {
+	locks, err := h.lockManager.List(c.Request.Context())
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, locks)
+}
+
+// @Summary		Lock contention stats
+// @Description	Returns acquire, contention, lost lease and force release counters per lock kind since startup
+// @Tags			admin
+// @Produce		json
+// @Success		200	{object}	map[string]lock.Stats
+// @Router			/admin/locks/stats [get]
+// @Security		ApiKeyAuth
+func (h *LockHandler) GetStats(c *gin.Context) {
+	responses.SuccessOK(c, h.lockManager.Stats())
+}
+
+// @Summary		Get lock
+// @Tags			admin
+// @Produce		json
+// @Param			name	path		string	true	"Lock name"
+// @Success		200		{object}	lock.LockInfo
+// @Failure		404		{object}	responses.ErrorResponse
+// @Router			/admin/locks/{name} [get]
+// @Security		ApiKeyAuth
+func (h *LockHandler) Get(c *gin.Context) {
+	info, err := h.lockManager.Inspect(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, info)
+}
+
+// @Summary		Force-release lock
+// @Description	Deletes the lock regardless of its holder. The holder loses its lease on the next renewal.
+// @Tags			admin
+// @Produce		json
+// @Param			name	path	string	true	"Lock name"
+// @Success		200
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/locks/{name} [delete]
+// @Security		ApiKeyAuth
+func (h *LockHandler) ForceRelease(c *gin.Context) {
+	released, err := h.lockManager.ForceRelease(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	if !released {
+		responses.ErrorNotFound(c, "lock")
+		return
+	}
+
+	responses.SuccessDeleted(c)
+}
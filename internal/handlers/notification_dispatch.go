@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/services/sse"
+)
+
+type NotificationDispatchHandler struct {
+	*handler.Handler
+	sseService sse.SSEManager
+	dispatcher sse.Dispatcher
+}
+
+func NewNotificationDispatchHandler(handler *handler.Handler, sseService sse.SSEManager, dispatcher sse.Dispatcher) *NotificationDispatchHandler {
+	return &NotificationDispatchHandler{
+		Handler:    handler,
+		sseService: sseService,
+		dispatcher: dispatcher,
+	}
+}
+
+// @Summary		Bulk notification dispatch
+// @Description	Sends a templated notification to a list of users or to every connected user. Sending happens in the background; poll the returned dispatch for progress.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.BulkNotificationRequest	true	"Audience and message"
+// @Success		201		{object}	sse.Dispatch
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/notifications/bulk [post]
+// @Security		ApiKeyAuth
+func (h *NotificationDispatchHandler) Dispatch(c *gin.Context) {
+	var payload dto.BulkNotificationRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	var recipients []uint
+	switch payload.Audience {
+	case dto.NotificationAudienceUsers:
+		recipients = payload.UserIDs
+	case dto.NotificationAudienceConnected:
+		recipients = h.sseService.ConnectedUsers()
+	}
+
+	if payload.Audience == dto.NotificationAudienceUsers && len(recipients) == 0 {
+		responses.ErrorBadRequest(c, "user_ids is required for the users audience")
+		return
+	}
+
+	event := payload.Event
+	if event == "" {
+		event = defaultNotificationEvent
+	}
+
+	dispatch, err := h.dispatcher.Dispatch(c.Request.Context(), recipients, &sse.BulkMessage{
+		Event:    event,
+		Data:     payload.Data,
+		Vars:     payload.Vars,
+		Defaults: payload.Defaults,
+	})
+	if err != nil {
+		responses.ErrorBadRequest(c, fmt.Sprintf("error dispatching notification: %v", err))
+		return
+	}
+
+	responses.SuccessCreated(c, dispatch)
+}
+
+// @Summary		Get bulk dispatch
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		string	true	"Dispatch ID"
+// @Success		200	{object}	sse.Dispatch
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/notifications/bulk/{id} [get]
+// @Security		ApiKeyAuth
+func (h *NotificationDispatchHandler) Get(c *gin.Context) {
+	dispatch, err := h.dispatcher.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, dispatch)
+}
+
+// @Summary		Bulk dispatch delivery results
+// @Description	Returns the per-recipient outcome of the batches sent so far
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		string	true	"Dispatch ID"
+// @Success		200	{array}		sse.DeliveryResult
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/notifications/bulk/{id}/results [get]
+// @Security		ApiKeyAuth
+func (h *NotificationDispatchHandler) GetResults(c *gin.Context) {
+	results, err := h.dispatcher.Results(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, results)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+// oauthStateCookie ties a sign in to the browser that started it
+const oauthStateCookie = "oauth_state"
+
+type OAuthHandler struct {
+	*handler.Handler
+	oauthService       service.OAuthService
+	successRedirectURL string
+	secureCookies      bool
+}
+
+// NewOAuthHandler sets the state cookie with the Secure flag when
+// secureCookies is true, which it must be whenever the callback is served
+// over https
+func NewOAuthHandler(handler *handler.Handler, oauthService service.OAuthService, successRedirectURL string, secureCookies bool) *OAuthHandler {
+	return &OAuthHandler{
+		Handler:            handler,
+		oauthService:       oauthService,
+		successRedirectURL: successRedirectURL,
+		secureCookies:      secureCookies,
+	}
+}
+
+// @Summary		Start social sign in
+// @Description	Redirects to the consent page of the provider, google or github. The sign in must be finished within 10 minutes, in the same browser: it sets an oauth_state cookie the callback requires.
+// @Tags			auth
+// @Param			provider	path	string	true	"google or github"
+// @Success		302
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/auth/oauth/{provider} [get]
+func (h *OAuthHandler) Start(c *gin.Context) {
+	authURL, state, err := h.oauthService.AuthURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Lax still sends the cookie on the top level redirect back from the
+	// provider
+	h.setStateCookie(c, state, int(service.OAuthStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary		Finish social sign in
+// @Description	Called by the provider after consent. Signs in the user linked to the account, linking or creating one by verified email on the first sign in, and returns their tokens. When a success redirect is configured the browser is sent there with the tokens in the URL fragment instead.
+// @Tags			auth
+// @Produce		json
+// @Param			provider	path		string	true	"google or github"
+// @Param			code		query		string	true	"Authorization code"
+// @Param			state		query		string	true	"State issued by the start endpoint"
+// @Success		200			{object}	dto.TokenPairResponse
+// @Success		302
+// @Failure		401			{object}	responses.ErrorResponse
+// @Failure		403			{object}	responses.ErrorResponse
+// @Router			/auth/oauth/{provider}/callback [get]
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	// Users who decline consent come back with an error instead of a code
+	if reason := c.Query("error"); reason != "" {
+		responses.ErrorUnauthorized(c, "sign in was not completed: "+reason)
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		responses.ErrorBadRequest(c, "code and state are required")
+		return
+	}
+
+	browserState, _ := c.Cookie(oauthStateCookie)
+	h.setStateCookie(c, "", -1)
+
+	tokens, err := h.oauthService.Callback(c.Request.Context(), c.Param("provider"), state, browserState, code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if h.successRedirectURL == "" {
+		responses.SuccessOK(c, tokens)
+		return
+	}
+
+	// The fragment never reaches servers or their logs
+	fragment := url.Values{
+		"access_token":       {tokens.AccessToken},
+		"refresh_token":      {tokens.RefreshToken},
+		"token_type":         {tokens.TokenType},
+		"expires_at":         {strconv.FormatInt(tokens.ExpiresAt.Unix(), 10)},
+		"refresh_expires_at": {strconv.FormatInt(tokens.RefreshExpiresAt.Unix(), 10)},
+	}
+	c.Redirect(http.StatusFound, h.successRedirectURL+"#"+fragment.Encode())
+}
+
+func (h *OAuthHandler) setStateCookie(c *gin.Context, state string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/auth/oauth",
+		MaxAge:   maxAge,
+		Secure:   h.secureCookies,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/services/degrade"
+)
+
+type ProviderHandler struct {
+	*handler.Handler
+	guard *degrade.Guard
+}
+
+func NewProviderHandler(handler *handler.Handler, guard *degrade.Guard) *ProviderHandler {
+	return &ProviderHandler{
+		Handler: handler,
+		guard:   guard,
+	}
+}
+
+// @Summary		Get external provider health
+// @Description	Returns the circuit state of every external provider so clients can show a degraded mode banner
+// @Tags			providers
+// @Produce		json
+// @Success		200	{object}	map[string]degrade.ProviderHealth
+// @Router			/api/v1/providers/health [get]
+// @Security		BearerAuth
+func (h *ProviderHandler) GetHealth(c *gin.Context) {
+	responses.SuccessOK(c, h.guard.Health())
+}
@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/querytag"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The query tag is built before the statement runs, so a dry run database
+// is enough to see it and the test needs no Postgres
+func TestHandlerQueriesCarryRequestID(t *testing.T) {
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries []string
+	err = db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		queries = append(queries, db.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := newTestAPI(t, db, &config.Config{}, querytag.New("api"))
+	commission := handlers.NewCommissionHandler(handler.NewHandler(api.logger), service.NewCommissionService(api.container))
+	api.router.GET("/admin/commission/rules", commission.ListRules)
+
+	rec := api.do(http.MethodGet, "/admin/commission/rules", nil, map[string]string{"X-Request-ID": "tag-test-1"})
+	expectStatus(t, rec, http.StatusOK)
+
+	if len(queries) == 0 {
+		t.Fatal("the handler ran no query")
+	}
+	for _, query := range queries {
+		if !strings.Contains(query, "req=tag-test-1") {
+			t.Errorf("query is not tagged with the request id: %s", query)
+		}
+	}
+}
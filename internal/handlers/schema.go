@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/database"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type SchemaHandler struct {
+	*handler.Handler
+	checkSchema   func() (*database.SchemaStatus, error)
+	policy        database.SchemaPolicy
+	readOnly      bool
+	mockExternals bool
+}
+
+func NewSchemaHandler(handler *handler.Handler, checkSchema func() (*database.SchemaStatus, error), policy database.SchemaPolicy, readOnly bool, mockExternals bool) *SchemaHandler {
+	return &SchemaHandler{
+		Handler:       handler,
+		checkSchema:   checkSchema,
+		policy:        policy,
+		readOnly:      readOnly,
+		mockExternals: mockExternals,
+	}
+}
+
+// @Summary		Schema version
+// @Description	Returns the applied schema version against the one this build expects, whether the API started in read-only mode and whether external services are mocked
+// @Tags			admin
+// @Produce		json
+// @Success		200	{object}	dto.SchemaStatusResponse
+// @Router			/admin/schema [get]
+// @Security		ApiKeyAuth
+func (h *SchemaHandler) Get(c *gin.Context) {
+	status, err := h.checkSchema()
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, dto.SchemaStatusResponse{
+		SchemaStatus:  *status,
+		Policy:        string(h.policy),
+		ReadOnly:      h.readOnly,
+		MockExternals: h.mockExternals,
+	})
+}
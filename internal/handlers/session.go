@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type SessionHandler struct {
+	*handler.Handler
+	sessionService service.SessionService
+}
+
+func NewSessionHandler(handler *handler.Handler, sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		Handler:        handler,
+		sessionService: sessionService,
+	}
+}
+
+// @Summary		Revoke user sessions
+// @Description	Bumps the claims version of the user so every token issued before is rejected, revokes their refresh tokens and sends a session_refresh event to their connected clients
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int							true	"User ID"
+// @Param			payload	body		dto.RevokeSessionsRequest	true	"Revocation reason"
+// @Success		200		{object}	dto.RevokeSessionsResponse
+// @Failure		404		{object}	responses.ErrorResponse
+// @Router			/admin/users/{id}/sessions/revoke [post]
+// @Security		ApiKeyAuth
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid user id")
+		return
+	}
+
+	var payload dto.RevokeSessionsRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	version, err := h.sessionService.RevokeSessions(c.Request.Context(), userID, payload.Reason)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "user"))
+		return
+	}
+
+	responses.SuccessOK(c, dto.RevokeSessionsResponse{
+		UserID:        userID,
+		ClaimsVersion: version,
+	})
+}
+
+// @Summary		Refresh session
+// @Description	Exchanges a refresh token for a new access token and refresh token. Each refresh token works once; presenting a used one revokes every token of its session.
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.RefreshTokenRequest	true	"Refresh token"
+// @Success		200		{object}	dto.TokenPairResponse
+// @Failure		401		{object}	responses.ErrorResponse
+// @Router			/auth/refresh [post]
+func (h *SessionHandler) Refresh(c *gin.Context) {
+	var payload dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(c.Request.Context(), payload.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, tokens)
+}
+
+// @Summary		Log out
+// @Description	Revokes the refresh token and every token rotated from the same sign in. Access tokens already issued stay valid until they expire.
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			payload	body	dto.RefreshTokenRequest	true	"Refresh token"
+// @Success		200
+// @Failure		401	{object}	responses.ErrorResponse
+// @Router			/auth/logout [post]
+func (h *SessionHandler) Logout(c *gin.Context) {
+	var payload dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	if err := h.sessionService.Logout(c.Request.Context(), payload.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, gin.H{"logged_out": true})
+}
@@ -1,30 +1,55 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
 	"github.com/imlargo/go-api/pkg/medusa/core/handler"
 	"github.com/imlargo/go-api/pkg/medusa/core/responses"
 	"github.com/imlargo/go-api/pkg/medusa/services/sse"
 )
 
-type Handler struct {
+const defaultNotificationEvent = "notification"
+
+type SSEHandler struct {
 	*handler.Handler
 	sseService sse.SSEManager
+	changes    *changes.Registry
 }
 
-func NewSSEHandler(handler *handler.Handler) *Handler {
-	sse := sse.NewSSEManager()
-	return &Handler{
+func NewSSEHandler(handler *handler.Handler, sseService sse.SSEManager, changes *changes.Registry) *SSEHandler {
+	return &SSEHandler{
 		Handler:    handler,
-		sseService: sse,
+		sseService: sseService,
+		changes:    changes,
 	}
 }
 
-func (h *Handler) Listen(c *gin.Context) {
+// Listen streams the notifications of a user. Clients reconnecting with the
+// Last-Event-ID header (or last_event_id query param) first receive the
+// events they missed. The entities query param limits the change events
+// to the given entity types.
+//
+// @Summary		Listen to notifications
+// @Description	Opens a server-sent events stream with the notifications and change events of the user
+// @Tags			sse
+// @Produce		text/event-stream
+// @Param			user_id			query	int		true	"User ID"
+// @Param			device_id		query	string	true	"Device ID, one connection per device"
+// @Param			last_event_id	query	string	false	"Resume after this event, same as the Last-Event-ID header"
+// @Param			entities		query	string	false	"Comma separated entity types of the change events to receive"
+// @Success		200
+// @Failure		400	{object}	responses.ErrorResponse
+// @Failure		429	{object}	responses.ErrorResponse
+// @Router			/sse/listen [get]
+func (h *SSEHandler) Listen(c *gin.Context) {
 	userIDStr := c.Query("user_id")
 	deviceID := c.Query("device_id")
 
@@ -39,43 +64,89 @@ func (h *Handler) Listen(c *gin.Context) {
 		return
 	}
 
-	// SSE headers
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("Access-Control-Allow-Headers", "Cache-Control")
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
 
-	client, err := h.sseService.Subscribe(c.Request.Context(), uint(userID), deviceID)
+	var entities []string
+	if value := c.Query("entities"); value != "" {
+		for _, entity := range strings.Split(value, ",") {
+			entity = strings.TrimSpace(entity)
+			if entity == "" {
+				continue
+			}
+			if !h.changes.Has(entity) {
+				responses.ErrorBadRequest(c, fmt.Sprintf("unknown entity %q", entity))
+				return
+			}
+			entities = append(entities, entity)
+		}
+	}
+
+	client, err := h.sseService.Subscribe(c.Request.Context(), uint(userID), deviceID, lastEventID, entities)
+	var limitErr *sse.LimitError
+	if errors.As(err, &limitErr) {
+		responses.WriteErrorResponse(c, http.StatusTooManyRequests, responses.ErrToManyRequests, limitErr.Error(), limitErr)
+		return
+	}
 	if err != nil {
 		responses.ErrorBadRequest(c, fmt.Sprintf("error subscribing: %v", err))
 		return
 	}
 
-	c.SSEvent("connected", gin.H{
+	// SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Access-Control-Allow-Headers", "Cache-Control, Last-Event-ID")
+
+	connected := &sse.Message{Event: "connected", Data: gin.H{
 		"user_id":   userID,
 		"device_id": deviceID,
 		"timestamp": time.Now().Unix(),
-	})
+	}}
+	if err := connected.Write(c.Writer); err != nil {
+		return
+	}
+
+	for _, missed := range client.Replay() {
+		if !client.Accept(missed) {
+			continue
+		}
+		if err := missed.Write(c.Writer); err != nil {
+			return
+		}
+	}
 	c.Writer.Flush()
 
-	pingTicker := time.NewTicker(30 * time.Second)
-	defer pingTicker.Stop()
+	heartbeat := time.NewTicker(h.sseService.Config().HeartbeatInterval)
+	defer heartbeat.Stop()
 
 	for {
 		select {
 		case notification, ok := <-client.GetChannel():
 			if !ok {
-				return // Closed channel
+				return // Closed channel, the client reconnects and resumes
 			}
 
 			client.UpdateLastSeen()
+			if !client.Accept(notification) {
+				continue
+			}
 
-			c.SSEvent("notification", notification)
+			if err := notification.Write(c.Writer); err != nil {
+				return
+			}
 			c.Writer.Flush()
 
-		case <-pingTicker.C:
-			c.SSEvent("ping", gin.H{"timestamp": time.Now().Unix()})
+		case <-heartbeat.C:
+			client.UpdateLastSeen()
+			if err := sse.WriteComment(c.Writer, "heartbeat"); err != nil {
+				return
+			}
 			c.Writer.Flush()
 
 		case <-c.Request.Context().Done():
@@ -87,30 +158,42 @@ func (h *Handler) Listen(c *gin.Context) {
 	}
 }
 
-func (h *Handler) Publish(c *gin.Context) {
+// @Summary		Publish notification
+// @Description	Sends an event to every connection of the user
+// @Tags			sse
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.SendNotificationRequestPayload	true	"Notification"
+// @Success		200		{object}	responses.SuccessResponse
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/sse/publish [post]
+func (h *SSEHandler) Publish(c *gin.Context) {
 	var payload dto.SendNotificationRequestPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		responses.ErrorBindJson(c, err)
 		return
 	}
 
-	if payload.UserID == 0 {
-		responses.ErrorBadRequest(c, "user_id is required")
-		return
+	event := payload.Event
+	if event == "" {
+		event = defaultNotificationEvent
 	}
 
-	if payload.Notification.ID == 0 {
-		responses.ErrorBadRequest(c, "notification_id is required")
-		return
-	}
-
-	err := h.notificationService.DispatchSSE(&payload.Notification)
+	err := h.sseService.Send(payload.UserID, &sse.Message{Event: event, Data: payload.Data})
 	if err != nil {
-		responses.ErrorInternalServerWithMessage(c, fmt.Sprintf("error sending notification: %v", err))
+		responses.ErrorInternalServerWithMessage(c, fmt.Sprintf("error sending notification: %v", err), nil)
 		return
 	}
 
-	if !c.IsAborted() {
-		responses.Ok(c, "Notification sent successfully")
-	}
+	responses.SuccessOK(c, "Notification sent successfully")
+}
+
+// @Summary		List change events
+// @Description	Returns the entity types whose changes are streamed and the actions sent for each
+// @Tags			sse
+// @Produce		json
+// @Success		200	{array}	changes.Schema
+// @Router			/sse/events [get]
+func (h *SSEHandler) ListEvents(c *gin.Context) {
+	responses.SuccessOK(c, h.changes.Schemas())
 }
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type StatusHandler struct {
+	*handler.Handler
+	statusService service.StatusService
+}
+
+func NewStatusHandler(handler *handler.Handler, statusService service.StatusService) *StatusHandler {
+	return &StatusHandler{
+		Handler:       handler,
+		statusService: statusService,
+	}
+}
+
+// @Summary		Platform status
+// @Description	Public feed for the status page: the state of each component (operational, degraded_performance, partial_outage or major_outage) from health probes, error rates and active incidents, the incidents of the last 90 days and the daily history of each component. Refreshed at most every 30 seconds.
+// @Tags			status
+// @Produce		json
+// @Success		200	{object}	dto.StatusPageResponse
+// @Router			/status [get]
+func (h *StatusHandler) Get(c *gin.Context) {
+	status, err := h.statusService.GetStatus(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, status)
+}
+
+// @Summary		List incidents
+// @Description	Returns the active incidents and those resolved in the last 90 days, newest first, with their updates
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	models.Incident
+// @Router			/admin/status/incidents [get]
+// @Security		ApiKeyAuth
+func (h *StatusHandler) ListIncidents(c *gin.Context) {
+	incidents, err := h.statusService.ListIncidents(c.Request.Context())
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, incidents)
+}
+
+// @Summary		Create incident
+// @Description	Publishes an incident on the status page. Its impact sets the state of the affected components until it is resolved: minor degrades them, major is a partial outage and critical a major outage.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.CreateIncidentRequest	true	"Incident"
+// @Success		201		{object}	models.Incident
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/status/incidents [post]
+// @Security		ApiKeyAuth
+func (h *StatusHandler) CreateIncident(c *gin.Context) {
+	var payload dto.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	incident, err := h.statusService.CreateIncident(c.Request.Context(), &payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessCreated(c, incident)
+}
+
+// @Summary		Post incident update
+// @Description	Adds a progress message to an incident and moves it to the given status. A resolved incident no longer affects its components and can't be updated.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int								true	"Incident ID"
+// @Param			payload	body		dto.PostIncidentUpdateRequest	true	"Update"
+// @Success		200		{object}	models.Incident
+// @Failure		400		{object}	responses.ErrorResponse
+// @Failure		404		{object}	responses.ErrorResponse
+// @Failure		409		{object}	responses.ErrorResponse
+// @Router			/admin/status/incidents/{id}/updates [post]
+// @Security		ApiKeyAuth
+func (h *StatusHandler) PostIncidentUpdate(c *gin.Context) {
+	incidentID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid incident id")
+		return
+	}
+
+	var payload dto.PostIncidentUpdateRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	incident, err := h.statusService.PostIncidentUpdate(c.Request.Context(), incidentID, &payload)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "incident"))
+		return
+	}
+
+	responses.SuccessOK(c, incident)
+}
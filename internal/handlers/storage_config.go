@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type StorageConfigHandler struct {
+	*handler.Handler
+	storageConfigService service.StorageConfigService
+}
+
+func NewStorageConfigHandler(handler *handler.Handler, storageConfigService service.StorageConfigService) *StorageConfigHandler {
+	return &StorageConfigHandler{
+		Handler:              handler,
+		storageConfigService: storageConfigService,
+	}
+}
+
+// @Summary		Get customer bucket
+// @Description	Returns the customer-provided bucket of a user. Credentials are never returned.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	models.UserStorageConfig
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/users/{id}/storage [get]
+// @Security		ApiKeyAuth
+func (h *StorageConfigHandler) Get(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid user id")
+		return
+	}
+
+	config, err := h.storageConfigService.Get(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "storage config"))
+		return
+	}
+
+	responses.SuccessOK(c, config)
+}
+
+// @Summary		Set customer bucket
+// @Description	Validates the credentials against the bucket and stores them encrypted. New files of the user are routed to this bucket.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int								true	"User ID"
+// @Param			payload	body		dto.SetStorageConfigRequest	true	"Bucket configuration"
+// @Success		200		{object}	models.UserStorageConfig
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/users/{id}/storage [put]
+// @Security		ApiKeyAuth
+func (h *StorageConfigHandler) Set(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid user id")
+		return
+	}
+
+	var payload dto.SetStorageConfigRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	config, err := h.storageConfigService.Set(c.Request.Context(), userID, &payload)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "storage config"))
+		return
+	}
+
+	responses.SuccessUpdated(c, config)
+}
+
+// @Summary		Remove customer bucket
+// @Description	Routes the user's new files back to the platform bucket. Files already in the customer bucket are left in place.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path	int	true	"User ID"
+// @Success		200
+// @Router			/admin/users/{id}/storage [delete]
+// @Security		ApiKeyAuth
+func (h *StorageConfigHandler) Remove(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid user id")
+		return
+	}
+
+	if err := h.storageConfigService.Remove(c.Request.Context(), userID); err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessDeleted(c)
+}
+
+// @Summary		Check customer bucket
+// @Description	Re-validates the stored credentials and records the health status
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"User ID"
+// @Success		200	{object}	models.UserStorageConfig
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/users/{id}/storage/check [post]
+// @Security		ApiKeyAuth
+func (h *StorageConfigHandler) Check(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid user id")
+		return
+	}
+
+	config, err := h.storageConfigService.Check(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "storage config"))
+		return
+	}
+
+	responses.SuccessOK(c, config)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type StorageLifecycleHandler struct {
+	*handler.Handler
+	lifecycleService service.StorageLifecycleService
+}
+
+func NewStorageLifecycleHandler(handler *handler.Handler, lifecycleService service.StorageLifecycleService) *StorageLifecycleHandler {
+	return &StorageLifecycleHandler{
+		Handler:          handler,
+		lifecycleService: lifecycleService,
+	}
+}
+
+// @Summary		Storage consistency stats
+// @Description	Returns the deletion queue counters and the last orphan scan of each source
+// @Tags			admin
+// @Produce		json
+// @Success		200	{object}	dto.StorageConsistencyStats
+// @Router			/admin/storage/consistency [get]
+// @Security		ApiKeyAuth
+func (h *StorageLifecycleHandler) GetStats(c *gin.Context) {
+	stats, err := h.lifecycleService.Stats(c.Request.Context())
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, stats)
+}
+
+// @Summary		Scan for orphaned objects
+// @Description	Compares the bucket objects of a source with the rows referencing them. With enqueue=true the orphans are queued for deletion.
+// @Tags			admin
+// @Produce		json
+// @Param			source	path		string	true	"Storage source, e.g. backups"
+// @Param			enqueue	query		bool	false	"Queue the orphans for deletion"
+// @Success		200		{object}	dto.OrphanScanReport
+// @Failure		404		{object}	responses.ErrorResponse
+// @Router			/admin/storage/orphans/{source}/scan [post]
+// @Security		ApiKeyAuth
+func (h *StorageLifecycleHandler) ScanOrphans(c *gin.Context) {
+	report, err := h.lifecycleService.ScanOrphans(c.Request.Context(), c.Param("source"), c.Query("enqueue") == "true")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, report)
+}
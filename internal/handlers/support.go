@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type SupportHandler struct {
+	*handler.Handler
+	supportService service.SupportService
+}
+
+func NewSupportHandler(handler *handler.Handler, supportService service.SupportService) *SupportHandler {
+	return &SupportHandler{
+		Handler:        handler,
+		supportService: supportService,
+	}
+}
+
+// @Summary		Create support ticket
+// @Description	Opens a ticket with the response times of the user's support tier. Send multipart form data to attach up to 5 files of 10 MB.
+// @Tags			support
+// @Accept			json,mpfd
+// @Produce		json
+// @Param			category	formData	string	true	"billing, technical, account or other"
+// @Param			subject		formData	string	true	"Subject"
+// @Param			description	formData	string	true	"Description"
+// @Param			attachments	formData	file	false	"Attachments"
+// @Success		201			{object}	models.SupportTicket
+// @Failure		400			{object}	responses.ErrorResponse
+// @Router			/api/v1/support/tickets [post]
+// @Security		BearerAuth
+func (h *SupportHandler) CreateMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	var payload dto.CreateSupportTicketRequest
+	if err := c.ShouldBind(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	var attachments []*dto.SupportAttachmentUpload
+	if form, err := c.MultipartForm(); err == nil {
+		for _, header := range form.File["attachments"] {
+			file, err := header.Open()
+			if err != nil {
+				responses.ErrorBadRequest(c, "could not read attachment "+header.Filename)
+				return
+			}
+			defer file.Close()
+
+			attachments = append(attachments, &dto.SupportAttachmentUpload{
+				Name:        header.Filename,
+				ContentType: header.Header.Get("Content-Type"),
+				Size:        header.Size,
+				Content:     file,
+			})
+		}
+	}
+
+	ticket, err := h.supportService.CreateTicket(c.Request.Context(), userID, &payload, attachments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessCreated(c, ticket)
+}
+
+// @Summary		List my support tickets
+// @Tags			support
+// @Produce		json
+// @Success		200	{array}	models.SupportTicket
+// @Router			/api/v1/support/tickets [get]
+// @Security		BearerAuth
+func (h *SupportHandler) ListMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	tickets, err := h.supportService.ListMyTickets(c.Request.Context(), userID)
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, tickets)
+}
+
+// @Summary		Get my support ticket
+// @Description	Returns the ticket with its messages and attachment download links
+// @Tags			support
+// @Produce		json
+// @Param			id	path		int	true	"Ticket ID"
+// @Success		200	{object}	models.SupportTicket
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/api/v1/support/tickets/{id} [get]
+// @Security		BearerAuth
+func (h *SupportHandler) GetMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	ticketID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid ticket id")
+		return
+	}
+
+	ticket, err := h.supportService.GetMyTicket(c.Request.Context(), userID, ticketID)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "support ticket"))
+		return
+	}
+
+	responses.SuccessOK(c, ticket)
+}
+
+// @Summary		Reply to support ticket
+// @Description	Adds a message to the ticket. Tickets waiting on the customer or resolved go back to in progress.
+// @Tags			support
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int								true	"Ticket ID"
+// @Param			payload	body		dto.ReplySupportTicketRequest	true	"Reply"
+// @Success		200		{object}	models.SupportTicket
+// @Failure		404		{object}	responses.ErrorResponse
+// @Failure		409		{object}	responses.ErrorResponse
+// @Router			/api/v1/support/tickets/{id}/messages [post]
+// @Security		BearerAuth
+func (h *SupportHandler) ReplyMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	ticketID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid ticket id")
+		return
+	}
+
+	var payload dto.ReplySupportTicketRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	ticket, err := h.supportService.Reply(c.Request.Context(), userID, ticketID, payload.Body)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "support ticket"))
+		return
+	}
+
+	responses.SuccessUpdated(c, ticket)
+}
+
+// @Summary		Close support ticket
+// @Tags			support
+// @Produce		json
+// @Param			id	path		int	true	"Ticket ID"
+// @Success		200	{object}	models.SupportTicket
+// @Failure		404	{object}	responses.ErrorResponse
+// @Failure		409	{object}	responses.ErrorResponse
+// @Router			/api/v1/support/tickets/{id}/close [post]
+// @Security		BearerAuth
+func (h *SupportHandler) CloseMine(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	ticketID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid ticket id")
+		return
+	}
+
+	ticket, err := h.supportService.Close(c.Request.Context(), userID, ticketID)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "support ticket"))
+		return
+	}
+
+	responses.SuccessUpdated(c, ticket)
+}
+
+// @Summary		Support ticket queue
+// @Description	Lists tickets, closest to their resolution deadline first. Filters take an operator in brackets, e.g. status[in]=open,in_progress or created_at[gte]=2025-01-01.
+// @Tags			admin
+// @Produce		json
+// @Param			status				query	string	false	"Filter by status (eq, ne, in)"
+// @Param			tier				query	string	false	"Filter by support tier (eq, in)"
+// @Param			category			query	string	false	"Filter by category (eq, in)"
+// @Param			assigned_to			query	string	false	"Filter by assignee"
+// @Param			user_id				query	int		false	"Filter by user"
+// @Param			sort				query	string	false	"created_at or resolution_due_at, prefixed with - for descending (default resolution_due_at)"
+// @Param			limit				query	int		false	"Max records (default 50)"
+// @Success		200					{array}		models.SupportTicket
+// @Failure		400					{object}	responses.ErrorResponse
+// @Router			/admin/support/tickets [get]
+// @Security		ApiKeyAuth
+func (h *SupportHandler) ListQueue(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		responses.ErrorBadRequest(c, "limit must be between 1 and 500")
+		return
+	}
+
+	tickets, err := h.supportService.ListQueue(c.Request.Context(), c.Request.URL.Query(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, tickets)
+}
+
+// @Summary		Get support ticket
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Ticket ID"
+// @Success		200	{object}	models.SupportTicket
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/support/tickets/{id} [get]
+// @Security		ApiKeyAuth
+func (h *SupportHandler) Get(c *gin.Context) {
+	ticketID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid ticket id")
+		return
+	}
+
+	ticket, err := h.supportService.GetTicket(c.Request.Context(), ticketID)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "support ticket"))
+		return
+	}
+
+	responses.SuccessOK(c, ticket)
+}
+
+// @Summary		Update support ticket
+// @Description	Changes the status or assignee of a ticket and optionally replies to the customer, who is notified by email
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id				path		int								true	"Ticket ID"
+// @Param			X-Admin-Actor	header		string							false	"Person making the change"
+// @Param			payload			body		dto.UpdateSupportTicketRequest	true	"Changes"
+// @Success		200				{object}	models.SupportTicket
+// @Failure		404				{object}	responses.ErrorResponse
+// @Failure		409				{object}	responses.ErrorResponse
+// @Router			/admin/support/tickets/{id} [patch]
+// @Security		ApiKeyAuth
+func (h *SupportHandler) Update(c *gin.Context) {
+	ticketID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid ticket id")
+		return
+	}
+
+	var payload dto.UpdateSupportTicketRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	actor := c.GetHeader(adminActorHeader)
+	if actor == "" {
+		actor = "admin"
+	}
+
+	ticket, err := h.supportService.UpdateTicket(c.Request.Context(), actor, ticketID, &payload)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "support ticket"))
+		return
+	}
+
+	responses.SuccessUpdated(c, ticket)
+}
+
+// @Summary		Set support tier
+// @Description	Sets the support tier of a user. Only tickets opened afterwards get the new response times.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id		path	int							true	"User ID"
+// @Param			payload	body	dto.SetSupportTierRequest	true	"Tier"
+// @Success		200
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/users/{id}/support-tier [put]
+// @Security		ApiKeyAuth
+func (h *SupportHandler) SetUserTier(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid user id")
+		return
+	}
+
+	var payload dto.SetSupportTierRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	if err := h.supportService.SetUserTier(c.Request.Context(), userID, models.SupportTier(payload.Tier)); err != nil {
+		c.Error(apperrors.NotFoundIf(err, "user"))
+		return
+	}
+
+	responses.SuccessUpdated(c, gin.H{"user_id": userID, "tier": payload.Tier})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type SyncHandler struct {
+	*handler.Handler
+	syncService service.SyncService
+}
+
+func NewSyncHandler(handler *handler.Handler, syncService service.SyncService) *SyncHandler {
+	return &SyncHandler{
+		Handler:     handler,
+		syncService: syncService,
+	}
+}
+
+// @Summary		Differential sync
+// @Description	Returns records created, updated or deleted since the given cursor
+// @Tags			sync
+// @Produce		json
+// @Param			since		query		string	false	"Cursor returned by the previous sync"
+// @Param			entities	query		string	false	"Comma separated entity types"
+// @Param			limit		query		int		false	"Max records per entity type"
+// @Success		200			{object}	dto.SyncResponse
+// @Failure		400			{object}	responses.ErrorResponse
+// @Router			/api/v1/sync [get]
+// @Security		BearerAuth
+func (h *SyncHandler) Sync(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		responses.ErrorUnauthorized(c, "user not authenticated")
+		return
+	}
+
+	var entityTypes []string
+	if entities := c.Query("entities"); entities != "" {
+		for _, entityType := range strings.Split(entities, ",") {
+			if entityType = strings.TrimSpace(entityType); entityType != "" {
+				entityTypes = append(entityTypes, entityType)
+			}
+		}
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			responses.ErrorBadRequest(c, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	changes, err := h.syncService.GetChanges(c.Request.Context(), userID, c.Query("since"), entityTypes, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessOK(c, changes)
+}
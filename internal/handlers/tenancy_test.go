@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
+	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+)
+
+func tenancyConfig() *config.Config {
+	return &config.Config{
+		Tenancy: config.TenancyConfig{Enabled: true, Header: "X-Tenant"},
+	}
+}
+
+// Admin routes run behind the tenant middleware; with tenants required a
+// query that drops the request context fails instead of reading public
+func TestAdminRouteRunsInTenantSchema(t *testing.T) {
+	cfg := tenancyConfig()
+	api := setup(t, cfg, tenancy.New(true))
+	slug := api.provisionTenant(t)
+
+	tenants := service.NewTenantService(api.container, api.db)
+	commission := handlers.NewCommissionHandler(handler.NewHandler(api.logger), service.NewCommissionService(api.container))
+
+	admin := api.router.Group("/admin", middleware.TenantMiddleware(cfg.Tenancy.Header, tenants.Exists))
+	admin.POST("/commission/rules", commission.CreateRule)
+	admin.GET("/commission/rules", commission.ListRules)
+
+	headers := map[string]string{cfg.Tenancy.Header: slug}
+	rec := api.do(http.MethodPost, "/admin/commission/rules", map[string]any{"category": "books", "rate_bps": 1200}, headers)
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = api.do(http.MethodGet, "/admin/commission/rules", nil, headers)
+	expectStatus(t, rec, http.StatusOK)
+
+	var rules []models.CommissionRule
+	err := api.db.WithContext(tenancy.WithTenant(context.Background(), slug)).Find(&rules).Error
+	if err != nil {
+		t.Fatalf("could not read the tenant rules: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Category != "books" {
+		t.Errorf("tenant schema has rules %+v, want the books rule", rules)
+	}
+}
+
+// Scheduled jobs have no request; runSingleton gives them the context of
+// each tenant
+func TestScheduledJobRunsInTenantSchema(t *testing.T) {
+	cfg := tenancyConfig()
+	cfg.Backup.Interval = 50 * time.Millisecond
+	api := setup(t, cfg, tenancy.New(true))
+	slug := api.provisionTenant(t)
+
+	encryptor, err := encryption.NewEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := encryption.Default()
+	encryption.SetDefault(encryptor)
+	t.Cleanup(func() { encryption.SetDefault(previous) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backups := service.NewBackupService(api.container, storage.NewMemoryStorage())
+	backups.StartScheduler(ctx)
+
+	tenantCtx := tenancy.WithTenant(context.Background(), slug)
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		records, err := backups.List(tenantCtx, 1)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(records) > 0 && records[0].Status != models.BackupStatusRunning {
+			if records[0].Status != models.BackupStatusCompleted {
+				t.Fatalf("scheduled backup status = %s, error %q", records[0].Status, records[0].Error)
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("no scheduled backup completed in the tenant schema")
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type TenantHandler struct {
+	*handler.Handler
+	tenantService service.TenantService
+}
+
+func NewTenantHandler(handler *handler.Handler, tenantService service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		Handler:       handler,
+		tenantService: tenantService,
+	}
+}
+
+// @Summary		List tenants
+// @Description	Lists the provisioned tenants with the schema version of each
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	models.Tenant
+// @Router			/admin/tenants [get]
+// @Security		ApiKeyAuth
+func (h *TenantHandler) List(c *gin.Context) {
+	tenants, err := h.tenantService.List(c.Request.Context())
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, tenants)
+}
+
+// @Summary		Provision tenant
+// @Description	Creates the schema of a tenant, applies every migration to it and registers the tenant
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.ProvisionTenantRequest	true	"Tenant"
+// @Success		201		{object}	models.Tenant
+// @Failure		400		{object}	responses.ErrorResponse
+// @Failure		409		{object}	responses.ErrorResponse
+// @Router			/admin/tenants [post]
+// @Security		ApiKeyAuth
+func (h *TenantHandler) Provision(c *gin.Context) {
+	var payload dto.ProvisionTenantRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	tenant, err := h.tenantService.Provision(c.Request.Context(), payload.Slug)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "tenant"))
+		return
+	}
+
+	responses.SuccessCreated(c, tenant)
+}
+
+// @Summary		Deprovision tenant
+// @Description	Unregisters a tenant and drops its schema with all its data
+// @Tags			admin
+// @Produce		json
+// @Param			slug	path		string	true	"Tenant slug"
+// @Success		200		{object}	responses.SuccessResponse
+// @Failure		404		{object}	responses.ErrorResponse
+// @Router			/admin/tenants/{slug} [delete]
+// @Security		ApiKeyAuth
+func (h *TenantHandler) Deprovision(c *gin.Context) {
+	if err := h.tenantService.Deprovision(c.Request.Context(), c.Param("slug")); err != nil {
+		c.Error(apperrors.NotFoundIf(err, "tenant"))
+		return
+	}
+
+	responses.SuccessDeleted(c)
+}
+
+// @Summary		Migrate tenants
+// @Description	Applies the pending migrations to every tenant schema, stopping at the first failure
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}		models.Tenant
+// @Failure		400	{object}	responses.ErrorResponse
+// @Router			/admin/tenants/migrate [post]
+// @Security		ApiKeyAuth
+func (h *TenantHandler) Migrate(c *gin.Context) {
+	tenants, err := h.tenantService.MigrateAll(c.Request.Context())
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "tenant"))
+		return
+	}
+
+	responses.SuccessOK(c, tenants)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/handler"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+type WebhookHandler struct {
+	*handler.Handler
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(handler *handler.Handler, webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		Handler:        handler,
+		webhookService: webhookService,
+	}
+}
+
+// @Summary		List webhooks
+// @Tags			admin
+// @Produce		json
+// @Success		200	{array}	models.Webhook
+// @Router			/admin/webhooks [get]
+// @Security		ApiKeyAuth
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.webhookService.List(c.Request.Context())
+	if err != nil {
+		responses.ErrorInternalServer(c, err.Error())
+		return
+	}
+
+	responses.SuccessOK(c, webhooks)
+}
+
+// @Summary		Create webhook
+// @Description	Registers an https endpoint for change events, e.g. support_ticket.updated. Deliveries are signed with the returned secret in the X-Webhook-Signature header as t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">. The secret is not shown again.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body		dto.CreateWebhookRequest	true	"Webhook"
+// @Success		201		{object}	dto.WebhookCreatedResponse
+// @Failure		400		{object}	responses.ErrorResponse
+// @Router			/admin/webhooks [post]
+// @Security		ApiKeyAuth
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var payload dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	created, err := h.webhookService.Create(c.Request.Context(), &payload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	responses.SuccessCreated(c, created)
+}
+
+// @Summary		Update webhook
+// @Description	Changes the url, events or description of a webhook, or disables it. Pending deliveries of a disabled webhook are not sent.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int							true	"Webhook ID"
+// @Param			payload	body		dto.UpdateWebhookRequest	true	"Changes"
+// @Success		200		{object}	models.Webhook
+// @Failure		400		{object}	responses.ErrorResponse
+// @Failure		404		{object}	responses.ErrorResponse
+// @Router			/admin/webhooks/{id} [patch]
+// @Security		ApiKeyAuth
+func (h *WebhookHandler) Update(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid webhook id")
+		return
+	}
+
+	var payload dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		responses.ErrorBindJson(c, err)
+		return
+	}
+
+	webhook, err := h.webhookService.Update(c.Request.Context(), webhookID, &payload)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "webhook"))
+		return
+	}
+
+	responses.SuccessOK(c, webhook)
+}
+
+// @Summary		Delete webhook
+// @Description	Deletes the webhook and its delivery log
+// @Tags			admin
+// @Produce		json
+// @Param			id	path	int	true	"Webhook ID"
+// @Success		200
+// @Failure		404	{object}	responses.ErrorResponse
+// @Router			/admin/webhooks/{id} [delete]
+// @Security		ApiKeyAuth
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid webhook id")
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), webhookID); err != nil {
+		c.Error(apperrors.NotFoundIf(err, "webhook"))
+		return
+	}
+
+	responses.SuccessDeleted(c)
+}
+
+// @Summary		Webhook delivery log
+// @Description	Returns the deliveries of a webhook, newest first, with the response status and error of the last attempt
+// @Tags			admin
+// @Produce		json
+// @Param			id		path	int		true	"Webhook ID"
+// @Param			status	query	string	false	"pending, delivered or failed"
+// @Param			limit	query	int		false	"Max entries, up to 200"
+// @Success		200		{array}	dto.WebhookDeliveryItem
+// @Failure		400		{object}	responses.ErrorResponse
+// @Failure		404		{object}	responses.ErrorResponse
+// @Router			/admin/webhooks/{id}/deliveries [get]
+// @Security		ApiKeyAuth
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		responses.ErrorBadRequest(c, "invalid webhook id")
+		return
+	}
+
+	status := models.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		responses.ErrorBadRequest(c, "invalid status")
+		return
+	}
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			responses.ErrorBadRequest(c, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), webhookID, status, limit)
+	if err != nil {
+		c.Error(apperrors.NotFoundIf(err, "webhook"))
+		return
+	}
+
+	responses.SuccessOK(c, deliveries)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type ActivityType string
+
+const (
+	ActivitySessionsRevoked ActivityType = "sessions_revoked"
+	ActivityStorageChanged  ActivityType = "storage_changed"
+)
+
+// ActivityTypes lists every type accepted by the activity feed filter
+var ActivityTypes = []ActivityType{
+	ActivitySessionsRevoked,
+	ActivityStorageChanged,
+}
+
+// Activity is an entry of a user's activity feed
+type Activity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	UserID uint         `json:"user_id" gorm:"not null;index"`
+	Type   ActivityType `json:"type" gorm:"not null;index"`
+
+	// Data is the JSON encoded detail of the event, e.g. the reason sessions were revoked
+	Data string `json:"data" gorm:"type:text"`
+}
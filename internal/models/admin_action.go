@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type AdminActionStatus string
+
+const (
+	AdminActionStatusSucceeded AdminActionStatus = "succeeded"
+	AdminActionStatusFailed    AdminActionStatus = "failed"
+	AdminActionStatusRejected  AdminActionStatus = "rejected"
+)
+
+// AdminActionExecution is the audit record of an admin action run
+type AdminActionExecution struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	Action     string            `json:"action" gorm:"not null;index"`
+	Actor      string            `json:"actor" gorm:"not null"`
+	Role       string            `json:"role" gorm:"not null"`
+	Params     string            `json:"params" gorm:"type:text"`
+	DryRun     bool              `json:"dry_run"`
+	Status     AdminActionStatus `json:"status" gorm:"not null;index"`
+	Result     string            `json:"result,omitempty" gorm:"type:text"`
+	Error      string            `json:"error,omitempty" gorm:"type:text"`
+	DurationMs int64             `json:"duration_ms"`
+}
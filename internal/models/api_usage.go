@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ApiUsageDaily is the persisted daily rollup of the per-user request counters
+type ApiUsageDaily struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID   uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_api_usage_daily"`
+	Day      time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_api_usage_daily;index"`
+	Method   string    `json:"method" gorm:"not null;uniqueIndex:idx_api_usage_daily"`
+	Route    string    `json:"route" gorm:"not null;uniqueIndex:idx_api_usage_daily"`
+	Requests int64     `json:"requests" gorm:"not null;default:0"`
+	BytesIn  int64     `json:"bytes_in" gorm:"not null;default:0"`
+	BytesOut int64     `json:"bytes_out" gorm:"not null;default:0"`
+}
+
+// ApiUsageTotal is an aggregate over a range of ApiUsageDaily rows
+type ApiUsageTotal struct {
+	UserID   uint   `json:"user_id"`
+	Method   string `json:"method,omitempty"`
+	Route    string `json:"route,omitempty"`
+	Requests int64  `json:"requests"`
+	BytesIn  int64  `json:"bytes_in"`
+	BytesOut int64  `json:"bytes_out"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AuditLog records a write made by a mutating API request. A request that
+// writes several rows gets one entry per row, sharing its request id; one
+// that writes nothing gets a single entry without an entity.
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	Actor     string `json:"actor" gorm:"not null;index"`
+	UserID    *uint  `json:"user_id" gorm:"index"`
+	Method    string `json:"method" gorm:"not null"`
+	Route     string `json:"route" gorm:"not null"`
+	Path      string `json:"path" gorm:"not null"`
+	Status    int    `json:"status" gorm:"not null"`
+	IP        string `json:"ip"`
+	RequestID string `json:"request_id" gorm:"index"`
+
+	EntityType string `json:"entity_type" gorm:"index:idx_audit_logs_entity"`
+	EntityID   string `json:"entity_id" gorm:"index:idx_audit_logs_entity"`
+	// Action is created, updated or deleted, empty when nothing was written
+	Action string `json:"action"`
+	// Changes is the JSON encoded before and after value of each changed
+	// column, when they could be read cheaply
+	Changes string `json:"-" gorm:"type:text"`
+}
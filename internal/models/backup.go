@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BackupStatus string
+
+const (
+	BackupStatusRunning   BackupStatus = "running"
+	BackupStatusCompleted BackupStatus = "completed"
+	BackupStatusFailed    BackupStatus = "failed"
+)
+
+// BackupRecord is the catalog entry of a logical backup archive
+type BackupRecord struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Status      BackupStatus `json:"status" gorm:"not null;index"`
+	Trigger     string       `json:"trigger" gorm:"not null"`
+	Tables      string       `json:"tables" gorm:"not null"`
+	ObjectKey   string       `json:"object_key"`
+	SizeBytes   int64        `json:"size_bytes"`
+	Checksum    string       `json:"checksum"`
+	RowCounts   string       `json:"row_counts" gorm:"type:text"`
+	Error       string       `json:"error,omitempty" gorm:"type:text"`
+	SnapshotAt  time.Time    `json:"snapshot_at"`
+	CompletedAt *time.Time   `json:"completed_at"`
+}
+
+// AfterDelete queues the archive for deletion. The record must be loaded,
+// deletes by condition don't carry the object key.
+func (b *BackupRecord) AfterDelete(tx *gorm.DB) error {
+	return EnqueueStorageDeletion(tx, "backups", b.ObjectKey)
+}
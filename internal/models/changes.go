@@ -0,0 +1,21 @@
+package models
+
+import "github.com/imlargo/go-api/pkg/medusa/core/changes"
+
+// ChangeSchemas are the change events published to the SSE stream. Clients
+// can subscribe to a subset with the entities filter.
+var ChangeSchemas = []changes.Schema{
+	{
+		Entity:      EntityTypeSupportTicket,
+		Actions:     []string{changes.ActionCreated, changes.ActionUpdated},
+		Description: "Support ticket, sent to the user who opened it",
+	},
+}
+
+func (t *SupportTicket) ChangeEntity() string {
+	return EntityTypeSupportTicket
+}
+
+func (t *SupportTicket) ChangeAudience() []uint {
+	return []uint{t.UserID}
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+)
+
+// ChatChannel is a Slack or Discord incoming webhook that receives alerts.
+// Channels without a user belong to the operations team.
+type ChatChannel struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID     *uint                      `json:"user_id" gorm:"index"`
+	Name       string                     `json:"name" gorm:"not null"`
+	Provider   string                     `json:"provider" gorm:"not null"`
+	WebhookURL encryption.EncryptedString `json:"-" gorm:"not null"`
+	Events     []string                   `json:"events" gorm:"serializer:json;not null"`
+	Enabled    bool                       `json:"enabled" gorm:"not null;default:true"`
+	LastSentAt *time.Time                 `json:"last_sent_at"`
+	LastError  string                     `json:"last_error,omitempty" gorm:"type:text"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// CommissionRule is a versioned platform commission rate. A rule with an
+// empty Category applies to every category and a nil SellerID applies to
+// every seller. Rules are never edited in place: a new version closes the
+// previous one by setting its EffectiveTo.
+type CommissionRule struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Category           string     `json:"category" gorm:"not null;default:'';index"`
+	SellerID           *uint      `json:"seller_id" gorm:"index"`
+	MinCompletedOrders int        `json:"min_completed_orders" gorm:"not null;default:0"`
+	RateBps            int        `json:"rate_bps" gorm:"not null"`
+	EffectiveFrom      time.Time  `json:"effective_from" gorm:"not null;index"`
+	EffectiveTo        *time.Time `json:"effective_to" gorm:"index"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+type IncidentImpact string
+
+const (
+	IncidentImpactMinor    IncidentImpact = "minor"
+	IncidentImpactMajor    IncidentImpact = "major"
+	IncidentImpactCritical IncidentImpact = "critical"
+)
+
+type IncidentStatus string
+
+const (
+	IncidentInvestigating IncidentStatus = "investigating"
+	IncidentIdentified    IncidentStatus = "identified"
+	IncidentMonitoring    IncidentStatus = "monitoring"
+	IncidentResolved      IncidentStatus = "resolved"
+)
+
+// Incident is an outage published on the status page. Components are the
+// names of the status components it affects. Incidents concern the whole
+// platform and are stored in the public schema.
+type Incident struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Title      string         `json:"title" gorm:"not null"`
+	Impact     IncidentImpact `json:"impact" gorm:"not null"`
+	Status     IncidentStatus `json:"status" gorm:"not null;index"`
+	Components []string       `json:"components" gorm:"serializer:json;not null"`
+	StartedAt  time.Time      `json:"started_at" gorm:"not null;index"`
+	ResolvedAt *time.Time     `json:"resolved_at"`
+
+	Updates []*IncidentUpdate `json:"updates,omitempty" gorm:"foreignKey:IncidentID"`
+}
+
+func (Incident) TenantShared() {}
+
+// IsActive reports whether the incident still affects its components
+func (i *Incident) IsActive() bool {
+	return i.Status != IncidentResolved
+}
+
+// IncidentUpdate is a progress message posted on an incident
+type IncidentUpdate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	IncidentID uint           `json:"incident_id" gorm:"not null;index"`
+	Status     IncidentStatus `json:"status" gorm:"not null"`
+	Message    string         `json:"message" gorm:"type:text;not null"`
+}
+
+func (IncidentUpdate) TenantShared() {}
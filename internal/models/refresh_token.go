@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RefreshToken is a single use token exchanged for a new access token.
+// Every refresh rotates it into a new token of the same family, the chain
+// started by one sign in. Presenting a token that was already used means
+// it leaked, and the whole family is revoked.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	FamilyID  string    `json:"family_id" gorm:"not null;size:32;index"`
+	TokenHash string    `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
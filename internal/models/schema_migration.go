@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SchemaMigration records an applied schema migration
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `json:"name" gorm:"not null"`
+	Breaking  bool      `json:"breaking" gorm:"not null;default:false"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
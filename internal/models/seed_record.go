@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SeedRecord remembers the checksum of a seeded default as it was written,
+// so a later seed can tell whether someone edited the row since
+type SeedRecord struct {
+	Key       string    `gorm:"primaryKey" json:"key"`
+	Checksum  string    `json:"checksum" gorm:"not null"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+)
+
+type StorageConfigStatus string
+
+const (
+	StorageConfigStatusPending   StorageConfigStatus = "pending"
+	StorageConfigStatusHealthy   StorageConfigStatus = "healthy"
+	StorageConfigStatusUnhealthy StorageConfigStatus = "unhealthy"
+)
+
+// UserStorageConfig is a customer-provided bucket that holds the files of
+// a user instead of the platform bucket
+type UserStorageConfig struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID          uint                       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Provider        string                     `json:"provider" gorm:"not null"`
+	BucketName      string                     `json:"bucket_name" gorm:"not null"`
+	AccountID       string                     `json:"account_id" gorm:"not null"`
+	AccessKeyID     encryption.EncryptedString `json:"-" gorm:"not null"`
+	SecretAccessKey encryption.EncryptedString `json:"-" gorm:"not null"`
+	PublicDomain    string                     `json:"public_domain"`
+	UsePublicURL    bool                       `json:"use_public_url"`
+	Enabled         bool                       `json:"enabled" gorm:"not null;default:true"`
+	Status          StorageConfigStatus        `json:"status" gorm:"not null;index"`
+	LastCheckedAt   *time.Time                 `json:"last_checked_at"`
+	LastError       string                     `json:"last_error,omitempty" gorm:"type:text"`
+}
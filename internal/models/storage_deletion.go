@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StorageDeletionStatus string
+
+const (
+	StorageDeletionPending StorageDeletionStatus = "pending"
+	StorageDeletionDeleted StorageDeletionStatus = "deleted"
+	StorageDeletionFailed  StorageDeletionStatus = "failed"
+)
+
+// StorageDeletion is a queued delete of a bucket object whose row is gone.
+// Deleted entries are kept as tombstones so the orphan scan and support can
+// tell a purged object from one that was never tracked.
+type StorageDeletion struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ObjectKey     string                `json:"object_key" gorm:"not null;index"`
+	Source        string                `json:"source" gorm:"not null"`
+	Status        StorageDeletionStatus `json:"status" gorm:"not null;index"`
+	Attempts      int                   `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time             `json:"next_attempt_at" gorm:"not null;index"`
+	LastError     string                `json:"last_error,omitempty" gorm:"type:text"`
+	DeletedAt     *time.Time            `json:"deleted_at"`
+}
+
+// EnqueueStorageDeletion queues the object for deletion in the transaction
+// that deletes the row referencing it, so the object is never forgotten
+// when the row deletion commits
+func EnqueueStorageDeletion(tx *gorm.DB, source, key string) error {
+	if key == "" {
+		return nil
+	}
+
+	return tx.Create(&StorageDeletion{
+		ObjectKey:     key,
+		Source:        source,
+		Status:        StorageDeletionPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
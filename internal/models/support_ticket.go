@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+const EntityTypeSupportTicket = "support_ticket"
+
+type SupportTier string
+
+const (
+	SupportTierStandard   SupportTier = "standard"
+	SupportTierPriority   SupportTier = "priority"
+	SupportTierEnterprise SupportTier = "enterprise"
+)
+
+type SupportTicketStatus string
+
+const (
+	SupportTicketOpen              SupportTicketStatus = "open"
+	SupportTicketInProgress        SupportTicketStatus = "in_progress"
+	SupportTicketWaitingOnCustomer SupportTicketStatus = "waiting_on_customer"
+	SupportTicketResolved          SupportTicketStatus = "resolved"
+	SupportTicketClosed            SupportTicketStatus = "closed"
+)
+
+type SupportTicketCategory string
+
+const (
+	SupportCategoryBilling   SupportTicketCategory = "billing"
+	SupportCategoryTechnical SupportTicketCategory = "technical"
+	SupportCategoryAccount   SupportTicketCategory = "account"
+	SupportCategoryOther     SupportTicketCategory = "other"
+)
+
+// SupportTicket is a request for help from a user. The SLA deadlines are
+// fixed at creation from the tier the user had then.
+type SupportTicket struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      uint                  `json:"user_id" gorm:"not null;index"`
+	Tier        SupportTier           `json:"tier" gorm:"not null;index"`
+	Category    SupportTicketCategory `json:"category" gorm:"not null"`
+	Subject     string                `json:"subject" gorm:"not null"`
+	Description string                `json:"description" gorm:"type:text;not null"`
+	Status      SupportTicketStatus   `json:"status" gorm:"not null;index"`
+	AssignedTo  string                `json:"assigned_to,omitempty" gorm:"index"`
+
+	FirstResponseDueAt time.Time  `json:"first_response_due_at" gorm:"not null"`
+	ResolutionDueAt    time.Time  `json:"resolution_due_at" gorm:"not null;index"`
+	FirstRespondedAt   *time.Time `json:"first_responded_at"`
+	ResolvedAt         *time.Time `json:"resolved_at"`
+
+	// Breaches are recorded once so each one is only alerted once
+	FirstResponseBreachedAt *time.Time `json:"first_response_breached_at"`
+	ResolutionBreachedAt    *time.Time `json:"resolution_breached_at"`
+
+	Attachments []*SupportTicketAttachment `json:"attachments,omitempty" gorm:"foreignKey:TicketID"`
+	Messages    []*SupportTicketMessage    `json:"messages,omitempty" gorm:"foreignKey:TicketID"`
+}
+
+// IsOpen reports whether the ticket still counts against its SLA
+func (t *SupportTicket) IsOpen() bool {
+	return t.Status != SupportTicketResolved && t.Status != SupportTicketClosed
+}
+
+// SupportTicketMessage is a reply on a ticket. Author is the admin actor
+// for staff replies, empty for the user.
+type SupportTicketMessage struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	TicketID uint   `json:"ticket_id" gorm:"not null;index"`
+	Staff    bool   `json:"staff" gorm:"not null;default:false"`
+	Author   string `json:"author,omitempty"`
+	Body     string `json:"body" gorm:"type:text;not null"`
+}
+
+// SupportTicketAttachment is a file uploaded with a ticket. URL is a
+// presigned download link filled in when the ticket is read.
+type SupportTicketAttachment struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	TicketID    uint   `json:"ticket_id" gorm:"not null;index"`
+	Name        string `json:"name" gorm:"not null"`
+	ContentType string `json:"content_type" gorm:"not null"`
+	SizeBytes   int64  `json:"size_bytes" gorm:"not null"`
+	StorageKey  string `json:"-" gorm:"not null"`
+	URL         string `json:"url,omitempty" gorm:"-"`
+}
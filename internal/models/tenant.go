@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Tenant is an isolated customer whose data lives in its own schema. The
+// registry itself is stored in the public schema.
+type Tenant struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Slug          string `json:"slug" gorm:"not null;uniqueIndex"`
+	Schema        string `json:"schema" gorm:"not null;uniqueIndex"`
+	SchemaVersion int    `json:"schema_version" gorm:"not null;default:0"`
+}
+
+func (Tenant) TenantShared() {}
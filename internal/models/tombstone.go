@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Tombstone records the deletion of an entity so that offline clients can
+// learn about it on their next differential sync.
+type Tombstone struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	EntityType string `json:"entity_type" gorm:"not null;index:idx_tombstone_entity"`
+	EntityID   uint   `json:"entity_id" gorm:"not null;index:idx_tombstone_entity"`
+	OwnerID    uint   `json:"owner_id" gorm:"not null;index"`
+}
@@ -1,11 +1,33 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const EntityTypeUser = "users"
 
 type User struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"index"`
 
 	Email string `json:"email" gorm:"unique;not null"`
+
+	// SupportTier sets the response times promised on support tickets
+	SupportTier SupportTier `json:"support_tier" gorm:"not null;default:'standard'"`
+
+	// ClaimsVersion is bumped to invalidate every token issued before
+	ClaimsVersion int64 `json:"-" gorm:"not null;default:0"`
+}
+
+// AfterDelete leaves a tombstone behind in the same transaction so the
+// deletion is visible to sync clients.
+func (u *User) AfterDelete(tx *gorm.DB) error {
+	return tx.Create(&Tombstone{
+		EntityType: EntityTypeUser,
+		EntityID:   u.ID,
+		OwnerID:    u.ID,
+	}).Error
 }
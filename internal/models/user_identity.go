@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to an account at an OAuth provider. Subject is
+// the provider's id of the account, which unlike the email never changes.
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID   uint   `json:"user_id" gorm:"not null;index"`
+	Provider string `json:"provider" gorm:"not null;uniqueIndex:idx_user_identity_subject"`
+	Subject  string `json:"subject" gorm:"not null;uniqueIndex:idx_user_identity_subject"`
+	// Email is the address the provider reported on the last sign in
+	Email string `json:"email"`
+}
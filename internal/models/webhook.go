@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+)
+
+// Webhook is an external endpoint that receives the change events it
+// subscribes to, signed with its secret
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	URL         string                     `json:"url" gorm:"not null"`
+	Description string                     `json:"description"`
+	Secret      encryption.EncryptedString `json:"-" gorm:"not null"`
+	Events      []string                   `json:"events" gorm:"serializer:json;not null"`
+	Enabled     bool                       `json:"enabled" gorm:"not null;default:true"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event queued for a webhook, kept after it is sent
+// as the delivery log of the webhook. Payload is the JSON body posted.
+type WebhookDelivery struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	WebhookID      uint                  `json:"webhook_id" gorm:"not null;index"`
+	Event          string                `json:"event" gorm:"not null"`
+	Payload        string                `json:"-" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"not null;index"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" gorm:"not null;index"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type ActivityRepository interface {
+	Create(ctx context.Context, activity *models.Activity) error
+	List(ctx context.Context, userID uint, types []models.ActivityType, beforeID uint, limit int) ([]*models.Activity, error)
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type activityRepository struct {
+	*medusarepo.Repository
+}
+
+func NewActivityRepository(repo *medusarepo.Repository) ActivityRepository {
+	return &activityRepository{Repository: repo}
+}
+
+func (r *activityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	return r.DB(ctx).Create(activity).Error
+}
+
+// List returns the newest activities of a user first. A non zero beforeID
+// continues after the last entry of the previous page.
+func (r *activityRepository) List(ctx context.Context, userID uint, types []models.ActivityType, beforeID uint, limit int) ([]*models.Activity, error) {
+	query := r.ReadDB(ctx).Where("user_id = ?", userID)
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var activities []*models.Activity
+	if err := query.Order("id DESC").Limit(limit).Find(&activities).Error; err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
+
+func (r *activityRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.DB(ctx).Where("created_at < ?", cutoff).Delete(&models.Activity{})
+	return result.RowsAffected, result.Error
+}
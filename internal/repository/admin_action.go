@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/query"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type AdminActionRepository interface {
+	Create(ctx context.Context, execution *models.AdminActionExecution) error
+	List(ctx context.Context, filters *query.Query, limit int) ([]*models.AdminActionExecution, error)
+}
+
+type adminActionRepository struct {
+	*medusarepo.Repository
+}
+
+func NewAdminActionRepository(repo *medusarepo.Repository) AdminActionRepository {
+	return &adminActionRepository{Repository: repo}
+}
+
+func (r *adminActionRepository) Create(ctx context.Context, execution *models.AdminActionExecution) error {
+	return r.DB(ctx).Create(execution).Error
+}
+
+func (r *adminActionRepository) List(ctx context.Context, filters *query.Query, limit int) ([]*models.AdminActionExecution, error) {
+	var executions []*models.AdminActionExecution
+	if err := r.ReadDB(ctx).Scopes(filters.Scope).Order("id DESC").Limit(limit).Find(&executions).Error; err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm/clause"
+)
+
+type ApiUsageRepository interface {
+	Upsert(ctx context.Context, rows []*models.ApiUsageDaily) error
+	GetDaily(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageDaily, error)
+	GetBreakdown(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageTotal, error)
+	GetTopConsumers(ctx context.Context, from, to time.Time, limit int) ([]*models.ApiUsageTotal, error)
+}
+
+type apiUsageRepository struct {
+	*medusarepo.Repository
+}
+
+func NewApiUsageRepository(repo *medusarepo.Repository) ApiUsageRepository {
+	return &apiUsageRepository{Repository: repo}
+}
+
+// Upsert adds the given counters to the existing rows, so a rollup can be
+// safely re-run for a partially processed day.
+func (r *apiUsageRepository) Upsert(ctx context.Context, rows []*models.ApiUsageDaily) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return r.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}, {Name: "method"}, {Name: "route"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "requests"}, Value: clause.Expr{SQL: "api_usage_dailies.requests + excluded.requests"}},
+			{Column: clause.Column{Name: "bytes_in"}, Value: clause.Expr{SQL: "api_usage_dailies.bytes_in + excluded.bytes_in"}},
+			{Column: clause.Column{Name: "bytes_out"}, Value: clause.Expr{SQL: "api_usage_dailies.bytes_out + excluded.bytes_out"}},
+			{Column: clause.Column{Name: "updated_at"}, Value: clause.Expr{SQL: "excluded.updated_at"}},
+		},
+	}).Create(&rows).Error
+}
+
+func (r *apiUsageRepository) GetDaily(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageDaily, error) {
+	var rows []*models.ApiUsageDaily
+	err := r.ReadDB(ctx).
+		Where("user_id = ? AND day >= ? AND day <= ?", userID, from, to).
+		Order("day ASC, route ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetBreakdown aggregates usage per user and route. A zero userID returns
+// every user.
+func (r *apiUsageRepository) GetBreakdown(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageTotal, error) {
+	var totals []*models.ApiUsageTotal
+	query := r.ReadDB(ctx).
+		Model(&models.ApiUsageDaily{}).
+		Select("user_id, method, route, SUM(requests) AS requests, SUM(bytes_in) AS bytes_in, SUM(bytes_out) AS bytes_out").
+		Where("day >= ? AND day <= ?", from, to)
+
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	err := query.
+		Group("user_id, method, route").
+		Order("requests DESC").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+func (r *apiUsageRepository) GetTopConsumers(ctx context.Context, from, to time.Time, limit int) ([]*models.ApiUsageTotal, error) {
+	var totals []*models.ApiUsageTotal
+	err := r.ReadDB(ctx).
+		Model(&models.ApiUsageDaily{}).
+		Select("user_id, SUM(requests) AS requests, SUM(bytes_in) AS bytes_in, SUM(bytes_out) AS bytes_out").
+		Where("day >= ? AND day <= ?", from, to).
+		Group("user_id").
+		Order("requests DESC").
+		Limit(limit).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/query"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type AuditLogRepository interface {
+	CreateMany(ctx context.Context, logs []*models.AuditLog) error
+	List(ctx context.Context, filters *query.Query, limit int) ([]*models.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	*medusarepo.Repository
+}
+
+func NewAuditLogRepository(repo *medusarepo.Repository) AuditLogRepository {
+	return &auditLogRepository{Repository: repo}
+}
+
+func (r *auditLogRepository) CreateMany(ctx context.Context, logs []*models.AuditLog) error {
+	return r.DB(ctx).Create(logs).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filters *query.Query, limit int) ([]*models.AuditLog, error) {
+	var logs []*models.AuditLog
+	if err := r.ReadDB(ctx).Scopes(filters.Scope).Order("id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type BackupRepository interface {
+	Create(ctx context.Context, record *models.BackupRecord) error
+	Update(ctx context.Context, record *models.BackupRecord) error
+	GetByID(ctx context.Context, id uint) (*models.BackupRecord, error)
+	List(ctx context.Context, limit int) ([]*models.BackupRecord, error)
+	ListObjectKeys(ctx context.Context) ([]string, error)
+}
+
+type backupRepository struct {
+	*medusarepo.Repository
+}
+
+func NewBackupRepository(repo *medusarepo.Repository) BackupRepository {
+	return &backupRepository{Repository: repo}
+}
+
+func (r *backupRepository) Create(ctx context.Context, record *models.BackupRecord) error {
+	return r.DB(ctx).Create(record).Error
+}
+
+func (r *backupRepository) Update(ctx context.Context, record *models.BackupRecord) error {
+	return r.DB(ctx).Save(record).Error
+}
+
+func (r *backupRepository) GetByID(ctx context.Context, id uint) (*models.BackupRecord, error) {
+	var record models.BackupRecord
+	if err := r.DB(ctx).First(&record, id).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *backupRepository) List(ctx context.Context, limit int) ([]*models.BackupRecord, error) {
+	var records []*models.BackupRecord
+	if err := r.DB(ctx).Order("created_at DESC").Limit(limit).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *backupRepository) ListObjectKeys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := r.DB(ctx).Model(&models.BackupRecord{}).
+		Where("object_key <> ''").
+		Pluck("object_key", &keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type ChatChannelRepository interface {
+	Create(ctx context.Context, channel *models.ChatChannel) error
+	GetByID(ctx context.Context, id uint) (*models.ChatChannel, error)
+	ListByOwner(ctx context.Context, userID *uint) ([]*models.ChatChannel, error)
+	Delete(ctx context.Context, id uint) error
+	RecordDelivery(ctx context.Context, id uint, sentAt time.Time, lastError string) error
+}
+
+type chatChannelRepository struct {
+	*medusarepo.Repository
+}
+
+func NewChatChannelRepository(repo *medusarepo.Repository) ChatChannelRepository {
+	return &chatChannelRepository{Repository: repo}
+}
+
+func (r *chatChannelRepository) Create(ctx context.Context, channel *models.ChatChannel) error {
+	return r.DB(ctx).Create(channel).Error
+}
+
+func (r *chatChannelRepository) GetByID(ctx context.Context, id uint) (*models.ChatChannel, error) {
+	var channel models.ChatChannel
+	if err := r.DB(ctx).First(&channel, id).Error; err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// ListByOwner returns the channels of a user, or the operations channels
+// when userID is nil
+func (r *chatChannelRepository) ListByOwner(ctx context.Context, userID *uint) ([]*models.ChatChannel, error) {
+	query := r.DB(ctx)
+	if userID == nil {
+		query = query.Where("user_id IS NULL")
+	} else {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var channels []*models.ChatChannel
+	if err := query.Order("id ASC").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (r *chatChannelRepository) Delete(ctx context.Context, id uint) error {
+	return r.DB(ctx).Delete(&models.ChatChannel{}, id).Error
+}
+
+// RecordDelivery stores the outcome of the last message sent to a channel
+func (r *chatChannelRepository) RecordDelivery(ctx context.Context, id uint, sentAt time.Time, lastError string) error {
+	return r.DB(ctx).Model(&models.ChatChannel{}).Where("id = ?", id).Updates(map[string]any{
+		"last_sent_at": sentAt,
+		"last_error":   lastError,
+	}).Error
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm"
+)
+
+type CommissionRepository interface {
+	Create(ctx context.Context, rule *models.CommissionRule) error
+	GetByID(ctx context.Context, id uint) (*models.CommissionRule, error)
+	List(ctx context.Context, activeAt *time.Time) ([]*models.CommissionRule, error)
+	GetApplicable(ctx context.Context, sellerID uint, category string, at time.Time) ([]*models.CommissionRule, error)
+	GetOpenDefault(ctx context.Context, category string, minCompletedOrders int) (*models.CommissionRule, error)
+	CloseOverlappingVersions(ctx context.Context, rule *models.CommissionRule) error
+	Expire(ctx context.Context, id uint, at time.Time) error
+}
+
+type commissionRepository struct {
+	*medusarepo.Repository
+}
+
+func NewCommissionRepository(repo *medusarepo.Repository) CommissionRepository {
+	return &commissionRepository{Repository: repo}
+}
+
+func (r *commissionRepository) Create(ctx context.Context, rule *models.CommissionRule) error {
+	return r.DB(ctx).Create(rule).Error
+}
+
+func (r *commissionRepository) GetByID(ctx context.Context, id uint) (*models.CommissionRule, error) {
+	var rule models.CommissionRule
+	if err := r.DB(ctx).First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *commissionRepository) List(ctx context.Context, activeAt *time.Time) ([]*models.CommissionRule, error) {
+	var rules []*models.CommissionRule
+	query := r.DB(ctx)
+	if activeAt != nil {
+		query = query.Where("effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", *activeAt, *activeAt)
+	}
+
+	if err := query.Order("category ASC, seller_id ASC, min_completed_orders ASC, effective_from DESC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetApplicable returns every rule in effect at the given time that could
+// apply to the seller and category, from global defaults to seller overrides.
+func (r *commissionRepository) GetApplicable(ctx context.Context, sellerID uint, category string, at time.Time) ([]*models.CommissionRule, error) {
+	var rules []*models.CommissionRule
+	err := r.DB(ctx).
+		Where("category = ? OR category = ''", category).
+		Where("seller_id = ? OR seller_id IS NULL", sellerID).
+		Where("effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", at, at).
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetOpenDefault returns the latest open rule that applies to every seller
+// of the category and tier
+func (r *commissionRepository) GetOpenDefault(ctx context.Context, category string, minCompletedOrders int) (*models.CommissionRule, error) {
+	var rule models.CommissionRule
+	err := r.DB(ctx).
+		Where("category = ? AND min_completed_orders = ? AND seller_id IS NULL", category, minCompletedOrders).
+		Where("effective_to IS NULL").
+		Order("effective_from DESC").
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CloseOverlappingVersions fits rule between the versions of its scope. The
+// version in effect when rule starts ends at that moment, and rule ends when
+// the next version already scheduled after it starts.
+func (r *commissionRepository) CloseOverlappingVersions(ctx context.Context, rule *models.CommissionRule) error {
+	err := r.sameScope(ctx, rule).
+		Model(&models.CommissionRule{}).
+		Where("effective_from < ? AND (effective_to IS NULL OR effective_to > ?)", rule.EffectiveFrom, rule.EffectiveFrom).
+		Update("effective_to", rule.EffectiveFrom).Error
+	if err != nil {
+		return err
+	}
+
+	var next models.CommissionRule
+	err = r.sameScope(ctx, rule).
+		Where("effective_from > ?", rule.EffectiveFrom).
+		Order("effective_from ASC").
+		Limit(1).
+		Find(&next).Error
+	if err != nil {
+		return err
+	}
+	if next.ID != 0 {
+		rule.EffectiveTo = &next.EffectiveFrom
+	}
+	return nil
+}
+
+func (r *commissionRepository) sameScope(ctx context.Context, rule *models.CommissionRule) *gorm.DB {
+	query := r.DB(ctx).Where("category = ? AND min_completed_orders = ?", rule.Category, rule.MinCompletedOrders)
+	if rule.SellerID != nil {
+		return query.Where("seller_id = ?", *rule.SellerID)
+	}
+	return query.Where("seller_id IS NULL")
+}
+
+func (r *commissionRepository) Expire(ctx context.Context, id uint, at time.Time) error {
+	return r.DB(ctx).
+		Model(&models.CommissionRule{}).
+		Where("id = ? AND effective_to IS NULL", id).
+		Update("effective_to", at).Error
+}
@@ -0,0 +1,73 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/repository"
+)
+
+func TestCloseOverlappingVersions(t *testing.T) {
+	ctx, f, base := setup(t)
+	repo := repository.NewCommissionRepository(base)
+	day := 24 * time.Hour
+
+	current := f.CommissionRule().Category("overlap").Effective(f.Now().Add(-10*day), nil).Create()
+
+	add := func(from time.Time, rateBps int) *models.CommissionRule {
+		t.Helper()
+		rule := &models.CommissionRule{Category: "overlap", RateBps: rateBps, EffectiveFrom: from}
+		if err := repo.CloseOverlappingVersions(ctx, rule); err != nil {
+			t.Fatalf("CloseOverlappingVersions() error = %v", err)
+		}
+		if err := repo.Create(ctx, rule); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return rule
+	}
+
+	scheduled := add(f.Now().Add(10*day), 300)
+	// Dated between the current version and the scheduled one
+	inserted := add(f.Now().Add(5*day), 200)
+
+	reload := func(rule *models.CommissionRule) *models.CommissionRule {
+		t.Helper()
+		stored, err := repo.GetByID(ctx, rule.ID)
+		if err != nil {
+			t.Fatalf("GetByID(%d) error = %v", rule.ID, err)
+		}
+		return stored
+	}
+
+	if got := reload(current).EffectiveTo; got == nil || !got.Equal(inserted.EffectiveFrom) {
+		t.Errorf("current version ends at %v, want %v", got, inserted.EffectiveFrom)
+	}
+	if got := reload(inserted).EffectiveTo; got == nil || !got.Equal(scheduled.EffectiveFrom) {
+		t.Errorf("inserted version ends at %v, want %v", got, scheduled.EffectiveFrom)
+	}
+	if got := reload(scheduled).EffectiveTo; got != nil {
+		t.Errorf("scheduled version ends at %v, want open", got)
+	}
+
+	for offset, want := range map[time.Duration]uint{
+		0:        current.ID,
+		6 * day:  inserted.ID,
+		11 * day: scheduled.ID,
+	} {
+		rules, err := repo.GetApplicable(ctx, 0, "overlap", f.Now().Add(offset))
+		if err != nil {
+			t.Fatalf("GetApplicable() error = %v", err)
+		}
+
+		var ids []uint
+		for _, rule := range rules {
+			if rule.Category == "overlap" {
+				ids = append(ids, rule.ID)
+			}
+		}
+		if len(ids) != 1 || ids[0] != want {
+			t.Errorf("rules in effect %v from now = %v, want only %d", offset, ids, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm"
+)
+
+type IncidentRepository interface {
+	Create(ctx context.Context, incident *models.Incident) error
+	GetByID(ctx context.Context, id uint) (*models.Incident, error)
+	Update(ctx context.Context, incident *models.Incident) error
+	CreateUpdate(ctx context.Context, update *models.IncidentUpdate) error
+	ListActive(ctx context.Context) ([]*models.Incident, error)
+	ListSince(ctx context.Context, since time.Time, limit int) ([]*models.Incident, error)
+}
+
+type incidentRepository struct {
+	*medusarepo.Repository
+}
+
+func NewIncidentRepository(repo *medusarepo.Repository) IncidentRepository {
+	return &incidentRepository{Repository: repo}
+}
+
+func preloadIncidentUpdates(db *gorm.DB) *gorm.DB {
+	return db.Preload("Updates", func(db *gorm.DB) *gorm.DB { return db.Order("id DESC") })
+}
+
+// Create stores the incident with its first update
+func (r *incidentRepository) Create(ctx context.Context, incident *models.Incident) error {
+	return r.DB(ctx).Create(incident).Error
+}
+
+// GetByID returns the incident with its updates, newest first
+func (r *incidentRepository) GetByID(ctx context.Context, id uint) (*models.Incident, error) {
+	var incident models.Incident
+	if err := r.DB(ctx).Scopes(preloadIncidentUpdates).First(&incident, id).Error; err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// Update saves the incident columns only, updates are appended through
+// CreateUpdate
+func (r *incidentRepository) Update(ctx context.Context, incident *models.Incident) error {
+	return r.DB(ctx).Omit("Updates").Save(incident).Error
+}
+
+func (r *incidentRepository) CreateUpdate(ctx context.Context, update *models.IncidentUpdate) error {
+	return r.DB(ctx).Create(update).Error
+}
+
+func (r *incidentRepository) ListActive(ctx context.Context) ([]*models.Incident, error) {
+	var incidents []*models.Incident
+	err := r.DB(ctx).
+		Scopes(preloadIncidentUpdates).
+		Where("status <> ?", models.IncidentResolved).
+		Order("started_at DESC").
+		Find(&incidents).Error
+	if err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// ListSince returns the incidents active at some point since the given
+// time, newest first
+func (r *incidentRepository) ListSince(ctx context.Context, since time.Time, limit int) ([]*models.Incident, error) {
+	var incidents []*models.Incident
+	err := r.DB(ctx).
+		Scopes(preloadIncidentUpdates).
+		Where("resolved_at IS NULL OR resolved_at >= ?", since).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(&incidents).Error
+	if err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
@@ -0,0 +1,19 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imlargo/go-api/internal/testfactory"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+// setup returns a context running in a transaction rolled back after the
+// test, a factory writing in that transaction and the base repository
+func setup(t *testing.T) (context.Context, *testfactory.Factory, *medusarepo.Repository) {
+	t.Helper()
+
+	db := testfactory.OpenDB(t)
+	ctx, tx := testfactory.Transaction(t, db)
+	return ctx, testfactory.New(t, tx, 1), medusarepo.NewRepository(db, nil)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm/clause"
+)
+
+type SeedRepository interface {
+	Get(ctx context.Context, key string) (*models.SeedRecord, error)
+	Save(ctx context.Context, record *models.SeedRecord) error
+}
+
+type seedRepository struct {
+	*medusarepo.Repository
+}
+
+func NewSeedRepository(repo *medusarepo.Repository) SeedRepository {
+	return &seedRepository{Repository: repo}
+}
+
+func (r *seedRepository) Get(ctx context.Context, key string) (*models.SeedRecord, error) {
+	var record models.SeedRecord
+	if err := r.DB(ctx).Where("key = ?", key).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *seedRepository) Save(ctx context.Context, record *models.SeedRecord) error {
+	return r.DB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"checksum", "applied_at"}),
+	}).Create(record).Error
+}
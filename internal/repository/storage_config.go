@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type StorageConfigRepository interface {
+	Save(ctx context.Context, config *models.UserStorageConfig) error
+	GetByUserID(ctx context.Context, userID uint) (*models.UserStorageConfig, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+	ForEachEnabled(ctx context.Context, batchSize int, fn func(configs []*models.UserStorageConfig) error) error
+}
+
+type storageConfigRepository struct {
+	*medusarepo.Repository
+}
+
+func NewStorageConfigRepository(repo *medusarepo.Repository) StorageConfigRepository {
+	return &storageConfigRepository{Repository: repo}
+}
+
+func (r *storageConfigRepository) Save(ctx context.Context, config *models.UserStorageConfig) error {
+	return r.DB(ctx).Save(config).Error
+}
+
+func (r *storageConfigRepository) GetByUserID(ctx context.Context, userID uint) (*models.UserStorageConfig, error) {
+	var config models.UserStorageConfig
+	if err := r.DB(ctx).Where("user_id = ?", userID).First(&config).Error; err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *storageConfigRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.DB(ctx).Where("user_id = ?", userID).Delete(&models.UserStorageConfig{}).Error
+}
+
+func (r *storageConfigRepository) ForEachEnabled(ctx context.Context, batchSize int, fn func(configs []*models.UserStorageConfig) error) error {
+	return medusarepo.ForEachBatch(ctx, r.DB(ctx).Where("enabled = ?", true), batchSize, fn)
+}
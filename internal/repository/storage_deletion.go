@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type StorageDeletionRepository interface {
+	Create(ctx context.Context, deletion *models.StorageDeletion) error
+	Update(ctx context.Context, deletion *models.StorageDeletion) error
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*models.StorageDeletion, error)
+	ListPendingKeys(ctx context.Context, prefix string) ([]string, error)
+	CountByStatus(ctx context.Context) (map[models.StorageDeletionStatus]int64, error)
+}
+
+type storageDeletionRepository struct {
+	*medusarepo.Repository
+}
+
+func NewStorageDeletionRepository(repo *medusarepo.Repository) StorageDeletionRepository {
+	return &storageDeletionRepository{Repository: repo}
+}
+
+func (r *storageDeletionRepository) Create(ctx context.Context, deletion *models.StorageDeletion) error {
+	return r.DB(ctx).Create(deletion).Error
+}
+
+func (r *storageDeletionRepository) Update(ctx context.Context, deletion *models.StorageDeletion) error {
+	return r.DB(ctx).Save(deletion).Error
+}
+
+func (r *storageDeletionRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*models.StorageDeletion, error) {
+	var deletions []*models.StorageDeletion
+	err := r.DB(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.StorageDeletionPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deletions).Error
+	if err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+func (r *storageDeletionRepository) ListPendingKeys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := r.DB(ctx).Model(&models.StorageDeletion{}).
+		Where("status = ? AND object_key LIKE ?", models.StorageDeletionPending, prefix+"%").
+		Pluck("object_key", &keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *storageDeletionRepository) CountByStatus(ctx context.Context) (map[models.StorageDeletionStatus]int64, error) {
+	var rows []struct {
+		Status models.StorageDeletionStatus
+		Count  int64
+	}
+	err := r.DB(ctx).Model(&models.StorageDeletion{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.StorageDeletionStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
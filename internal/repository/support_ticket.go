@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/query"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm"
+)
+
+type SupportTicketRepository interface {
+	Create(ctx context.Context, ticket *models.SupportTicket) error
+	GetByID(ctx context.Context, id uint) (*models.SupportTicket, error)
+	Update(ctx context.Context, ticket *models.SupportTicket) error
+	ListByUser(ctx context.Context, userID uint) ([]*models.SupportTicket, error)
+	GetUpdatedSince(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]*models.SupportTicket, error)
+	List(ctx context.Context, filters *query.Query, limit int) ([]*models.SupportTicket, error)
+	CreateMessage(ctx context.Context, message *models.SupportTicketMessage) error
+	CreateAttachment(ctx context.Context, attachment *models.SupportTicketAttachment) error
+	GetFirstResponseBreaches(ctx context.Context, at time.Time, limit int) ([]*models.SupportTicket, error)
+	GetResolutionBreaches(ctx context.Context, at time.Time, limit int) ([]*models.SupportTicket, error)
+}
+
+type supportTicketRepository struct {
+	*medusarepo.Repository
+}
+
+func NewSupportTicketRepository(repo *medusarepo.Repository) SupportTicketRepository {
+	return &supportTicketRepository{Repository: repo}
+}
+
+func (r *supportTicketRepository) Create(ctx context.Context, ticket *models.SupportTicket) error {
+	return r.DB(ctx).Create(ticket).Error
+}
+
+// GetByID returns the ticket with its messages and attachments, oldest first
+func (r *supportTicketRepository) GetByID(ctx context.Context, id uint) (*models.SupportTicket, error) {
+	var ticket models.SupportTicket
+	err := r.DB(ctx).
+		Preload("Messages", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// Update saves the ticket columns only, the messages and attachments are
+// appended through their own methods
+func (r *supportTicketRepository) Update(ctx context.Context, ticket *models.SupportTicket) error {
+	return r.DB(ctx).Omit("Messages", "Attachments").Save(ticket).Error
+}
+
+func (r *supportTicketRepository) ListByUser(ctx context.Context, userID uint) ([]*models.SupportTicket, error) {
+	var tickets []*models.SupportTicket
+	if err := r.DB(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+// GetUpdatedSince returns the tickets of userID changed after the
+// (since, afterID) watermark, ordered so the last row is the next watermark.
+func (r *supportTicketRepository) GetUpdatedSince(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]*models.SupportTicket, error) {
+	var tickets []*models.SupportTicket
+	err := r.DB(ctx).
+		Where("user_id = ?", userID).
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", since, since, afterID).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *supportTicketRepository) List(ctx context.Context, filters *query.Query, limit int) ([]*models.SupportTicket, error) {
+	var tickets []*models.SupportTicket
+	err := r.ReadDB(ctx).
+		Scopes(filters.Scope).
+		Order("id ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *supportTicketRepository) CreateMessage(ctx context.Context, message *models.SupportTicketMessage) error {
+	return r.DB(ctx).Create(message).Error
+}
+
+func (r *supportTicketRepository) CreateAttachment(ctx context.Context, attachment *models.SupportTicketAttachment) error {
+	return r.DB(ctx).Create(attachment).Error
+}
+
+// GetFirstResponseBreaches returns the tickets nobody answered by their
+// first response deadline that weren't flagged yet
+func (r *supportTicketRepository) GetFirstResponseBreaches(ctx context.Context, at time.Time, limit int) ([]*models.SupportTicket, error) {
+	var tickets []*models.SupportTicket
+	err := r.DB(ctx).
+		Where("first_responded_at IS NULL AND first_response_breached_at IS NULL").
+		Where("first_response_due_at <= ?", at).
+		Where("status NOT IN ?", []models.SupportTicketStatus{models.SupportTicketResolved, models.SupportTicketClosed}).
+		Order("first_response_due_at ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+// GetResolutionBreaches returns the open tickets past their resolution
+// deadline that weren't flagged yet
+func (r *supportTicketRepository) GetResolutionBreaches(ctx context.Context, at time.Time, limit int) ([]*models.SupportTicket, error) {
+	var tickets []*models.SupportTicket
+	err := r.DB(ctx).
+		Where("resolved_at IS NULL AND resolution_breached_at IS NULL").
+		Where("resolution_due_at <= ?", at).
+		Where("status NOT IN ?", []models.SupportTicketStatus{models.SupportTicketResolved, models.SupportTicketClosed}).
+		Order("resolution_due_at ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
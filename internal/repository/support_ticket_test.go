@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/internal/repository"
+)
+
+func TestSupportTicketGetFirstResponseBreaches(t *testing.T) {
+	ctx, f, base := setup(t)
+	repo := repository.NewSupportTicketRepository(base)
+
+	overdue := f.SupportTicket().Due(f.Now().Add(-time.Hour), f.Now().Add(time.Hour)).Create()
+	f.SupportTicket().Due(f.Now().Add(time.Hour), f.Now().Add(2*time.Hour)).Create()
+	f.SupportTicket().Due(f.Now().Add(-time.Hour), f.Now().Add(time.Hour)).Status(models.SupportTicketResolved).Create()
+
+	tickets, err := repo.GetFirstResponseBreaches(ctx, f.Now(), 10)
+	if err != nil {
+		t.Fatalf("GetFirstResponseBreaches() error = %v", err)
+	}
+
+	var found bool
+	for _, ticket := range tickets {
+		if !ticket.IsOpen() || ticket.FirstResponseDueAt.After(f.Now()) {
+			t.Errorf("ticket %d is not breached: status %s, due at %v", ticket.ID, ticket.Status, ticket.FirstResponseDueAt)
+		}
+		found = found || ticket.ID == overdue.ID
+	}
+	if !found {
+		t.Errorf("overdue ticket %d was not returned", overdue.ID)
+	}
+}
+
+func TestSupportTicketGetUpdatedSince(t *testing.T) {
+	ctx, f, base := setup(t)
+	repo := repository.NewSupportTicketRepository(base)
+
+	user := f.User().Create()
+	first := f.SupportTicket().User(user).Create()
+	second := f.SupportTicket().User(user).Create()
+	f.SupportTicket().Create()
+
+	tickets, err := repo.GetUpdatedSince(ctx, user.ID, time.Time{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetUpdatedSince() error = %v", err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("GetUpdatedSince() returned %d tickets, want the 2 of the user", len(tickets))
+	}
+
+	ids := map[uint]bool{tickets[0].ID: true, tickets[1].ID: true}
+	if !ids[first.ID] || !ids[second.ID] {
+		t.Errorf("GetUpdatedSince() = %v, want tickets %d and %d", ids, first.ID, second.ID)
+	}
+
+	// The last row is the watermark of the next page
+	last := tickets[len(tickets)-1]
+	next, err := repo.GetUpdatedSince(ctx, user.ID, last.UpdatedAt, last.ID, 10)
+	if err != nil {
+		t.Fatalf("GetUpdatedSince() error = %v", err)
+	}
+	if len(next) != 0 {
+		t.Errorf("page after the watermark returned %d tickets, want none", len(next))
+	}
+}
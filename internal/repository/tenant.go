@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant) error
+	GetBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	List(ctx context.Context) ([]*models.Tenant, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type tenantRepository struct {
+	*medusarepo.Repository
+}
+
+func NewTenantRepository(repo *medusarepo.Repository) TenantRepository {
+	return &tenantRepository{Repository: repo}
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
+	return r.DB(ctx).Create(tenant).Error
+}
+
+func (r *tenantRepository) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.DB(ctx).Where("slug = ?", slug).First(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	if err := r.DB(ctx).Order("id ASC").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (r *tenantRepository) Delete(ctx context.Context, id uint) error {
+	return r.DB(ctx).Delete(&models.Tenant{}, id).Error
+}
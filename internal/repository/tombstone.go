@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type TombstoneRepository interface {
+	Create(ctx context.Context, tombstone *models.Tombstone) error
+	GetSince(ctx context.Context, entityType string, ownerID uint, since time.Time, afterID uint, limit int) ([]*models.Tombstone, error)
+}
+
+type tombstoneRepository struct {
+	*medusarepo.Repository
+}
+
+func NewTombstoneRepository(repo *medusarepo.Repository) TombstoneRepository {
+	return &tombstoneRepository{Repository: repo}
+}
+
+func (r *tombstoneRepository) Create(ctx context.Context, tombstone *models.Tombstone) error {
+	return r.DB(ctx).Create(tombstone).Error
+}
+
+func (r *tombstoneRepository) GetSince(ctx context.Context, entityType string, ownerID uint, since time.Time, afterID uint, limit int) ([]*models.Tombstone, error) {
+	var tombstones []*models.Tombstone
+	err := r.DB(ctx).
+		Where("entity_type = ? AND owner_id = ?", entityType, ownerID).
+		Where("created_at > ? OR (created_at = ? AND id > ?)", since, since, afterID).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&tombstones).Error
+	if err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
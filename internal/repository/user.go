@@ -2,13 +2,22 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/imlargo/go-api/internal/models"
 	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	GetByID(ctx context.Context, id uint) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	GetUpdatedSince(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]*models.User, error)
+	GetClaimsVersion(ctx context.Context, id uint) (int64, error)
+	BumpClaimsVersion(ctx context.Context, id uint) (int64, error)
+	SetSupportTier(ctx context.Context, id uint, tier models.SupportTier) error
 }
 
 type userRepository struct {
@@ -26,3 +35,69 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, er
 	}
 	return &user, nil
 }
+
+// GetByEmail matches emails case insensitively, providers don't agree on
+// the case of the address
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.DB(ctx).Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.DB(ctx).Create(user).Error
+}
+
+// GetUpdatedSince returns the records visible to userID changed after the
+// (since, afterID) watermark, ordered so the last row is the next watermark.
+func (r *userRepository) GetUpdatedSince(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := r.DB(ctx).
+		Where("id = ?", userID).
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", since, since, afterID).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) GetClaimsVersion(ctx context.Context, id uint) (int64, error) {
+	var user models.User
+	if err := r.DB(ctx).Select("id", "claims_version").First(&user, id).Error; err != nil {
+		return 0, err
+	}
+	return user.ClaimsVersion, nil
+}
+
+// BumpClaimsVersion increments the claims version without touching
+// updated_at, which would resend the user to sync clients for no change
+func (r *userRepository) BumpClaimsVersion(ctx context.Context, id uint) (int64, error) {
+	var user models.User
+	result := r.DB(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "claims_version"}}}).
+		Where("id = ?", id).
+		UpdateColumn("claims_version", gorm.Expr("claims_version + 1"))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return user.ClaimsVersion, nil
+}
+
+func (r *userRepository) SetSupportTier(ctx context.Context, id uint, tier models.SupportTier) error {
+	result := r.DB(ctx).Model(&models.User{}).Where("id = ?", id).Update("support_tier", tier)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imlargo/go-api/internal/models"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	UpdateEmail(ctx context.Context, id uint, email string) error
+}
+
+type userIdentityRepository struct {
+	*medusarepo.Repository
+}
+
+func NewUserIdentityRepository(repo *medusarepo.Repository) UserIdentityRepository {
+	return &userIdentityRepository{Repository: repo}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.DB(ctx).Create(identity).Error
+}
+
+func (r *userIdentityRepository) GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.DB(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) UpdateEmail(ctx context.Context, id uint, email string) error {
+	return r.DB(ctx).Model(&models.UserIdentity{}).Where("id = ?", id).Update("email", email).Error
+}
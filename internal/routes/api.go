@@ -0,0 +1,197 @@
+// Package routes registers the routes of the API and SSE servers. The mains
+// build the handlers and middleware; the route tables live here so tests and
+// the CLI can list them with gin's Routes() without starting a server.
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/internal/service"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// API holds the handlers and the middleware built from services of the API
+// server
+type API struct {
+	Activity         *handlers.ActivityHandler
+	AdminAction      *handlers.AdminActionHandler
+	ApiUsage         *handlers.ApiUsageHandler
+	AuditLog         *handlers.AuditLogHandler
+	Backup           *handlers.BackupHandler
+	Chat             *handlers.ChatHandler
+	Commission       *handlers.CommissionHandler
+	Database         *handlers.DatabaseHandler
+	Health           *handlers.HealthHandler
+	Lock             *handlers.LockHandler
+	OAuth            *handlers.OAuthHandler
+	Provider         *handlers.ProviderHandler
+	Schema           *handlers.SchemaHandler
+	Session          *handlers.SessionHandler
+	Status           *handlers.StatusHandler
+	StorageConfig    *handlers.StorageConfigHandler
+	StorageLifecycle *handlers.StorageLifecycleHandler
+	Support          *handlers.SupportHandler
+	Sync             *handlers.SyncHandler
+	Tenant           *handlers.TenantHandler
+	Webhook          *handlers.WebhookHandler
+
+	Audit       gin.HandlerFunc
+	Auth        gin.HandlerFunc
+	ErrorRate   gin.HandlerFunc
+	RateLimiter gin.HandlerFunc
+	TenantScope gin.HandlerFunc
+	Usage       gin.HandlerFunc
+}
+
+// RegisterAPI registers the routes of the API server on router
+func RegisterAPI(router *gin.Engine, cfg *config.Config, api *API) {
+	// Bulkheads keep the heavy analytics queries from taking every database
+	// connection from the rest of the API
+	apiBulkhead := middleware.NewBulkhead("api", cfg.Bulkheads.Api)
+	analyticsBulkhead := middleware.NewBulkhead("analytics", cfg.Bulkheads.Analytics)
+
+	// Error rates of the status page components
+	router.Use(api.ErrorRate)
+
+	router.Use(middleware.ReadConsistencyMiddleware())
+
+	internal := router.Group("/internal")
+	internal.Use(middleware.BearerApiKeyMiddleware(cfg.Admin.ApiKey))
+	internal.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	internal.GET("/db/stats", api.Database.Stats)
+	router.GET("/health/live", api.Health.Live)
+	router.GET("/health/ready", api.Health.Ready)
+	router.GET("/status", api.Status.Get)
+
+	// Signing in and refreshing happen without an access token. The tenant
+	// is resolved before authentication, the claims version of a user is
+	// read from the tenant schema.
+	authRoutes := router.Group("/auth", api.Audit)
+	if cfg.Tenancy.Enabled {
+		authRoutes.Use(api.TenantScope)
+	}
+	if cfg.RateLimiter.Enabled {
+		authRoutes.Use(api.RateLimiter)
+	}
+	authRoutes.POST("/refresh", api.Session.Refresh)
+	authRoutes.POST("/logout", api.Session.Logout)
+	authRoutes.GET("/oauth/:provider", api.OAuth.Start)
+	authRoutes.GET("/oauth/:provider/callback", api.OAuth.Callback)
+
+	v1 := router.Group("/api/v1", api.Audit)
+	if cfg.Tenancy.Enabled {
+		v1.Use(api.TenantScope)
+	}
+	v1.Use(api.Auth)
+	// Limited after authentication so the limit is per user
+	if cfg.RateLimiter.Enabled {
+		v1.Use(api.RateLimiter)
+	}
+	if cfg.ApiUsage.Enabled {
+		v1.Use(api.Usage)
+	}
+
+	v1Analytics := v1.Group("/usage", analyticsBulkhead.Middleware())
+	v1Analytics.GET("/api", api.ApiUsage.GetMyUsage)
+
+	v1Core := v1.Group("", apiBulkhead.Middleware())
+	v1Core.GET("/sync", api.Sync.Sync)
+
+	v1Core.GET("/providers/health", api.Provider.GetHealth)
+
+	v1Core.GET("/activity", api.Activity.List)
+
+	v1Core.GET("/chat/channels", api.Chat.ListMine)
+	v1Core.POST("/chat/channels", api.Chat.CreateMine)
+	v1Core.DELETE("/chat/channels/:id", api.Chat.DeleteMine)
+	v1Core.POST("/chat/channels/:id/test", api.Chat.TestMine)
+
+	v1Core.GET("/support/tickets", api.Support.ListMine)
+	v1Core.POST("/support/tickets", api.Support.CreateMine)
+	v1Core.GET("/support/tickets/:id", api.Support.GetMine)
+	v1Core.POST("/support/tickets/:id/messages", api.Support.ReplyMine)
+	v1Core.POST("/support/tickets/:id/close", api.Support.CloseMine)
+
+	// Tenants are managed outside any tenant schema
+	adminTenants := router.Group("/admin/tenants", api.Audit)
+	adminTenants.Use(middleware.BearerApiKeyMiddleware(cfg.Admin.ApiKey))
+
+	adminTenants.GET("", api.Tenant.List)
+	adminTenants.POST("", api.Tenant.Provision)
+	adminTenants.POST("/migrate", api.Tenant.Migrate)
+	adminTenants.DELETE("/:slug", api.Tenant.Deprovision)
+
+	// Incidents concern the whole platform, not a tenant
+	adminStatus := router.Group("/admin/status", api.Audit)
+	adminStatus.Use(middleware.BearerApiKeyMiddleware(cfg.Admin.ApiKey))
+
+	adminStatus.GET("/incidents", api.Status.ListIncidents)
+	adminStatus.POST("/incidents", api.Status.CreateIncident)
+	adminStatus.POST("/incidents/:id/updates", api.Status.PostIncidentUpdate)
+
+	admin := router.Group("/admin", api.Audit)
+	admin.Use(middleware.BearerApiKeyMiddleware(cfg.Admin.ApiKey))
+	if cfg.Tenancy.Enabled {
+		admin.Use(api.TenantScope)
+	}
+
+	adminAnalytics := admin.Group("/usage", analyticsBulkhead.Middleware())
+	adminAnalytics.GET("/api", api.ApiUsage.GetBreakdown)
+	adminAnalytics.GET("/api/top", api.ApiUsage.GetTopConsumers)
+
+	admin.GET("/commission/rules", api.Commission.ListRules)
+	admin.POST("/commission/rules", api.Commission.CreateRule)
+	admin.POST("/commission/rules/:id/expire", api.Commission.ExpireRule)
+	admin.GET("/commission/quote", api.Commission.Quote)
+
+	admin.GET("/chat/channels", api.Chat.ListOperations)
+	admin.POST("/chat/channels", api.Chat.CreateOperations)
+	admin.DELETE("/chat/channels/:id", api.Chat.DeleteOperations)
+	admin.POST("/chat/channels/:id/test", api.Chat.TestOperations)
+
+	admin.GET("/webhooks", api.Webhook.List)
+	admin.POST("/webhooks", api.Webhook.Create)
+	admin.PATCH("/webhooks/:id", api.Webhook.Update)
+	admin.DELETE("/webhooks/:id", api.Webhook.Delete)
+	admin.GET("/webhooks/:id/deliveries", api.Webhook.ListDeliveries)
+
+	admin.GET("/support/tickets", api.Support.ListQueue)
+	admin.GET("/support/tickets/:id", api.Support.Get)
+	admin.PATCH("/support/tickets/:id", api.Support.Update)
+
+	admin.GET("/storage/consistency", api.StorageLifecycle.GetStats)
+	admin.POST("/storage/orphans/:source/scan", api.StorageLifecycle.ScanOrphans)
+
+	admin.GET("/users/:id/storage", api.StorageConfig.Get)
+	admin.PUT("/users/:id/storage", api.StorageConfig.Set)
+	admin.DELETE("/users/:id/storage", api.StorageConfig.Remove)
+	admin.POST("/users/:id/storage/check", api.StorageConfig.Check)
+
+	admin.POST("/users/:id/sessions/revoke", api.Session.Revoke)
+	admin.PUT("/users/:id/support-tier", api.Support.SetUserTier)
+
+	admin.GET("/locks", api.Lock.List)
+	admin.GET("/locks/stats", api.Lock.GetStats)
+	admin.GET("/locks/:name", api.Lock.Get)
+	admin.DELETE("/locks/:name", api.Lock.ForceRelease)
+
+	admin.GET("/schema", api.Schema.Get)
+	admin.GET("/audit-logs", api.AuditLog.List)
+
+	admin.POST("/backups", api.Backup.Run)
+	admin.GET("/backups", api.Backup.List)
+	admin.GET("/backups/:id", api.Backup.Get)
+	admin.GET("/backups/:id/diff", api.Backup.Diff)
+
+	actions := router.Group("/admin/actions", api.Audit)
+	actions.Use(middleware.BearerApiKeyRolesMiddleware(map[string]string{
+		cfg.Admin.ApiKey:         string(service.AdminRoleAdmin),
+		cfg.Admin.OperatorApiKey: string(service.AdminRoleOperator),
+	}))
+
+	actions.GET("", api.AdminAction.List)
+	actions.GET("/executions", api.AdminAction.ListExecutions)
+	actions.POST("/:name", api.AdminAction.Execute)
+}
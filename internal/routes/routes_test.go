@@ -0,0 +1,52 @@
+package routes_test
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/apidocs"
+	"github.com/imlargo/go-api/internal/routes"
+	"github.com/swaggo/swag/gen"
+)
+
+// TestRoutesDocumented generates the spec the way make swag does and fails
+// when it drifts from the routes the API and SSE servers register
+func TestRoutesDocumented(t *testing.T) {
+	output := t.TempDir()
+	// From the module root, as make swag runs
+	t.Chdir("../..")
+	err := gen.New().Build(&gen.Config{
+		Debugger:        log.New(io.Discard, "", 0),
+		SearchDir:       "./cmd/api,./internal/handlers",
+		MainAPIFile:     "main.go",
+		OutputDir:       output,
+		OutputTypes:     []string{"json"},
+		ParseInternal:   true,
+		ParseVendor:     true,
+		ParseDependency: 1,
+		ParseDepth:      100,
+		ParseGoList:     true,
+	})
+	if err != nil {
+		t.Fatalf("swag could not generate the spec: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	report, err := apidocs.Verify(routes.Registered(), filepath.Join(output, "swagger.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range report.Undocumented {
+		t.Errorf("undocumented route %s", route)
+	}
+	for _, operation := range report.Stale {
+		t.Errorf("documented route is not registered: %s", operation)
+	}
+	if report.Routes == 0 {
+		t.Error("no routes served by the handlers were registered")
+	}
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/internal/config"
+	"github.com/imlargo/go-api/internal/handlers"
+	"github.com/imlargo/go-api/pkg/medusa/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// SSE holds the handlers of the SSE server
+type SSE struct {
+	SSE      *handlers.SSEHandler
+	Dispatch *handlers.NotificationDispatchHandler
+}
+
+// RegisterSSE registers the routes of the SSE server on router
+func RegisterSSE(router *gin.Engine, cfg *config.Config, sse *SSE) {
+	router.GET("/sse/listen", sse.SSE.Listen)
+	router.POST("/sse/publish", sse.SSE.Publish)
+	router.GET("/sse/events", sse.SSE.ListEvents)
+
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	admin := router.Group("/admin")
+	admin.Use(middleware.BearerApiKeyMiddleware(cfg.Admin.ApiKey))
+
+	admin.POST("/notifications/bulk", sse.Dispatch.Dispatch)
+	admin.GET("/notifications/bulk/:id", sse.Dispatch.Get)
+	admin.GET("/notifications/bulk/:id/results", sse.Dispatch.GetResults)
+}
+
+// Registered returns the routes of both servers without building their
+// handlers, nothing can be served from them
+func Registered() gin.RoutesInfo {
+	cfg := &config.Config{}
+
+	api := gin.New()
+	RegisterAPI(api, cfg, &API{})
+
+	sse := gin.New()
+	RegisterSSE(sse, cfg, &SSE{})
+
+	return append(api.Routes(), sse.Routes()...)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"go.uber.org/zap"
+)
+
+const (
+	activityDefaultLimit      = 20
+	activityMaxLimit          = 100
+	activityRetentionInterval = time.Hour
+)
+
+var (
+	ErrInvalidActivityCursor = apperrors.Validation("invalid activity cursor").WithCode("INVALID_ACTIVITY_CURSOR")
+	ErrUnknownActivityType   = apperrors.Validation("unknown activity type").WithCode("UNKNOWN_ACTIVITY_TYPE")
+)
+
+type ActivityService interface {
+	List(ctx context.Context, userID uint, types []string, cursor string, limit int) (*dto.ActivityFeedResponse, error)
+	PurgeExpired(ctx context.Context) (int64, error)
+	StartRetentionWorker(ctx context.Context)
+}
+
+type activityService struct {
+	*Service
+}
+
+func NewActivityService(container *Service) ActivityService {
+	return &activityService{
+		Service: container,
+	}
+}
+
+// List returns a page of the user's feed, newest first. The cursor is the
+// next_cursor of the previous page.
+func (s *activityService) List(ctx context.Context, userID uint, types []string, cursor string, limit int) (*dto.ActivityFeedResponse, error) {
+	var filter []models.ActivityType
+	for _, value := range types {
+		activityType := models.ActivityType(value)
+		if !slices.Contains(models.ActivityTypes, activityType) {
+			return nil, ErrUnknownActivityType
+		}
+		filter = append(filter, activityType)
+	}
+
+	var beforeID uint
+	if cursor != "" {
+		parsed, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil || parsed == 0 {
+			return nil, ErrInvalidActivityCursor
+		}
+		beforeID = uint(parsed)
+	}
+
+	if limit <= 0 {
+		limit = activityDefaultLimit
+	}
+	limit = min(limit, activityMaxLimit)
+
+	activities, err := s.store.ActivityRepository.List(ctx, userID, filter, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ActivityFeedResponse{Items: make([]*dto.ActivityItem, 0, len(activities))}
+	for _, activity := range activities {
+		response.Items = append(response.Items, &dto.ActivityItem{
+			ID:        activity.ID,
+			Type:      activity.Type,
+			CreatedAt: activity.CreatedAt,
+			Data:      json.RawMessage(activity.Data),
+		})
+	}
+
+	if len(activities) == limit {
+		response.NextCursor = strconv.FormatUint(uint64(activities[len(activities)-1].ID), 10)
+	}
+
+	return response, nil
+}
+
+// PurgeExpired deletes the activities older than the configured retention
+func (s *activityService) PurgeExpired(ctx context.Context) (int64, error) {
+	if s.config.Activity.Retention <= 0 {
+		return 0, nil
+	}
+	return s.store.ActivityRepository.DeleteOlderThan(ctx, s.Clock().Now().Add(-s.config.Activity.Retention))
+}
+
+func (s *activityService) StartRetentionWorker(ctx context.Context) {
+	if s.config.Activity.Retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(activityRetentionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "activity_retention", func(ctx context.Context) error {
+					deleted, err := s.PurgeExpired(ctx)
+					if deleted > 0 {
+						s.Logger().Info("purged expired activities", zap.Int64("deleted", deleted))
+					}
+					return err
+				})
+				if err != nil {
+					s.Logger().Error("activity retention failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// recordActivity adds an entry to the user's activity feed. Inside a
+// transaction it is written with the change it describes.
+func (s *Service) recordActivity(ctx context.Context, userID uint, activityType models.ActivityType, data map[string]any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return s.store.ActivityRepository.Create(ctx, &models.Activity{
+		UserID: userID,
+		Type:   activityType,
+		Data:   string(encoded),
+	})
+}
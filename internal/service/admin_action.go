@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/query"
+	"github.com/imlargo/go-api/pkg/medusa/core/ratelimiter"
+	"github.com/imlargo/go-api/pkg/medusa/services/lock"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+type AdminRole string
+
+const (
+	AdminRoleOperator AdminRole = "operator"
+	AdminRoleAdmin    AdminRole = "admin"
+)
+
+var adminRoleRank = map[AdminRole]int{
+	AdminRoleOperator: 1,
+	AdminRoleAdmin:    2,
+}
+
+// Allows reports whether the role is at least the required role
+func (r AdminRole) Allows(required AdminRole) bool {
+	return adminRoleRank[r] >= adminRoleRank[required]
+}
+
+var (
+	ErrAdminActionNotFound     = apperrors.NotFound("admin action").WithCode("ADMIN_ACTION_NOT_FOUND")
+	ErrAdminActionForbidden    = apperrors.Forbidden("role is not allowed to run this action").WithCode("ADMIN_ACTION_FORBIDDEN")
+	ErrAdminActionRateLimited  = apperrors.RateLimited("admin action rate limit exceeded, try again later").WithCode("ADMIN_ACTION_RATE_LIMITED")
+	ErrAdminActionInvalidParam = apperrors.Validation("invalid admin action params").WithCode("ADMIN_ACTION_INVALID_PARAMS")
+)
+
+// AdminActionParam documents a parameter accepted by an admin action
+type AdminActionParam struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// AdminAction is a safe, parameterized operation exposed through the admin
+// actions API. Run must not change anything when dryRun is set.
+type AdminAction struct {
+	Name         string
+	Description  string
+	RequiredRole AdminRole
+	Params       []AdminActionParam
+	RateLimit    ratelimiter.Config
+	Run          func(ctx context.Context, params map[string]string, dryRun bool) (any, error)
+}
+
+type AdminActionService interface {
+	Register(action AdminAction)
+	List(role AdminRole) []*dto.AdminActionInfo
+	Execute(ctx context.Context, actor string, role AdminRole, name string, params map[string]string, dryRun bool) (*models.AdminActionExecution, error)
+	ListExecutions(ctx context.Context, params url.Values, limit int) ([]*models.AdminActionExecution, error)
+}
+
+type adminActionService struct {
+	*Service
+	actions  map[string]AdminAction
+	limiters map[string]ratelimiter.RateLimiter
+}
+
+func NewAdminActionService(container *Service) AdminActionService {
+	return &adminActionService{
+		Service:  container,
+		actions:  make(map[string]AdminAction),
+		limiters: make(map[string]ratelimiter.RateLimiter),
+	}
+}
+
+// Register adds an action to the registry. It is meant to be called during
+// startup, before the API starts serving.
+func (s *adminActionService) Register(action AdminAction) {
+	s.actions[action.Name] = action
+	s.limiters[action.Name] = ratelimiter.NewTokenBucketLimiter(action.RateLimit)
+}
+
+func (s *adminActionService) List(role AdminRole) []*dto.AdminActionInfo {
+	infos := make([]*dto.AdminActionInfo, 0, len(s.actions))
+	for _, action := range s.actions {
+		if !role.Allows(action.RequiredRole) {
+			continue
+		}
+
+		info := &dto.AdminActionInfo{
+			Name:         action.Name,
+			Description:  action.Description,
+			RequiredRole: string(action.RequiredRole),
+			Params:       make([]dto.AdminActionParamInfo, 0, len(action.Params)),
+			RateLimit:    fmt.Sprintf("%d per %s", action.RateLimit.RequestsPerTimeFrame, action.RateLimit.TimeFrame),
+		}
+		for _, param := range action.Params {
+			info.Params = append(info.Params, dto.AdminActionParamInfo{
+				Name:        param.Name,
+				Description: param.Description,
+				Required:    param.Required,
+			})
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Execute runs an action and records the attempt. Rejected attempts are
+// audited too, so the log shows who tried what. Dry runs do not consume the
+// action's rate limit.
+func (s *adminActionService) Execute(ctx context.Context, actor string, role AdminRole, name string, params map[string]string, dryRun bool) (*models.AdminActionExecution, error) {
+	action, ok := s.actions[name]
+	if !ok {
+		return nil, ErrAdminActionNotFound
+	}
+
+	paramsJSON, _ := json.Marshal(params)
+	execution := &models.AdminActionExecution{
+		Action: name,
+		Actor:  actor,
+		Role:   string(role),
+		Params: string(paramsJSON),
+		DryRun: dryRun,
+	}
+
+	if err := s.authorize(action, role, params, dryRun); err != nil {
+		execution.Status = models.AdminActionStatusRejected
+		execution.Error = err.Error()
+		return execution, s.audit(ctx, execution, err)
+	}
+
+	start := time.Now()
+	result, err := action.Run(ctx, params, dryRun)
+	execution.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		execution.Status = models.AdminActionStatusFailed
+		execution.Error = err.Error()
+	} else {
+		execution.Status = models.AdminActionStatusSucceeded
+		resultJSON, _ := json.Marshal(result)
+		execution.Result = string(resultJSON)
+	}
+
+	return execution, s.audit(ctx, execution, err)
+}
+
+// adminActionExecutionQuery are the filters and sorts of the audit log
+var adminActionExecutionQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"action":      {Column: "action", Operators: []query.Operator{query.Eq, query.In}},
+		"actor":       {Column: "actor"},
+		"role":        {Column: "role"},
+		"status":      {Column: "status", Operators: []query.Operator{query.Eq, query.Ne, query.In}},
+		"dry_run":     {Column: "dry_run", Type: query.Bool},
+		"created_at":  {Column: "created_at", Type: query.Time, Operators: []query.Operator{query.Gte, query.Lt}, Sortable: true},
+		"duration_ms": {Column: "duration_ms", Type: query.Int, Operators: []query.Operator{query.Gte, query.Lte}, Sortable: true},
+	},
+	DefaultSort: "-created_at",
+}
+
+// ListExecutions returns the audit log filtered and sorted by params, see
+// adminActionExecutionQuery
+func (s *adminActionService) ListExecutions(ctx context.Context, params url.Values, limit int) ([]*models.AdminActionExecution, error) {
+	filters, err := adminActionExecutionQuery.Parse(params)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.AdminActionRepository.List(ctx, filters, limit)
+}
+
+func (s *adminActionService) authorize(action AdminAction, role AdminRole, params map[string]string, dryRun bool) error {
+	if !role.Allows(action.RequiredRole) {
+		return ErrAdminActionForbidden
+	}
+
+	known := make(map[string]bool, len(action.Params))
+	for _, param := range action.Params {
+		known[param.Name] = true
+		if param.Required && params[param.Name] == "" {
+			return fmt.Errorf("%w: %s is required", ErrAdminActionInvalidParam, param.Name)
+		}
+	}
+	for name := range params {
+		if !known[name] {
+			return fmt.Errorf("%w: unknown param %s", ErrAdminActionInvalidParam, name)
+		}
+	}
+
+	if !dryRun {
+		if allowed, _ := s.limiters[action.Name].Allow(action.Name); !allowed {
+			return ErrAdminActionRateLimited
+		}
+	}
+
+	return nil
+}
+
+// audit stores the execution and returns cause, so callers can return it
+// directly
+func (s *adminActionService) audit(ctx context.Context, execution *models.AdminActionExecution, cause error) error {
+	if err := s.store.AdminActionRepository.Create(ctx, execution); err != nil {
+		s.Logger().Error("failed to record admin action",
+			zap.String("action", execution.Action),
+			zap.String("actor", execution.Actor),
+			zap.Error(err),
+		)
+	}
+
+	s.Logger().Info("admin action executed",
+		zap.String("action", execution.Action),
+		zap.String("actor", execution.Actor),
+		zap.Bool("dry_run", execution.DryRun),
+		zap.String("status", string(execution.Status)),
+	)
+
+	return cause
+}
+
+// RegisterBuiltinAdminActions registers the operations support runs
+// routinely
+func RegisterBuiltinAdminActions(
+	actions AdminActionService,
+	redisClient *redis.Client,
+	lockManager lock.Manager,
+	apiUsageService ApiUsageService,
+	storageConfigService StorageConfigService,
+) {
+	actions.Register(AdminAction{
+		Name:         "clear_user_cache",
+		Description:  "Deletes every cached entry of a user (keys under user:<id>:)",
+		RequiredRole: AdminRoleOperator,
+		Params: []AdminActionParam{
+			{Name: "user_id", Description: "User id", Required: true},
+		},
+		RateLimit: ratelimiter.Config{RequestsPerTimeFrame: 20, TimeFrame: time.Minute},
+		Run: func(ctx context.Context, params map[string]string, dryRun bool) (any, error) {
+			userID, err := parseActionUint(params, "user_id")
+			if err != nil {
+				return nil, err
+			}
+
+			keys, err := scanKeys(ctx, redisClient, fmt.Sprintf("user:%d:*", userID))
+			if err != nil {
+				return nil, err
+			}
+			if dryRun || len(keys) == 0 {
+				return map[string]any{"keys": len(keys)}, nil
+			}
+
+			deleted, err := redisClient.Del(ctx, keys...).Result()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"keys": len(keys), "deleted": deleted}, nil
+		},
+	})
+
+	actions.Register(AdminAction{
+		Name:         "release_lock",
+		Description:  "Force-releases a stuck distributed lock",
+		RequiredRole: AdminRoleOperator,
+		Params: []AdminActionParam{
+			{Name: "name", Description: "Lock name", Required: true},
+		},
+		RateLimit: ratelimiter.Config{RequestsPerTimeFrame: 10, TimeFrame: time.Minute},
+		Run: func(ctx context.Context, params map[string]string, dryRun bool) (any, error) {
+			info, err := lockManager.Inspect(ctx, params["name"])
+			if errors.Is(err, lock.ErrLockNotFound) {
+				return map[string]any{"name": params["name"], "held": false}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if dryRun {
+				return info, nil
+			}
+
+			released, err := lockManager.ForceRelease(ctx, params["name"])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"lock": info, "released": released}, nil
+		},
+	})
+
+	actions.Register(AdminAction{
+		Name:         "refresh_api_usage",
+		Description:  "Rolls up the live API usage counters of a day into the analytics tables",
+		RequiredRole: AdminRoleOperator,
+		Params: []AdminActionParam{
+			{Name: "day", Description: "Day to roll up as YYYY-MM-DD, defaults to today (UTC)"},
+		},
+		RateLimit: ratelimiter.Config{RequestsPerTimeFrame: 5, TimeFrame: time.Minute},
+		Run: func(ctx context.Context, params map[string]string, dryRun bool) (any, error) {
+			day := time.Now().UTC()
+			if value := params["day"]; value != "" {
+				parsed, err := time.Parse(apiUsageDayFormat, value)
+				if err != nil {
+					return nil, fmt.Errorf("%w: day must be YYYY-MM-DD", ErrAdminActionInvalidParam)
+				}
+				day = parsed
+			}
+
+			if dryRun {
+				users, err := redisClient.SCard(ctx, apiUsageUsersKey(ctx, day.Format(apiUsageDayFormat))).Result()
+				if err != nil {
+					return nil, err
+				}
+				return map[string]any{"day": day.Format(apiUsageDayFormat), "users": users}, nil
+			}
+
+			if err := apiUsageService.Rollup(ctx, day); err != nil {
+				return nil, err
+			}
+			return map[string]any{"day": day.Format(apiUsageDayFormat)}, nil
+		},
+	})
+
+	actions.Register(AdminAction{
+		Name:         "check_customer_storage",
+		Description:  "Re-validates the credentials of a user's customer bucket",
+		RequiredRole: AdminRoleOperator,
+		Params: []AdminActionParam{
+			{Name: "user_id", Description: "User id", Required: true},
+		},
+		RateLimit: ratelimiter.Config{RequestsPerTimeFrame: 10, TimeFrame: time.Minute},
+		Run: func(ctx context.Context, params map[string]string, dryRun bool) (any, error) {
+			userID, err := parseActionUint(params, "user_id")
+			if err != nil {
+				return nil, err
+			}
+
+			if dryRun {
+				return storageConfigService.Get(ctx, userID)
+			}
+			return storageConfigService.Check(ctx, userID)
+		},
+	})
+}
+
+func parseActionUint(params map[string]string, name string) (uint, error) {
+	value, err := strconv.ParseUint(params[name], 10, 64)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive integer", ErrAdminActionInvalidParam, name)
+	}
+	return uint(value), nil
+}
+
+func scanKeys(ctx context.Context, redisClient *redis.Client, pattern string) ([]string, error) {
+	var keys []string
+	iter := redisClient.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/imlargo/go-api/pkg/medusa/services/cache"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	apiUsageKeyPrefix     = "api_usage"
+	apiUsageLastRollupKey = "api_usage_last_rollup"
+	apiUsageKeyTTL        = 72 * time.Hour
+	apiUsageDayFormat     = "2006-01-02"
+)
+
+type ApiUsageService interface {
+	Record(ctx context.Context, userID uint, method, route string, bytesIn, bytesOut int64) error
+	Rollup(ctx context.Context, day time.Time) error
+	StartRollupWorker(ctx context.Context)
+	GetUserUsage(ctx context.Context, userID uint, from, to time.Time) (*dto.ApiUsageResponse, error)
+	GetBreakdown(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageTotal, error)
+	GetTopConsumers(ctx context.Context, from, to time.Time, limit int) ([]*models.ApiUsageTotal, error)
+}
+
+// apiUsageHistoryCache is the warmed cache of the rolled up usage of a user
+// over the last apiUsageHistoryDays. Every user reads the same usage page,
+// so users are a single cohort.
+const (
+	apiUsageHistoryCache  = "api_usage_history"
+	apiUsageHistoryCohort = "users"
+	apiUsageHistoryDays   = 90
+)
+
+// apiUsageHistory is the cached daily usage of a user since From
+type apiUsageHistory struct {
+	From  time.Time               `json:"from"`
+	Daily []*models.ApiUsageDaily `json:"daily"`
+}
+
+type apiUsageService struct {
+	*Service
+	redis  *redis.Client
+	alerts ChatAlertService
+	warmer cache.Warmer
+}
+
+// NewApiUsageService caches the usage history of users in warmer, or reads
+// it from the database every time when warmer is nil
+func NewApiUsageService(container *Service, redisClient *redis.Client, alerts ChatAlertService, warmer cache.Warmer) ApiUsageService {
+	s := &apiUsageService{
+		Service: container,
+		redis:   redisClient,
+		alerts:  alerts,
+		warmer:  warmer,
+	}
+
+	if warmer != nil {
+		warmer.Register(apiUsageHistoryCache, container.config.CacheWarming.TTL, func(ctx context.Context, id string) (any, error) {
+			userID, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return s.loadHistory(ctx, uint(userID))
+		})
+	}
+	return s
+}
+
+// Record increments the live counters for the current UTC day
+func (s *apiUsageService) Record(ctx context.Context, userID uint, method, route string, bytesIn, bytesOut int64) error {
+	day := s.Clock().Now().UTC().Format(apiUsageDayFormat)
+	key := apiUsageUserKey(ctx, day, userID)
+	usersKey := apiUsageUsersKey(ctx, day)
+	field := method + "|" + route
+
+	pipe := s.redis.Pipeline()
+	pipe.HIncrBy(ctx, key, "requests|"+field, 1)
+	pipe.HIncrBy(ctx, key, "bytes_in|"+field, bytesIn)
+	pipe.HIncrBy(ctx, key, "bytes_out|"+field, bytesOut)
+	pipe.Expire(ctx, key, apiUsageKeyTTL)
+	pipe.SAdd(ctx, usersKey, userID)
+	pipe.Expire(ctx, usersKey, apiUsageKeyTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record api usage: %w", err)
+	}
+
+	return nil
+}
+
+// Rollup moves the live counters of a day into Postgres. Processed users are
+// removed from Redis, so running it twice for the same day is a no-op.
+func (s *apiUsageService) Rollup(ctx context.Context, day time.Time) error {
+	dayStr := day.UTC().Format(apiUsageDayFormat)
+	dayDate, _ := time.Parse(apiUsageDayFormat, dayStr)
+
+	members, err := s.redis.SMembers(ctx, apiUsageUsersKey(ctx, dayStr)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list api usage users: %w", err)
+	}
+
+	for _, member := range members {
+		userID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		key := apiUsageUserKey(ctx, dayStr, uint(userID))
+		counters, err := s.redis.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read api usage for user %d: %w", userID, err)
+		}
+
+		rows := countersToUsageRows(uint(userID), dayDate, counters)
+		if err := s.store.ApiUsageRepository.Upsert(ctx, rows); err != nil {
+			return fmt.Errorf("failed to persist api usage for user %d: %w", userID, err)
+		}
+
+		if err := s.redis.Del(ctx, key).Err(); err != nil {
+			return fmt.Errorf("failed to clear api usage for user %d: %w", userID, err)
+		}
+		s.redis.SRem(ctx, apiUsageUsersKey(ctx, dayStr), member)
+
+		if s.warmer != nil {
+			if err := s.warmer.Refresh(ctx, apiUsageHistoryCache, member); err != nil {
+				s.Logger().Warn("could not refresh the api usage history cache", zap.Uint64("user_id", userID), zap.Error(err))
+			}
+		}
+	}
+
+	s.alertTopConsumers(ctx, dayDate)
+
+	return nil
+}
+
+// StartRollupWorker rolls up the previous day shortly after every UTC midnight
+// until ctx is cancelled.
+func (s *apiUsageService) StartRollupWorker(ctx context.Context) {
+	go func() {
+		// Catch up on a rollup missed while the process was down
+		if err := s.rollupExclusive(ctx, s.Clock().Now().UTC().AddDate(0, 0, -1)); err != nil {
+			s.Logger().Error("api usage rollup failed", zap.Error(err))
+		}
+
+		for {
+			now := s.Clock().Now().UTC()
+			next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 5, 0, 0, time.UTC)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(next.Sub(now)):
+				if err := s.rollupExclusive(ctx, next.AddDate(0, 0, -1)); err != nil {
+					s.Logger().Error("api usage rollup failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// rollupExclusive keeps two instances from moving the same counters twice.
+// The last rolled up day is recorded, so restarts and instances waking up
+// after another one already rolled the day up do not alert again.
+func (s *apiUsageService) rollupExclusive(ctx context.Context, day time.Time) error {
+	dayStr := day.UTC().Format(apiUsageDayFormat)
+	lastKey := tenancy.Key(ctx, apiUsageLastRollupKey)
+
+	return s.runSingleton(ctx, "api_usage_rollup", func(ctx context.Context) error {
+		last, err := s.redis.Get(ctx, lastKey).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("failed to read last api usage rollup: %w", err)
+		}
+		// Days share one format, so they compare as strings
+		if last >= dayStr {
+			return nil
+		}
+
+		if err := s.Rollup(ctx, day); err != nil {
+			return err
+		}
+
+		if err := s.redis.Set(ctx, lastKey, dayStr, 0).Err(); err != nil {
+			return fmt.Errorf("failed to record api usage rollup: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *apiUsageService) GetUserUsage(ctx context.Context, userID uint, from, to time.Time) (*dto.ApiUsageResponse, error) {
+	daily, err := s.getDaily(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.Clock().Now().UTC().Format(apiUsageDayFormat)
+	todayDate, _ := time.Parse(apiUsageDayFormat, today)
+
+	counters, err := s.redis.HGetAll(ctx, apiUsageUserKey(ctx, today, userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live api usage: %w", err)
+	}
+
+	return &dto.ApiUsageResponse{
+		Daily: daily,
+		Today: countersToUsageRows(userID, todayDate, counters),
+	}, nil
+}
+
+// getDaily serves ranges inside the cached history from the cache. Older
+// ranges are rare and read from the database.
+func (s *apiUsageService) getDaily(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageDaily, error) {
+	if s.warmer == nil || from.Before(s.historyStart()) {
+		return s.store.ApiUsageRepository.GetDaily(ctx, userID, from, to)
+	}
+
+	var history apiUsageHistory
+	id := strconv.FormatUint(uint64(userID), 10)
+	if err := s.warmer.Get(ctx, apiUsageHistoryCache, apiUsageHistoryCohort, id, &history); err != nil {
+		return nil, err
+	}
+	// A value cached before midnight starts a day earlier, which still
+	// covers the range
+	if from.Before(history.From) {
+		return s.store.ApiUsageRepository.GetDaily(ctx, userID, from, to)
+	}
+
+	daily := make([]*models.ApiUsageDaily, 0, len(history.Daily))
+	for _, row := range history.Daily {
+		if !row.Day.Before(from) && !row.Day.After(to) {
+			daily = append(daily, row)
+		}
+	}
+	return daily, nil
+}
+
+func (s *apiUsageService) loadHistory(ctx context.Context, userID uint) (*apiUsageHistory, error) {
+	from := s.historyStart()
+	daily, err := s.store.ApiUsageRepository.GetDaily(ctx, userID, from, from.AddDate(0, 0, apiUsageHistoryDays))
+	if err != nil {
+		return nil, err
+	}
+	return &apiUsageHistory{From: from, Daily: daily}, nil
+}
+
+// historyStart is the first day of the cached history
+func (s *apiUsageService) historyStart() time.Time {
+	today, _ := time.Parse(apiUsageDayFormat, s.Clock().Now().UTC().Format(apiUsageDayFormat))
+	return today.AddDate(0, 0, -apiUsageHistoryDays)
+}
+
+func (s *apiUsageService) GetBreakdown(ctx context.Context, userID uint, from, to time.Time) ([]*models.ApiUsageTotal, error) {
+	return s.store.ApiUsageRepository.GetBreakdown(ctx, userID, from, to)
+}
+
+func (s *apiUsageService) GetTopConsumers(ctx context.Context, from, to time.Time, limit int) ([]*models.ApiUsageTotal, error) {
+	return s.store.ApiUsageRepository.GetTopConsumers(ctx, from, to, limit)
+}
+
+func (s *apiUsageService) alertTopConsumers(ctx context.Context, day time.Time) {
+	consumers, err := s.store.ApiUsageRepository.GetTopConsumers(ctx, day, day, s.config.ApiUsage.TopConsumers)
+	if err != nil {
+		s.Logger().Error("failed to load top api consumers", zap.Error(err))
+		return
+	}
+
+	for _, consumer := range consumers {
+		if consumer.Requests < s.config.ApiUsage.AlertThreshold {
+			continue
+		}
+
+		s.Logger().Warn("api usage threshold exceeded",
+			zap.Uint("user_id", consumer.UserID),
+			zap.String("day", day.Format(apiUsageDayFormat)),
+			zap.Int64("requests", consumer.Requests),
+			zap.Int64("bytes_out", consumer.BytesOut),
+		)
+		s.alerts.Notify(ctx, ChatAlertApiUsageThreshold, nil, map[string]string{
+			"user_id":   strconv.FormatUint(uint64(consumer.UserID), 10),
+			"day":       day.Format(apiUsageDayFormat),
+			"requests":  strconv.FormatInt(consumer.Requests, 10),
+			"threshold": strconv.FormatInt(s.config.ApiUsage.AlertThreshold, 10),
+		})
+	}
+}
+
+// countersToUsageRows groups the flat "<counter>|<method>|<route>" hash fields
+// into one row per route.
+func countersToUsageRows(userID uint, day time.Time, counters map[string]string) []*models.ApiUsageDaily {
+	byRoute := make(map[string]*models.ApiUsageDaily)
+	rows := make([]*models.ApiUsageDaily, 0)
+
+	for field, raw := range counters {
+		parts := strings.SplitN(field, "|", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		routeKey := parts[1] + "|" + parts[2]
+		row, exists := byRoute[routeKey]
+		if !exists {
+			row = &models.ApiUsageDaily{
+				UserID: userID,
+				Day:    day,
+				Method: parts[1],
+				Route:  parts[2],
+			}
+			byRoute[routeKey] = row
+			rows = append(rows, row)
+		}
+
+		switch parts[0] {
+		case "requests":
+			row.Requests = value
+		case "bytes_in":
+			row.BytesIn = value
+		case "bytes_out":
+			row.BytesOut = value
+		}
+	}
+
+	return rows
+}
+
+func apiUsageUserKey(ctx context.Context, day string, userID uint) string {
+	return fmt.Sprintf("%s:%s:%d", tenancy.Key(ctx, apiUsageKeyPrefix), day, userID)
+}
+
+func apiUsageUsersKey(ctx context.Context, day string) string {
+	return fmt.Sprintf("%s:%s:users", tenancy.Key(ctx, apiUsageKeyPrefix), day)
+}
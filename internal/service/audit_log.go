@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/audit"
+	"github.com/imlargo/go-api/pkg/medusa/core/query"
+	"go.uber.org/zap"
+)
+
+type AuditLogService interface {
+	RecordRequest(ctx context.Context, request *audit.Request) error
+	List(ctx context.Context, params url.Values, limit int) ([]*dto.AuditLogItem, error)
+}
+
+type auditLogService struct {
+	*Service
+}
+
+func NewAuditLogService(container *Service) AuditLogService {
+	return &auditLogService{
+		Service: container,
+	}
+}
+
+// auditLogQuery are the filters and sorts of the audit log
+var auditLogQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"user_id":     {Column: "user_id", Type: query.Int},
+		"actor":       {Column: "actor", Operators: []query.Operator{query.Eq, query.In}},
+		"entity_type": {Column: "entity_type", Operators: []query.Operator{query.Eq, query.In}},
+		"entity_id":   {Column: "entity_id"},
+		"action":      {Column: "action", Operators: []query.Operator{query.Eq, query.In}},
+		"method":      {Column: "method", Operators: []query.Operator{query.Eq, query.In}},
+		"status":      {Column: "status", Type: query.Int, Operators: []query.Operator{query.Eq, query.Gte, query.Lt}},
+		"request_id":  {Column: "request_id"},
+		"created_at":  {Column: "created_at", Type: query.Time, Operators: []query.Operator{query.Gte, query.Lt}, Sortable: true},
+	},
+	DefaultSort: "-created_at",
+}
+
+// RecordRequest stores an entry per write of request, or a single one when
+// it wrote nothing. Failures are logged, the request already happened.
+func (s *auditLogService) RecordRequest(ctx context.Context, request *audit.Request) error {
+	base := models.AuditLog{
+		Actor:     request.Actor,
+		UserID:    request.UserID,
+		Method:    request.Method,
+		Route:     request.Route,
+		Path:      request.Path,
+		Status:    request.Status,
+		IP:        request.IP,
+		RequestID: request.RequestID,
+	}
+
+	logs := make([]*models.AuditLog, 0, max(len(request.Entries), 1))
+	for _, entry := range request.Entries {
+		log := base
+		log.EntityType = entry.Entity
+		log.EntityID = entry.EntityID
+		log.Action = entry.Action
+		if len(entry.Changes) > 0 {
+			changes, err := json.Marshal(entry.Changes)
+			if err != nil {
+				return err
+			}
+			log.Changes = string(changes)
+		}
+		logs = append(logs, &log)
+	}
+	if len(logs) == 0 {
+		logs = append(logs, &base)
+	}
+
+	if err := s.store.AuditLogRepository.CreateMany(ctx, logs); err != nil {
+		s.Logger().WithContext(ctx).Error("failed to record audit log",
+			zap.String("actor", request.Actor),
+			zap.String("route", request.Method+" "+request.Route),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// List returns the audit log filtered and sorted by params, see
+// auditLogQuery
+func (s *auditLogService) List(ctx context.Context, params url.Values, limit int) ([]*dto.AuditLogItem, error) {
+	filters, err := auditLogQuery.Parse(params)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := s.store.AuditLogRepository.List(ctx, filters, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*dto.AuditLogItem, 0, len(logs))
+	for _, log := range logs {
+		item := &dto.AuditLogItem{
+			ID:         log.ID,
+			CreatedAt:  log.CreatedAt,
+			Actor:      log.Actor,
+			UserID:     log.UserID,
+			Method:     log.Method,
+			Route:      log.Route,
+			Path:       log.Path,
+			Status:     log.Status,
+			IP:         log.IP,
+			RequestID:  log.RequestID,
+			EntityType: log.EntityType,
+			EntityID:   log.EntityID,
+			Action:     log.Action,
+		}
+		if log.Changes != "" {
+			item.Changes = json.RawMessage(log.Changes)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+const backupArchiveVersion = 1
+
+// BackupTables are the critical tables included in every backup, in restore
+// order
+var BackupTables = []string{
+	"users",
+	"user_storage_configs",
+	"commission_rules",
+	"api_usage_dailies",
+}
+
+var (
+	ErrBackupEncryptionDisabled = apperrors.Validation("backups require ENCRYPTION_KEY to be configured").WithCode("BACKUP_ENCRYPTION_DISABLED")
+	ErrBackupNotCompleted       = apperrors.Validation("backup is not completed").WithCode("BACKUP_NOT_COMPLETED")
+	ErrBackupChecksumMismatch   = errors.New("backup archive checksum mismatch")
+)
+
+type BackupService interface {
+	Run(ctx context.Context, trigger string) (*models.BackupRecord, error)
+	List(ctx context.Context, limit int) ([]*models.BackupRecord, error)
+	Get(ctx context.Context, id uint) (*models.BackupRecord, error)
+	Restore(ctx context.Context, id uint, dryRun bool) (*dto.RestoreReport, error)
+	StartScheduler(ctx context.Context)
+}
+
+type backupArchive struct {
+	Version    int                         `json:"version"`
+	SnapshotAt time.Time                   `json:"snapshot_at"`
+	Tables     map[string][]map[string]any `json:"tables"`
+}
+
+type backupService struct {
+	*Service
+	fileStorage storage.FileStorage
+}
+
+func NewBackupService(container *Service, fileStorage storage.FileStorage) BackupService {
+	return &backupService{
+		Service:     container,
+		fileStorage: fileStorage,
+	}
+}
+
+// Run exports the critical tables from a single repeatable-read snapshot,
+// so the archive is consistent to one point in time, and uploads it
+// gzipped and encrypted.
+func (s *backupService) Run(ctx context.Context, trigger string) (*models.BackupRecord, error) {
+	encryptor := encryption.Default()
+	if encryptor == nil {
+		return nil, ErrBackupEncryptionDisabled
+	}
+
+	record := &models.BackupRecord{
+		Status:  models.BackupStatusRunning,
+		Trigger: trigger,
+		Tables:  strings.Join(BackupTables, ","),
+	}
+	if err := s.store.BackupRepository.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	archive := &backupArchive{
+		Version: backupArchiveVersion,
+		Tables:  make(map[string][]map[string]any, len(BackupTables)),
+	}
+
+	txOpts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := s.store.Transaction.WithTransactionOpts(ctx, txOpts, func(ctx context.Context) error {
+		archive.SnapshotAt = s.Clock().Now().UTC()
+		for _, table := range BackupTables {
+			var rows []map[string]any
+			if err := s.store.BaseRepo.DB(ctx).Table(table).Order("id ASC").Find(&rows).Error; err != nil {
+				return fmt.Errorf("failed to export %s: %w", table, err)
+			}
+			archive.Tables[table] = rows
+		}
+		return nil
+	})
+	if err != nil {
+		return s.fail(ctx, record, err)
+	}
+
+	payload, err := encodeBackupArchive(archive, encryptor)
+	if err != nil {
+		return s.fail(ctx, record, err)
+	}
+
+	checksum := sha256.Sum256(payload)
+	key := fmt.Sprintf("backups/%s-%d.bak", archive.SnapshotAt.Format("20060102T150405Z"), record.ID)
+
+	if _, err := s.fileStorage.Upload(key, bytes.NewReader(payload), "application/octet-stream", int64(len(payload))); err != nil {
+		return s.fail(ctx, record, err)
+	}
+
+	rowCounts := make(map[string]int, len(archive.Tables))
+	for table, rows := range archive.Tables {
+		rowCounts[table] = len(rows)
+	}
+	rowCountsJSON, _ := json.Marshal(rowCounts)
+
+	now := s.Clock().Now()
+	record.Status = models.BackupStatusCompleted
+	record.ObjectKey = key
+	record.SizeBytes = int64(len(payload))
+	record.Checksum = hex.EncodeToString(checksum[:])
+	record.RowCounts = string(rowCountsJSON)
+	record.SnapshotAt = archive.SnapshotAt
+	record.CompletedAt = &now
+
+	if err := s.store.BackupRepository.Update(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (s *backupService) List(ctx context.Context, limit int) ([]*models.BackupRecord, error) {
+	return s.store.BackupRepository.List(ctx, limit)
+}
+
+func (s *backupService) Get(ctx context.Context, id uint) (*models.BackupRecord, error) {
+	return s.store.BackupRepository.GetByID(ctx, id)
+}
+
+// Restore compares a backup with the current database. Unless dryRun is
+// set, rows from the backup are then upserted; rows only present in the
+// database are left untouched.
+func (s *backupService) Restore(ctx context.Context, id uint, dryRun bool) (*dto.RestoreReport, error) {
+	record, err := s.store.BackupRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != models.BackupStatusCompleted {
+		return nil, ErrBackupNotCompleted
+	}
+
+	encryptor := encryption.Default()
+	if encryptor == nil {
+		return nil, ErrBackupEncryptionDisabled
+	}
+
+	archive, err := s.download(record, encryptor)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.RestoreReport{
+		BackupID: record.ID,
+		DryRun:   dryRun,
+		Tables:   make(map[string]*dto.TableRestoreDiff, len(archive.Tables)),
+	}
+
+	err = s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, table := range BackupTables {
+			rows, ok := archive.Tables[table]
+			if !ok {
+				continue
+			}
+
+			diff, err := s.diffTable(ctx, table, rows)
+			if err != nil {
+				return err
+			}
+			report.Tables[table] = diff
+
+			if dryRun || len(rows) == 0 {
+				continue
+			}
+
+			if err := s.restoreTable(ctx, table, rows); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *backupService) StartScheduler(ctx context.Context) {
+	if s.config.Backup.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.config.Backup.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "backup", func(ctx context.Context) error {
+					// Instances tick at different times; the lock only covers
+					// overlapping runs, so also skip when another instance
+					// ran this interval
+					latest, err := s.store.BackupRepository.List(ctx, 1)
+					if err != nil {
+						return err
+					}
+					if len(latest) > 0 && time.Since(latest[0].CreatedAt) < s.config.Backup.Interval/2 {
+						return nil
+					}
+
+					record, err := s.Run(ctx, "schedule")
+					if err != nil {
+						return err
+					}
+					s.Logger().Info("scheduled backup completed", zap.Uint("backup_id", record.ID), zap.Int64("size_bytes", record.SizeBytes))
+					return nil
+				})
+				if err != nil {
+					s.Logger().Error("scheduled backup failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func (s *backupService) fail(ctx context.Context, record *models.BackupRecord, cause error) (*models.BackupRecord, error) {
+	record.Status = models.BackupStatusFailed
+	record.Error = cause.Error()
+	if err := s.store.BackupRepository.Update(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, cause
+}
+
+func (s *backupService) download(record *models.BackupRecord, encryptor *encryption.Encryptor) (*backupArchive, error) {
+	reader, err := s.fileStorage.Download(record.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup archive: %w", err)
+	}
+
+	checksum := sha256.Sum256(payload)
+	if hex.EncodeToString(checksum[:]) != record.Checksum {
+		return nil, ErrBackupChecksumMismatch
+	}
+
+	return decodeBackupArchive(payload, encryptor)
+}
+
+func (s *backupService) diffTable(ctx context.Context, table string, rows []map[string]any) (*dto.TableRestoreDiff, error) {
+	var current []map[string]any
+	if err := s.store.BaseRepo.DB(ctx).Table(table).Find(&current).Error; err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	currentByID := make(map[string]map[string]any, len(current))
+	for _, row := range current {
+		normalized, err := normalizeBackupRow(row)
+		if err != nil {
+			return nil, err
+		}
+		currentByID[fmt.Sprint(normalized["id"])] = normalized
+	}
+
+	diff := &dto.TableRestoreDiff{}
+	for _, row := range rows {
+		id := fmt.Sprint(row["id"])
+		existing, ok := currentByID[id]
+		switch {
+		case !ok:
+			diff.Insert++
+		case reflect.DeepEqual(existing, row):
+			diff.Unchanged++
+		default:
+			diff.Update++
+		}
+		delete(currentByID, id)
+	}
+	diff.OnlyInDatabase = len(currentByID)
+
+	return diff, nil
+}
+
+func (s *backupService) restoreTable(ctx context.Context, table string, rows []map[string]any) error {
+	var columns []string
+	for column := range rows[0] {
+		if column != "id" {
+			columns = append(columns, column)
+		}
+	}
+
+	db := s.store.BaseRepo.DB(ctx)
+	err := db.Table(table).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).CreateInBatches(rows, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", table, err)
+	}
+
+	// Keep the id sequence ahead of the restored rows
+	err = db.Exec(fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 1))",
+		table, table,
+	)).Error
+	if err != nil {
+		return fmt.Errorf("failed to reset %s sequence: %w", table, err)
+	}
+
+	return nil
+}
+
+func encodeBackupArchive(archive *backupArchive, encryptor *encryption.Encryptor) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(archive); err != nil {
+		return nil, fmt.Errorf("failed to encode backup archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress backup archive: %w", err)
+	}
+
+	encrypted, err := encryptor.Encrypt(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt backup archive: %w", err)
+	}
+
+	return []byte(encrypted), nil
+}
+
+func decodeBackupArchive(payload []byte, encryptor *encryption.Encryptor) (*backupArchive, error) {
+	compressed, err := encryptor.Decrypt(string(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup archive: %w", err)
+	}
+
+	gz, err := gzip.NewReader(strings.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	decoder := json.NewDecoder(gz)
+	decoder.UseNumber()
+
+	var archive backupArchive
+	if err := decoder.Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to decode backup archive: %w", err)
+	}
+
+	if archive.Version != backupArchiveVersion {
+		return nil, fmt.Errorf("unsupported backup archive version %d", archive.Version)
+	}
+
+	return &archive, nil
+}
+
+// normalizeBackupRow round-trips a database row through JSON so it compares
+// equal to the same row read back from an archive
+func normalizeBackupRow(row map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var normalized map[string]any
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/services/cache"
+	"go.uber.org/zap"
+)
+
+type CacheWarmingService interface {
+	StartWarmingWorker(ctx context.Context)
+}
+
+type cacheWarmingService struct {
+	*Service
+	warmer cache.Warmer
+}
+
+func NewCacheWarmingService(container *Service, warmer cache.Warmer) CacheWarmingService {
+	return &cacheWarmingService{
+		Service: container,
+		warmer:  warmer,
+	}
+}
+
+// StartWarmingWorker recomputes the hot cached keys before they expire
+func (s *cacheWarmingService) StartWarmingWorker(ctx context.Context) {
+	if !s.config.CacheWarming.Enabled {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.config.CacheWarming.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "cache_warming", func(ctx context.Context) error {
+					warmed, err := s.warmer.WarmExpiring(ctx)
+					if warmed > 0 {
+						s.Logger().Debug("warmed cache keys", zap.Int("warmed", warmed))
+					}
+					return err
+				})
+				if err != nil {
+					s.Logger().Error("cache warming failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
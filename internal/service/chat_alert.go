@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"text/template"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/services/chat"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ChatAlertEvent string
+
+const (
+	ChatAlertApiUsageThreshold    ChatAlertEvent = "api_usage.threshold_exceeded"
+	ChatAlertSupportSLABreached   ChatAlertEvent = "support.sla_breached"
+	ChatAlertSupportTicketUpdated ChatAlertEvent = "support.ticket_updated"
+	ChatAlertTest                 ChatAlertEvent = "test"
+)
+
+var (
+	ErrChatEncryptionDisabled = apperrors.Validation("chat channels require ENCRYPTION_KEY to be configured").WithCode("CHAT_ENCRYPTION_DISABLED")
+	ErrChatUnknownEvent       = apperrors.Validation("unknown chat alert event").WithCode("UNKNOWN_CHAT_EVENT")
+)
+
+type chatAlertTemplate struct {
+	level chat.Level
+	title *template.Template
+	text  *template.Template
+
+	// operations events only go to channels without a user
+	operations bool
+}
+
+func newChatAlertTemplate(level chat.Level, operations bool, title, text string) chatAlertTemplate {
+	return chatAlertTemplate{
+		level:      level,
+		title:      template.Must(template.New("title").Option("missingkey=zero").Parse(title)),
+		text:       template.Must(template.New("text").Option("missingkey=zero").Parse(text)),
+		operations: operations,
+	}
+}
+
+// chatAlertTemplates render each event from the vars passed to Notify. Vars
+// are also listed as fields under the message.
+var chatAlertTemplates = map[ChatAlertEvent]chatAlertTemplate{
+	ChatAlertApiUsageThreshold: newChatAlertTemplate(chat.LevelWarning, true,
+		"API usage threshold exceeded by user {{.user_id}}",
+		"User {{.user_id}} made {{.requests}} requests on {{.day}}, over the alert threshold of {{.threshold}}.",
+	),
+	ChatAlertSupportSLABreached: newChatAlertTemplate(chat.LevelCritical, true,
+		"Support ticket #{{.ticket_id}} missed its {{.sla}} SLA",
+		"The {{.tier}} ticket \"{{.subject}}\" was due by {{.due_at}}.{{if .assigned_to}} It is assigned to {{.assigned_to}}.{{else}} Nobody is assigned to it.{{end}}",
+	),
+	ChatAlertSupportTicketUpdated: newChatAlertTemplate(chat.LevelInfo, false,
+		"Support ticket #{{.ticket_id}} was updated",
+		"Your ticket \"{{.subject}}\" is now {{.status}}.{{if .message}} Support replied: {{.message}}{{end}}",
+	),
+	ChatAlertTest: newChatAlertTemplate(chat.LevelInfo, false,
+		"Test message",
+		"The channel {{.channel}} is set up to receive alerts.",
+	),
+}
+
+type ChatAlertService interface {
+	ListChannels(ctx context.Context, userID *uint) ([]*models.ChatChannel, error)
+	CreateChannel(ctx context.Context, userID *uint, req *dto.CreateChatChannelRequest) (*models.ChatChannel, error)
+	DeleteChannel(ctx context.Context, userID *uint, channelID uint) error
+	TestChannel(ctx context.Context, userID *uint, channelID uint) error
+	Notify(ctx context.Context, event ChatAlertEvent, userID *uint, vars map[string]string)
+}
+
+type chatAlertService struct {
+	*Service
+	notifier chat.Notifier
+}
+
+func NewChatAlertService(container *Service, notifier chat.Notifier) ChatAlertService {
+	return &chatAlertService{
+		Service:  container,
+		notifier: notifier,
+	}
+}
+
+// ListChannels returns the channels of a user, or the operations channels
+// when userID is nil
+func (s *chatAlertService) ListChannels(ctx context.Context, userID *uint) ([]*models.ChatChannel, error) {
+	return s.store.ChatChannelRepository.ListByOwner(ctx, userID)
+}
+
+func (s *chatAlertService) CreateChannel(ctx context.Context, userID *uint, req *dto.CreateChatChannelRequest) (*models.ChatChannel, error) {
+	if encryption.Default() == nil {
+		return nil, ErrChatEncryptionDisabled
+	}
+
+	provider := chat.Provider(req.Provider)
+	if err := chat.ValidateWebhookURL(provider, req.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	for _, event := range req.Events {
+		alert, exists := chatAlertTemplates[ChatAlertEvent(event)]
+		if !exists || event == string(ChatAlertTest) || (alert.operations && userID != nil) {
+			return nil, fmt.Errorf("%w: %s", ErrChatUnknownEvent, event)
+		}
+	}
+
+	channel := &models.ChatChannel{
+		UserID:     userID,
+		Name:       req.Name,
+		Provider:   req.Provider,
+		WebhookURL: encryption.EncryptedString(req.WebhookURL),
+		Events:     req.Events,
+		Enabled:    true,
+	}
+	if err := s.store.ChatChannelRepository.Create(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (s *chatAlertService) DeleteChannel(ctx context.Context, userID *uint, channelID uint) error {
+	if _, err := s.ownedChannel(ctx, userID, channelID); err != nil {
+		return err
+	}
+	return s.store.ChatChannelRepository.Delete(ctx, channelID)
+}
+
+// TestChannel sends a test message right away and returns the webhook error,
+// so a broken URL shows up when the channel is set up
+func (s *chatAlertService) TestChannel(ctx context.Context, userID *uint, channelID uint) error {
+	channel, err := s.ownedChannel(ctx, userID, channelID)
+	if err != nil {
+		return err
+	}
+
+	message, err := renderChatAlert(ChatAlertTest, map[string]string{"channel": channel.Name})
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, channel, message)
+}
+
+// Notify sends event to the operations channels and to the channels of
+// userID subscribed to it. Delivery happens in the background and failures
+// are only logged, alerts never fail the action that raised them.
+func (s *chatAlertService) Notify(ctx context.Context, event ChatAlertEvent, userID *uint, vars map[string]string) {
+	message, err := renderChatAlert(event, vars)
+	if err != nil {
+		s.Logger().Error("could not render chat alert", zap.String("event", string(event)), zap.Error(err))
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		owners := []*uint{nil}
+		if userID != nil && !chatAlertTemplates[event].operations {
+			owners = append(owners, userID)
+		}
+
+		for _, owner := range owners {
+			channels, err := s.store.ChatChannelRepository.ListByOwner(ctx, owner)
+			if err != nil {
+				s.Logger().Error("could not load chat channels", zap.String("event", string(event)), zap.Error(err))
+				continue
+			}
+
+			for _, channel := range channels {
+				if !channel.Enabled || !slices.Contains(channel.Events, string(event)) {
+					continue
+				}
+				if err := s.send(ctx, channel, message); err != nil {
+					s.Logger().Warn("chat alert not delivered",
+						zap.Uint("channel_id", channel.ID),
+						zap.String("event", string(event)),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	}()
+}
+
+func (s *chatAlertService) send(ctx context.Context, channel *models.ChatChannel, message *chat.Message) error {
+	err := s.notifier.Send(ctx, chat.Destination{
+		Key:      fmt.Sprintf("chat_channel:%d", channel.ID),
+		Provider: chat.Provider(channel.Provider),
+		URL:      string(channel.WebhookURL),
+	}, message)
+
+	// Dropped messages say nothing about the health of the webhook
+	if errors.Is(err, chat.ErrRateLimited) {
+		return err
+	}
+
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	if recordErr := s.store.ChatChannelRepository.RecordDelivery(ctx, channel.ID, s.Clock().Now(), lastError); recordErr != nil {
+		s.Logger().Warn("could not record chat delivery", zap.Uint("channel_id", channel.ID), zap.Error(recordErr))
+	}
+	return err
+}
+
+// ownedChannel loads a channel, reporting channels of another owner as not
+// found
+func (s *chatAlertService) ownedChannel(ctx context.Context, userID *uint, channelID uint) (*models.ChatChannel, error) {
+	channel, err := s.store.ChatChannelRepository.GetByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	sameOwner := (channel.UserID == nil && userID == nil) ||
+		(channel.UserID != nil && userID != nil && *channel.UserID == *userID)
+	if !sameOwner {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return channel, nil
+}
+
+func renderChatAlert(event ChatAlertEvent, vars map[string]string) (*chat.Message, error) {
+	alert, exists := chatAlertTemplates[event]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrChatUnknownEvent, event)
+	}
+
+	var title, text bytes.Buffer
+	if err := alert.title.Execute(&title, vars); err != nil {
+		return nil, err
+	}
+	if err := alert.text.Execute(&text, vars); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]chat.Field, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, chat.Field{Name: name, Value: vars[name]})
+	}
+
+	return &chat.Message{
+		Title:  title.String(),
+		Text:   text.String(),
+		Level:  alert.level,
+		Fields: fields,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/money"
+)
+
+var ErrCommissionRuleInPast = apperrors.Validation("commission rules cannot become effective in the past").WithCode("COMMISSION_RULE_IN_PAST")
+
+type CommissionService interface {
+	CreateRule(ctx context.Context, payload *dto.CreateCommissionRuleRequest) (*models.CommissionRule, error)
+	ListRules(ctx context.Context, activeOnly bool) ([]*models.CommissionRule, error)
+	ExpireRule(ctx context.Context, ruleID uint) error
+	Quote(ctx context.Context, sellerID uint, category string, completedOrders int, grossAmount money.Money, at time.Time) (*dto.CommissionQuote, error)
+}
+
+type commissionService struct {
+	*Service
+}
+
+func NewCommissionService(container *Service) CommissionService {
+	return &commissionService{
+		Service: container,
+	}
+}
+
+// CreateRule adds a new rule version and closes the previous version of the
+// same scope, so quotes for earlier dates keep resolving to the old rate. A
+// rule dated before a version already scheduled ends when that one starts.
+func (s *commissionService) CreateRule(ctx context.Context, payload *dto.CreateCommissionRuleRequest) (*models.CommissionRule, error) {
+	now := s.Clock().Now()
+
+	effectiveFrom := now
+	if payload.EffectiveFrom != nil {
+		if payload.EffectiveFrom.Before(now.Add(-time.Minute)) {
+			return nil, ErrCommissionRuleInPast
+		}
+		effectiveFrom = *payload.EffectiveFrom
+	}
+
+	rule := &models.CommissionRule{
+		Category:           payload.Category,
+		SellerID:           payload.SellerID,
+		MinCompletedOrders: payload.MinCompletedOrders,
+		RateBps:            payload.RateBps,
+		EffectiveFrom:      effectiveFrom,
+	}
+
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.store.CommissionRepository.CloseOverlappingVersions(ctx, rule); err != nil {
+			return err
+		}
+		return s.store.CommissionRepository.Create(ctx, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *commissionService) ListRules(ctx context.Context, activeOnly bool) ([]*models.CommissionRule, error) {
+	if activeOnly {
+		now := s.Clock().Now()
+		return s.store.CommissionRepository.List(ctx, &now)
+	}
+	return s.store.CommissionRepository.List(ctx, nil)
+}
+
+func (s *commissionService) ExpireRule(ctx context.Context, ruleID uint) error {
+	if _, err := s.store.CommissionRepository.GetByID(ctx, ruleID); err != nil {
+		return err
+	}
+
+	return s.store.CommissionRepository.Expire(ctx, ruleID, s.Clock().Now())
+}
+
+// Quote resolves the rate that applied to a sale at the given time. Seller
+// overrides win over category defaults, which win over global defaults;
+// within the same scope the highest volume tier reached wins. Commission is
+// rounded half up to the minor unit, so the platform and the seller never
+// disagree by a cent on the split.
+func (s *commissionService) Quote(ctx context.Context, sellerID uint, category string, completedOrders int, grossAmount money.Money, at time.Time) (*dto.CommissionQuote, error) {
+	rules, err := s.store.CommissionRepository.GetApplicable(ctx, sellerID, category, at)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &dto.CommissionQuote{
+		RateBps:     s.config.Commission.DefaultRateBps,
+		GrossAmount: grossAmount,
+	}
+
+	var best *models.CommissionRule
+	for _, rule := range rules {
+		if rule.MinCompletedOrders > completedOrders {
+			continue
+		}
+		if best == nil || commissionRuleBeats(rule, best) {
+			best = rule
+		}
+	}
+
+	if best != nil {
+		quote.RuleID = &best.ID
+		quote.RateBps = best.RateBps
+	}
+
+	quote.Commission, err = grossAmount.MulBps(int64(quote.RateBps), money.RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.NetAmount, err = grossAmount.Sub(quote.Commission)
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func commissionRuleSpecificity(rule *models.CommissionRule) int {
+	specificity := 0
+	if rule.SellerID != nil {
+		specificity += 2
+	}
+	if rule.Category != "" {
+		specificity++
+	}
+	return specificity
+}
+
+func commissionRuleBeats(candidate, current *models.CommissionRule) bool {
+	if a, b := commissionRuleSpecificity(candidate), commissionRuleSpecificity(current); a != b {
+		return a > b
+	}
+	if candidate.MinCompletedOrders != current.MinCompletedOrders {
+		return candidate.MinCompletedOrders > current.MinCompletedOrders
+	}
+	return candidate.EffectiveFrom.After(current.EffectiveFrom)
+}
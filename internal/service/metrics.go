@@ -0,0 +1,26 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "job_duration_seconds",
+		Help:    "Duration of background job runs by job and status: ok or error",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 15, 30, 60, 300},
+	}, []string{"job", "status"})
+
+	// Attempts by result: delivered, retry or failed. Retries over all
+	// attempts is the retry ratio of the queue.
+	webhookDeliveryAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_delivery_attempts_total",
+		Help: "Webhook delivery attempts by result",
+	}, []string{"result"})
+
+	webhookDeliveriesDue = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "webhook_deliveries_due",
+		Help: "Webhook deliveries due in the last batch of the delivery worker",
+	})
+)
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/auth"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const oauthStateKeyPrefix = "oauth_state"
+
+// OAuthStateTTL is how long a user has to get through the consent page
+const OAuthStateTTL = 10 * time.Minute
+
+var (
+	ErrOAuthProviderNotFound = apperrors.NotFound("oauth provider").WithCode("OAUTH_PROVIDER_NOT_FOUND")
+	ErrInvalidOAuthState     = apperrors.Unauthorized("sign in expired or was started elsewhere, try again").WithCode("INVALID_OAUTH_STATE")
+	ErrOAuthExchangeFailed   = apperrors.Unauthorized("the provider could not confirm the sign in").WithCode("OAUTH_EXCHANGE_FAILED")
+	ErrOAuthEmailUnverified  = apperrors.Forbidden("the email of this account is not verified by the provider").WithCode("OAUTH_EMAIL_UNVERIFIED")
+)
+
+type OAuthService interface {
+	AuthURL(ctx context.Context, providerName string) (authURL, state string, err error)
+	Callback(ctx context.Context, providerName, state, browserState, code string) (*dto.TokenPairResponse, error)
+}
+
+type oauthService struct {
+	*Service
+	redis     *redis.Client
+	sessions  SessionService
+	providers map[string]auth.Provider
+}
+
+// oauthState is what a sign in must come back with. The tenant is kept so
+// a consent started on one tenant can't sign in to another.
+type oauthState struct {
+	Provider string `json:"provider"`
+	Tenant   string `json:"tenant"`
+}
+
+func NewOAuthService(container *Service, redisClient *redis.Client, sessions SessionService, providers ...auth.Provider) OAuthService {
+	byName := make(map[string]auth.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &oauthService{
+		Service:   container,
+		redis:     redisClient,
+		sessions:  sessions,
+		providers: byName,
+	}
+}
+
+// AuthURL starts a sign in, returning the consent page of the provider and
+// the single use state it carries. The caller must keep the state in the
+// browser that started the sign in, Callback only accepts it from there.
+func (s *oauthService) AuthURL(ctx context.Context, providerName string) (string, string, error) {
+	provider, exists := s.providers[providerName]
+	if !exists {
+		return "", "", ErrOAuthProviderNotFound
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	state := hex.EncodeToString(raw)
+
+	value, err := json.Marshal(oauthState{Provider: providerName, Tenant: tenancy.Tenant(ctx)})
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.redis.Set(ctx, oauthStateKeyPrefix+":"+state, value, OAuthStateTTL).Err(); err != nil {
+		return "", "", fmt.Errorf("failed to store oauth state: %w", err)
+	}
+
+	return provider.AuthCodeURL(state), state, nil
+}
+
+// Callback finishes a sign in: it checks state, gets the identity from the
+// provider, finds or creates the user and issues their tokens. browserState
+// is the state kept by the browser at the start; requiring it stops an
+// attacker from signing a victim in to the attacker's account with a code
+// and state of their own.
+func (s *oauthService) Callback(ctx context.Context, providerName, state, browserState, code string) (*dto.TokenPairResponse, error) {
+	provider, exists := s.providers[providerName]
+	if !exists {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrInvalidOAuthState
+	}
+
+	value, err := s.redis.GetDel(ctx, oauthStateKeyPrefix+":"+state).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidOAuthState
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oauth state: %w", err)
+	}
+
+	var stored oauthState
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, ErrInvalidOAuthState
+	}
+	if stored.Provider != providerName || stored.Tenant != tenancy.Tenant(ctx) {
+		return nil, ErrInvalidOAuthState
+	}
+
+	identity, err := provider.Exchange(ctx, code)
+	if err != nil {
+		s.Logger().WithContext(ctx).Warn("oauth exchange failed", zap.String("provider", providerName), zap.Error(err))
+		return nil, ErrOAuthExchangeFailed.Wrap(err)
+	}
+
+	userID, err := s.linkUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sessions.IssueTokens(ctx, userID)
+}
+
+// linkUser returns the user of identity. New identities are linked to the
+// user with the same email, created if there is none, but only when the
+// provider verified the email: otherwise anyone could claim an account by
+// typing its address at the provider.
+func (s *oauthService) linkUser(ctx context.Context, identity *auth.Identity) (uint, error) {
+	var userID uint
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		linked, err := s.store.UserIdentityRepository.GetBySubject(ctx, identity.Provider, identity.Subject)
+		if err == nil {
+			userID = linked.UserID
+			if linked.Email != identity.Email {
+				return s.store.UserIdentityRepository.UpdateEmail(ctx, linked.ID, identity.Email)
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if !identity.EmailVerified {
+			return ErrOAuthEmailUnverified
+		}
+
+		user, err := s.store.UserRepository.GetByEmail(ctx, identity.Email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user = &models.User{Email: identity.Email}
+			err = s.store.UserRepository.Create(ctx, user)
+		}
+		if err != nil {
+			return err
+		}
+
+		userID = user.ID
+		return s.store.UserIdentityRepository.Create(ctx, &models.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SeedService interface {
+	LoadDefaults(path string) (*dto.SeedDefaults, error)
+	SeedDefaults(ctx context.Context, defaults *dto.SeedDefaults) ([]dto.SeedResult, error)
+}
+
+type seedService struct {
+	*Service
+}
+
+func NewSeedService(container *Service) SeedService {
+	return &seedService{
+		Service: container,
+	}
+}
+
+func (s *seedService) LoadDefaults(path string) (*dto.SeedDefaults, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed defaults: %w", err)
+	}
+
+	var defaults dto.SeedDefaults
+	if err := json.Unmarshal(content, &defaults); err != nil {
+		return nil, fmt.Errorf("invalid seed defaults %s: %w", path, err)
+	}
+	return &defaults, nil
+}
+
+// SeedDefaults writes the defaults that are missing or still as they were
+// last seeded. Rows edited since the last seed are kept as they are, which
+// is detected by comparing the row's checksum with the seeded one.
+func (s *seedService) SeedDefaults(ctx context.Context, defaults *dto.SeedDefaults) ([]dto.SeedResult, error) {
+	var results []dto.SeedResult
+
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, rule := range defaults.CommissionRules {
+			result, err := s.seedCommissionRule(ctx, rule)
+			if err != nil {
+				return err
+			}
+			results = append(results, *result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Outcome == dto.SeedOutcomeKept {
+			s.Logger().Info("seed default edited manually, keeping it", zap.String("key", result.Key))
+		}
+	}
+
+	return results, nil
+}
+
+func (s *seedService) seedCommissionRule(ctx context.Context, seed dto.SeedCommissionRule) (*dto.SeedResult, error) {
+	key := fmt.Sprintf("commission_rule:%s:%d", seed.Category, seed.MinCompletedOrders)
+	desired := seedChecksum(seed.RateBps)
+
+	var current *string
+	rule, err := s.store.CommissionRepository.GetOpenDefault(ctx, seed.Category, seed.MinCompletedOrders)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if rule != nil {
+		checksum := seedChecksum(rule.RateBps)
+		current = &checksum
+	}
+
+	outcome, err := s.resolve(ctx, key, current, desired)
+	if err != nil || outcome == dto.SeedOutcomeUnchanged || outcome == dto.SeedOutcomeKept {
+		return &dto.SeedResult{Key: key, Outcome: outcome}, err
+	}
+
+	// Rules are versioned, so an update is a new version like any other
+	next := &models.CommissionRule{
+		Category:           seed.Category,
+		MinCompletedOrders: seed.MinCompletedOrders,
+		RateBps:            seed.RateBps,
+		EffectiveFrom:      s.Clock().Now(),
+	}
+	if err := s.store.CommissionRepository.CloseOverlappingVersions(ctx, next); err != nil {
+		return nil, err
+	}
+	if err := s.store.CommissionRepository.Create(ctx, next); err != nil {
+		return nil, err
+	}
+
+	return &dto.SeedResult{Key: key, Outcome: outcome}, s.record(ctx, key, desired)
+}
+
+// resolve decides what to do with a default given the checksum of the
+// current row, nil when there is none, and the checksum of the new default
+func (s *seedService) resolve(ctx context.Context, key string, current *string, desired string) (dto.SeedOutcome, error) {
+	if current == nil {
+		return dto.SeedOutcomeCreated, nil
+	}
+
+	seeded, err := s.store.SeedRepository.Get(ctx, key)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	switch {
+	case *current == desired:
+		// Adopt rows that already match, so later default changes apply
+		if seeded == nil || seeded.Checksum != desired {
+			return dto.SeedOutcomeUnchanged, s.record(ctx, key, desired)
+		}
+		return dto.SeedOutcomeUnchanged, nil
+	case seeded != nil && seeded.Checksum == *current:
+		return dto.SeedOutcomeUpdated, nil
+	default:
+		return dto.SeedOutcomeKept, nil
+	}
+}
+
+func (s *seedService) record(ctx context.Context, key string, checksum string) error {
+	return s.store.SeedRepository.Save(ctx, &models.SeedRecord{
+		Key:       key,
+		Checksum:  checksum,
+		AppliedAt: s.Clock().Now(),
+	})
+}
+
+func seedChecksum(values ...any) string {
+	encoded, _ := json.Marshal(values)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,9 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/imlargo/go-api/internal/config"
 	"github.com/imlargo/go-api/internal/store"
 	medusaservice "github.com/imlargo/go-api/pkg/medusa/core/service"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"go.uber.org/zap"
 )
 
 type Service struct {
@@ -23,3 +30,48 @@ func NewService(
 		config,
 	}
 }
+
+// runSingleton runs a scheduled job on one instance at a time. Instances
+// that find the job already running skip it. With tenant isolation the job
+// runs once per tenant schema.
+func (s *Service) runSingleton(ctx context.Context, job string, fn func(ctx context.Context) error) error {
+	ran, err := s.store.JobLocker.RunExclusive(ctx, job, func(ctx context.Context) error {
+		start := time.Now()
+		err := s.forEachTenant(ctx, fn)
+
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		jobDuration.WithLabelValues(job, status).Observe(time.Since(start).Seconds())
+		return err
+	})
+	if !ran && err == nil {
+		s.Logger().Info("skipping job, another instance is running it", zap.String("job", job))
+	}
+	return err
+}
+
+// forEachTenant runs fn with the context of every tenant, or once with ctx
+// when tenants aren't isolated. A failing tenant doesn't stop the others.
+func (s *Service) forEachTenant(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !s.config.Tenancy.Enabled {
+		return fn(ctx)
+	}
+
+	tenants, err := s.store.TenantRepository.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, tenant := range tenants {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(tenancy.WithTenant(ctx, tenant.Slug)); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.Slug, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/jwt"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/imlargo/go-api/pkg/medusa/services/sse"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	claimsVersionKeyPrefix = "claims_version"
+	claimsVersionCacheTTL  = 24 * time.Hour
+
+	// SessionRefreshEvent is sent to the user's SSE connections when their
+	// tokens are invalidated so clients refresh the session right away
+	SessionRefreshEvent = "session_refresh"
+)
+
+var (
+	ErrInvalidRefreshToken = apperrors.Unauthorized("refresh token is invalid or expired").WithCode("INVALID_REFRESH_TOKEN")
+	ErrRefreshTokenReused  = apperrors.Unauthorized("refresh token was already used, sign in again").WithCode("REFRESH_TOKEN_REUSED")
+)
+
+type SessionService interface {
+	ClaimsVersion(ctx context.Context, userID uint) (int64, error)
+	RevokeSessions(ctx context.Context, userID uint, reason string) (int64, error)
+	IssueTokens(ctx context.Context, userID uint) (*dto.TokenPairResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*dto.TokenPairResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+type sessionService struct {
+	*Service
+	redis     *redis.Client
+	publisher sse.Publisher
+	jwt       *jwt.JWT
+}
+
+func NewSessionService(container *Service, redisClient *redis.Client, publisher sse.Publisher, jwtAuthenticator *jwt.JWT) SessionService {
+	return &sessionService{
+		Service:   container,
+		redis:     redisClient,
+		publisher: publisher,
+		jwt:       jwtAuthenticator,
+	}
+}
+
+// ClaimsVersion returns the current claims version of a user, read from
+// Redis and falling back to the database on a miss
+func (s *sessionService) ClaimsVersion(ctx context.Context, userID uint) (int64, error) {
+	key := claimsVersionKey(ctx, userID)
+
+	cached, err := s.redis.Get(ctx, key).Int64()
+	if err == nil {
+		return cached, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		s.Logger().WithContext(ctx).Warn("could not read cached claims version", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	version, err := s.store.UserRepository.GetClaimsVersion(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get claims version: %w", err)
+	}
+
+	if err := s.redis.Set(ctx, key, version, claimsVersionCacheTTL).Err(); err != nil {
+		s.Logger().WithContext(ctx).Warn("could not cache claims version", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	return version, nil
+}
+
+// RevokeSessions bumps the claims version of a user, invalidating every
+// token issued before, revokes their refresh tokens and tells their
+// connected clients to refresh
+func (s *sessionService) RevokeSessions(ctx context.Context, userID uint, reason string) (int64, error) {
+	version, err := s.store.UserRepository.BumpClaimsVersion(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The cache must not keep serving the old version, otherwise revoked
+	// tokens stay valid until the entry expires
+	if err := s.redis.Set(ctx, claimsVersionKey(ctx, userID), version, claimsVersionCacheTTL).Err(); err != nil {
+		if err := s.redis.Del(ctx, claimsVersionKey(ctx, userID)).Err(); err != nil {
+			return 0, fmt.Errorf("failed to invalidate cached claims version: %w", err)
+		}
+	}
+
+	if err := s.store.RefreshTokenRepository.RevokeByUser(ctx, userID, s.Clock().Now()); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := s.recordActivity(ctx, userID, models.ActivitySessionsRevoked, map[string]any{"reason": reason}); err != nil {
+		s.Logger().WithContext(ctx).Warn("could not record session activity", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	message := &sse.Message{
+		Event: SessionRefreshEvent,
+		Data: map[string]any{
+			"claims_version": version,
+			"reason":         reason,
+		},
+	}
+	if err := s.publisher.Send(ctx, userID, message); err != nil {
+		s.Logger().WithContext(ctx).Warn("could not notify session refresh", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	s.Logger().WithContext(ctx).Info("sessions revoked",
+		zap.Uint("user_id", userID),
+		zap.Int64("claims_version", version),
+		zap.String("reason", reason),
+	)
+
+	return version, nil
+}
+
+// IssueTokens starts a new refresh token family for a user who just signed
+// in, along with their first access token
+func (s *sessionService) IssueTokens(ctx context.Context, userID uint) (*dto.TokenPairResponse, error) {
+	family, err := newFamilyID()
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, userID, family)
+}
+
+// Refresh exchanges a refresh token for a new pair. The token is rotated:
+// it can't be used again, and presenting it twice revokes its family, as
+// one of the two holders must have stolen it.
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (*dto.TokenPairResponse, error) {
+	stored, err := s.store.RefreshTokenRepository.GetByHash(ctx, jwt.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if stored.RevokedAt != nil || !s.Clock().Now().Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var tokens *dto.TokenPairResponse
+	err = s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		marked, err := s.store.RefreshTokenRepository.MarkUsed(ctx, stored.ID, s.Clock().Now())
+		if err != nil {
+			return err
+		}
+		if !marked {
+			return ErrRefreshTokenReused
+		}
+
+		tokens, err = s.issue(ctx, stored.UserID, stored.FamilyID)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// Revoked outside the transaction, which rolled back
+		if err := s.store.RefreshTokenRepository.RevokeFamily(ctx, stored.FamilyID, s.Clock().Now()); err != nil {
+			return nil, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		s.Logger().WithContext(ctx).Warn("refresh token reused, family revoked",
+			zap.Uint("user_id", stored.UserID),
+			zap.String("family_id", stored.FamilyID),
+		)
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the family of refreshToken, signing out the device that
+// holds it. Access tokens already issued stay valid until they expire.
+func (s *sessionService) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.store.RefreshTokenRepository.GetByHash(ctx, jwt.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	return s.store.RefreshTokenRepository.RevokeFamily(ctx, stored.FamilyID, s.Clock().Now())
+}
+
+func (s *sessionService) issue(ctx context.Context, userID uint, family string) (*dto.TokenPairResponse, error) {
+	version, err := s.ClaimsVersion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Clock().Now()
+	expiresAt := now.Add(s.config.Auth.TokenExpiration)
+	accessToken, err := s.jwt.GenerateToken(userID, version, tenancy.Tenant(ctx), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, hash, err := jwt.NewRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshExpiresAt := now.Add(s.config.Auth.RefreshExpiration)
+	err = s.store.RefreshTokenRepository.Create(ctx, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  family,
+		TokenHash: hash,
+		ExpiresAt: refreshExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPairResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+func newFamilyID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func claimsVersionKey(ctx context.Context, userID uint) string {
+	return tenancy.Key(ctx, claimsVersionKeyPrefix) + ":" + strconv.FormatUint(uint64(userID), 10)
+}
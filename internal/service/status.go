@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/health"
+)
+
+const (
+	// statusCacheTTL bounds how often the public feed probes dependencies,
+	// it is served without authentication
+	statusCacheTTL = 30 * time.Second
+
+	statusErrorWindow = 5 * time.Minute
+	// statusMinRequests keeps a couple of failures on an idle component
+	// from reporting an outage
+	statusMinRequests = 20
+
+	// Shares of failed requests that degrade a component and that put it
+	// in partial outage
+	statusDegradedRate      = 0.05
+	statusPartialOutageRate = 0.25
+
+	statusHistoryDays    = 90
+	statusIncidentsLimit = 200
+)
+
+type ComponentStatus string
+
+const (
+	ComponentOperational   ComponentStatus = "operational"
+	ComponentDegraded      ComponentStatus = "degraded_performance"
+	ComponentPartialOutage ComponentStatus = "partial_outage"
+	ComponentMajorOutage   ComponentStatus = "major_outage"
+)
+
+var componentSeverity = map[ComponentStatus]int{
+	ComponentOperational:   0,
+	ComponentDegraded:      1,
+	ComponentPartialOutage: 2,
+	ComponentMajorOutage:   3,
+}
+
+// worse returns the more severe of two statuses
+func (c ComponentStatus) worse(other ComponentStatus) ComponentStatus {
+	if componentSeverity[other] > componentSeverity[c] {
+		return other
+	}
+	return c
+}
+
+// incidentComponentStatus is the state an incident puts its components in
+var incidentComponentStatus = map[models.IncidentImpact]ComponentStatus{
+	models.IncidentImpactMinor:    ComponentDegraded,
+	models.IncidentImpactMajor:    ComponentPartialOutage,
+	models.IncidentImpactCritical: ComponentMajorOutage,
+}
+
+var (
+	ErrUnknownStatusComponent = apperrors.Validation("unknown status component").WithCode("UNKNOWN_STATUS_COMPONENT")
+	ErrIncidentResolved       = apperrors.Conflict("incident is already resolved").WithCode("INCIDENT_RESOLVED")
+)
+
+type StatusService interface {
+	GetStatus(ctx context.Context) (*dto.StatusPageResponse, error)
+	RecordResponse(route string, status int)
+	ListIncidents(ctx context.Context) ([]*models.Incident, error)
+	CreateIncident(ctx context.Context, req *dto.CreateIncidentRequest) (*models.Incident, error)
+	PostIncidentUpdate(ctx context.Context, incidentID uint, req *dto.PostIncidentUpdateRequest) (*models.Incident, error)
+}
+
+// statusComponent is a part of the platform shown on the status page. It
+// is down when a health probe it needs fails, and degraded when too many
+// requests to its routes fail.
+type statusComponent struct {
+	name        string
+	description string
+	checks      []string
+	routes      []string
+	errors      *health.ErrorRate
+}
+
+type statusService struct {
+	*Service
+	health     *health.Registry
+	components []*statusComponent
+
+	mu       sync.Mutex
+	cached   *dto.StatusPageResponse
+	cachedAt time.Time
+}
+
+// NewStatusService builds the status page from the probes of registry and
+// the responses recorded by the error rate middleware. The feed is cached
+// per process, so an incident change shows on other instances within
+// statusCacheTTL.
+func NewStatusService(container *Service, registry *health.Registry) StatusService {
+	components := []*statusComponent{
+		{
+			name:        "api",
+			description: "Public API and authentication",
+			checks:      []string{"postgres", "redis"},
+			routes:      []string{"/api/v1", "/auth"},
+		},
+		{
+			name:        "analytics_sync",
+			description: "Usage analytics and client sync",
+			checks:      []string{"postgres"},
+			routes:      []string{"/api/v1/usage", "/api/v1/sync", "/admin/usage"},
+		},
+	}
+	for _, component := range components {
+		component.errors = health.NewErrorRate(statusErrorWindow)
+	}
+
+	return &statusService{
+		Service:    container,
+		health:     registry,
+		components: components,
+	}
+}
+
+// RecordResponse counts a response towards the error rate of every
+// component serving route. Server errors are failures, client errors are
+// not.
+func (s *statusService) RecordResponse(route string, status int) {
+	now := s.Clock().Now()
+	for _, component := range s.components {
+		if component.serves(route) {
+			component.errors.Record(now, status >= 500)
+		}
+	}
+}
+
+func (c *statusComponent) serves(route string) bool {
+	for _, prefix := range c.routes {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *statusService) GetStatus(ctx context.Context) (*dto.StatusPageResponse, error) {
+	// Held while computing, so concurrent requests wait for one probe run
+	// instead of each starting their own
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Clock().Now()
+	if s.cached != nil && now.Sub(s.cachedAt) < statusCacheTTL {
+		return s.cached, nil
+	}
+
+	historyStart := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -(statusHistoryDays - 1))
+	incidents, err := s.store.IncidentRepository.ListSince(ctx, historyStart, statusIncidentsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := s.health.Run(ctx)
+	page := &dto.StatusPageResponse{
+		UpdatedAt: now,
+		Incidents: incidents,
+		History:   s.history(incidents, historyStart, now),
+	}
+	overall := ComponentOperational
+	for _, component := range s.components {
+		status := s.componentStatus(component, report, incidents, now)
+		overall = overall.worse(status)
+		page.Components = append(page.Components, dto.StatusComponent{
+			Name:        component.name,
+			Description: component.description,
+			Status:      string(status),
+		})
+	}
+	page.Status = string(overall)
+
+	s.cached, s.cachedAt = page, now
+	return page, nil
+}
+
+func (s *statusService) componentStatus(component *statusComponent, report *health.Report, incidents []*models.Incident, now time.Time) ComponentStatus {
+	status := ComponentOperational
+
+	for _, check := range component.checks {
+		result, exists := report.Checks[check]
+		if !exists || result.Status == health.StatusUp {
+			continue
+		}
+		if result.Optional {
+			status = status.worse(ComponentDegraded)
+		} else {
+			status = status.worse(ComponentMajorOutage)
+		}
+	}
+
+	if rate, requests := component.errors.Rate(now); requests >= statusMinRequests {
+		switch {
+		case rate >= statusPartialOutageRate:
+			status = status.worse(ComponentPartialOutage)
+		case rate >= statusDegradedRate:
+			status = status.worse(ComponentDegraded)
+		}
+	}
+
+	for _, incident := range incidents {
+		if incident.IsActive() && affects(incident, component.name) {
+			status = status.worse(incidentComponentStatus[incident.Impact])
+		}
+	}
+	return status
+}
+
+// history returns the worst state of every component on each day since
+// start, oldest first. Only incidents count, probes and error rates are
+// not kept.
+func (s *statusService) history(incidents []*models.Incident, start, now time.Time) []dto.StatusDay {
+	days := make([]dto.StatusDay, 0, statusHistoryDays)
+	for day := start; day.Before(now); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+
+		statuses := make(map[string]ComponentStatus, len(s.components))
+		for _, incident := range incidents {
+			resolvedAt := now
+			if incident.ResolvedAt != nil {
+				resolvedAt = *incident.ResolvedAt
+			}
+			if !incident.StartedAt.Before(end) || resolvedAt.Before(day) {
+				continue
+			}
+			for _, name := range incident.Components {
+				statuses[name] = statuses[name].worse(incidentComponentStatus[incident.Impact])
+			}
+		}
+
+		components := make(map[string]string, len(s.components))
+		for _, component := range s.components {
+			status := ComponentOperational.worse(statuses[component.name])
+			components[component.name] = string(status)
+		}
+		days = append(days, dto.StatusDay{Date: day.Format(time.DateOnly), Components: components})
+	}
+	return days
+}
+
+func affects(incident *models.Incident, component string) bool {
+	for _, name := range incident.Components {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+// ListIncidents returns the active incidents and those resolved within the
+// status page history, newest first
+func (s *statusService) ListIncidents(ctx context.Context) ([]*models.Incident, error) {
+	since := s.Clock().Now().AddDate(0, 0, -statusHistoryDays)
+	return s.store.IncidentRepository.ListSince(ctx, since, statusIncidentsLimit)
+}
+
+func (s *statusService) CreateIncident(ctx context.Context, req *dto.CreateIncidentRequest) (*models.Incident, error) {
+	if err := s.validateComponents(req.Components); err != nil {
+		return nil, err
+	}
+
+	status := models.IncidentInvestigating
+	if req.Status != "" {
+		status = models.IncidentStatus(req.Status)
+	}
+	startedAt := s.Clock().Now()
+	if req.StartedAt != nil {
+		startedAt = *req.StartedAt
+	}
+
+	incident := &models.Incident{
+		Title:      req.Title,
+		Impact:     models.IncidentImpact(req.Impact),
+		Status:     status,
+		Components: req.Components,
+		StartedAt:  startedAt,
+		Updates:    []*models.IncidentUpdate{{Status: status, Message: req.Message}},
+	}
+	if err := s.store.IncidentRepository.Create(ctx, incident); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return incident, nil
+}
+
+// PostIncidentUpdate records the progress of an incident, resolving it when
+// the status is resolved
+func (s *statusService) PostIncidentUpdate(ctx context.Context, incidentID uint, req *dto.PostIncidentUpdateRequest) (*models.Incident, error) {
+	var incident *models.Incident
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		incident, err = s.store.IncidentRepository.GetByID(ctx, incidentID)
+		if err != nil {
+			return err
+		}
+		if !incident.IsActive() {
+			return ErrIncidentResolved
+		}
+
+		incident.Status = models.IncidentStatus(req.Status)
+		if req.Impact != nil {
+			incident.Impact = models.IncidentImpact(*req.Impact)
+		}
+		if incident.Status == models.IncidentResolved {
+			now := s.Clock().Now()
+			incident.ResolvedAt = &now
+		}
+
+		update := &models.IncidentUpdate{IncidentID: incident.ID, Status: incident.Status, Message: req.Message}
+		if err := s.store.IncidentRepository.CreateUpdate(ctx, update); err != nil {
+			return err
+		}
+		incident.Updates = append([]*models.IncidentUpdate{update}, incident.Updates...)
+		return s.store.IncidentRepository.Update(ctx, incident)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return incident, nil
+}
+
+func (s *statusService) validateComponents(names []string) error {
+	for _, name := range names {
+		known := false
+		for _, component := range s.components {
+			if component.name == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return ErrUnknownStatusComponent.WithDetails(fmt.Sprintf("component %q does not exist", name))
+		}
+	}
+	return nil
+}
+
+// invalidate drops the cached feed of this process
+func (s *statusService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = nil
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/core/warnings"
+	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// storageHealthCheckBatchSize is how many customer buckets are loaded at a
+// time by the health checker
+const storageHealthCheckBatchSize = 100
+
+var (
+	ErrStorageEncryptionDisabled = apperrors.Validation("customer buckets require ENCRYPTION_KEY to be configured").WithCode("STORAGE_ENCRYPTION_DISABLED")
+	ErrStorageProviderInvalid    = apperrors.Validation("unsupported storage provider").WithCode("STORAGE_PROVIDER_INVALID")
+	ErrStorageCredentialsInvalid = apperrors.Validation("storage credentials failed validation").WithCode("STORAGE_CREDENTIALS_INVALID")
+	ErrStorageConfigDisabled     = apperrors.Validation("user has no enabled customer bucket").WithCode("STORAGE_CONFIG_DISABLED")
+)
+
+type StorageConfigService interface {
+	Get(ctx context.Context, userID uint) (*models.UserStorageConfig, error)
+	Set(ctx context.Context, userID uint, req *dto.SetStorageConfigRequest) (*models.UserStorageConfig, error)
+	Remove(ctx context.Context, userID uint) error
+	Check(ctx context.Context, userID uint) (*models.UserStorageConfig, error)
+	Migrate(ctx context.Context, userID uint, prefix string, deleteSource bool) (*dto.StorageMigrationReport, error)
+	Router() storage.StorageRouter
+	StartHealthChecker(ctx context.Context)
+}
+
+type storageConfigService struct {
+	*Service
+	router storage.StorageRouter
+}
+
+func NewStorageConfigService(container *Service, defaultStorage storage.FileStorage) StorageConfigService {
+	s := &storageConfigService{Service: container}
+	s.router = storage.NewStorageRouter(defaultStorage, s.resolveBucket)
+	return s
+}
+
+// UserStoragePrefix is the key prefix under which a user's files are stored
+func UserStoragePrefix(userID uint) string {
+	return fmt.Sprintf("users/%d/", userID)
+}
+
+func (s *storageConfigService) Get(ctx context.Context, userID uint) (*models.UserStorageConfig, error) {
+	return s.store.StorageConfigRepository.GetByUserID(ctx, userID)
+}
+
+// Set validates the credentials against the bucket before storing them, so
+// a broken configuration never starts receiving files
+func (s *storageConfigService) Set(ctx context.Context, userID uint, req *dto.SetStorageConfigRequest) (*models.UserStorageConfig, error) {
+	if encryption.Default() == nil {
+		return nil, ErrStorageEncryptionDisabled
+	}
+
+	provider := storage.StorageProvider(req.Provider)
+	if !provider.IsValid() {
+		return nil, ErrStorageProviderInvalid
+	}
+
+	config, err := s.store.StorageConfigRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		config = &models.UserStorageConfig{UserID: userID}
+	}
+
+	config.Provider = req.Provider
+	config.BucketName = req.BucketName
+	config.AccountID = req.AccountID
+	config.AccessKeyID = encryption.EncryptedString(req.AccessKeyID)
+	config.SecretAccessKey = encryption.EncryptedString(req.SecretAccessKey)
+	config.PublicDomain = req.PublicDomain
+	config.UsePublicURL = req.UsePublicURL
+	config.Enabled = true
+
+	if err := s.verify(config); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrStorageCredentialsInvalid, err.Error())
+	}
+
+	now := s.Clock().Now()
+	config.Status = models.StorageConfigStatusHealthy
+	config.LastCheckedAt = &now
+	config.LastError = ""
+
+	created := config.ID == 0
+	if err := s.store.StorageConfigRepository.Save(ctx, config); err != nil {
+		return nil, err
+	}
+	s.router.Invalidate(ctx, userID)
+
+	activity := map[string]any{"provider": config.Provider, "bucket": config.BucketName}
+	if err := s.recordActivity(ctx, userID, models.ActivityStorageChanged, activity); err != nil {
+		s.Logger().WithContext(ctx).Warn("could not record storage activity", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	if created {
+		warnings.Add(ctx, "storage_files_not_migrated", "existing files stay in the platform bucket until they are migrated with the storage migrate command")
+	}
+
+	return config, nil
+}
+
+func (s *storageConfigService) Remove(ctx context.Context, userID uint) error {
+	if err := s.store.StorageConfigRepository.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	s.router.Invalidate(ctx, userID)
+
+	if err := s.recordActivity(ctx, userID, models.ActivityStorageChanged, map[string]any{"provider": "platform"}); err != nil {
+		s.Logger().WithContext(ctx).Warn("could not record storage activity", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	return nil
+}
+
+// Check re-validates the stored credentials and records the result
+func (s *storageConfigService) Check(ctx context.Context, userID uint) (*models.UserStorageConfig, error) {
+	config, err := s.store.StorageConfigRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAndRecord(ctx, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Migrate copies every object under prefix from the platform bucket into
+// the user's bucket. Source objects are only deleted when every copy
+// succeeded and deleteSource is set.
+func (s *storageConfigService) Migrate(ctx context.Context, userID uint, prefix string, deleteSource bool) (*dto.StorageMigrationReport, error) {
+	config, err := s.store.StorageConfigRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, ErrStorageConfigDisabled
+	}
+
+	if prefix == "" {
+		prefix = UserStoragePrefix(userID)
+	}
+
+	source := s.router.Default()
+	destination, err := s.router.ForOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := source.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.StorageMigrationReport{
+		UserID: userID,
+		Prefix: prefix,
+		Total:  len(keys),
+		Failed: []string{},
+	}
+
+	for _, key := range keys {
+		if err := copyObject(source, destination, key); err != nil {
+			s.Logger().Error("failed to migrate object", zap.Uint("user_id", userID), zap.String("key", key), zap.Error(err))
+			report.Failed = append(report.Failed, key)
+			continue
+		}
+		report.Copied++
+	}
+
+	if deleteSource && len(report.Failed) == 0 && len(keys) > 0 {
+		if err := source.BulkDelete(keys); err != nil {
+			return report, fmt.Errorf("objects were copied but the source could not be cleaned up: %w", err)
+		}
+		report.DeletedSource = true
+	}
+
+	return report, nil
+}
+
+func (s *storageConfigService) Router() storage.StorageRouter {
+	return s.router
+}
+
+// StartHealthChecker periodically validates every enabled customer bucket
+// and logs the ones that stopped accepting our credentials
+func (s *storageConfigService) StartHealthChecker(ctx context.Context) {
+	if s.config.CustomerStorage.HealthCheckInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.config.CustomerStorage.HealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "customer_storage_health", s.checkAll)
+				if err != nil {
+					s.Logger().Error("customer bucket health check failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func (s *storageConfigService) checkAll(ctx context.Context) error {
+	return s.store.StorageConfigRepository.ForEachEnabled(ctx, storageHealthCheckBatchSize, func(configs []*models.UserStorageConfig) error {
+		for _, config := range configs {
+			if err := s.checkAndRecord(ctx, config); err != nil {
+				s.Logger().Error("failed to record customer bucket health", zap.Uint("user_id", config.UserID), zap.Error(err))
+				continue
+			}
+			if config.Status == models.StorageConfigStatusUnhealthy {
+				s.Logger().Warn("customer bucket is unhealthy",
+					zap.Uint("user_id", config.UserID),
+					zap.String("bucket", config.BucketName),
+					zap.String("error", config.LastError),
+				)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *storageConfigService) checkAndRecord(ctx context.Context, config *models.UserStorageConfig) error {
+	now := s.Clock().Now()
+	config.LastCheckedAt = &now
+	config.Status = models.StorageConfigStatusHealthy
+	config.LastError = ""
+
+	if err := s.verify(config); err != nil {
+		config.Status = models.StorageConfigStatusUnhealthy
+		config.LastError = err.Error()
+	}
+
+	return s.store.StorageConfigRepository.Save(ctx, config)
+}
+
+func (s *storageConfigService) verify(config *models.UserStorageConfig) error {
+	fileStorage, err := storage.NewFileStorage(storage.StorageProvider(config.Provider), bucketConfig(config))
+	if err != nil {
+		return err
+	}
+
+	return fileStorage.CheckAccess()
+}
+
+func (s *storageConfigService) resolveBucket(ctx context.Context, ownerID uint) (*storage.OwnerBucket, error) {
+	config, err := s.store.StorageConfigRepository.GetByUserID(ctx, ownerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	return &storage.OwnerBucket{
+		Provider: storage.StorageProvider(config.Provider),
+		Config:   bucketConfig(config),
+	}, nil
+}
+
+func bucketConfig(config *models.UserStorageConfig) storage.StorageConfig {
+	return storage.StorageConfig{
+		BucketName:      config.BucketName,
+		AccountID:       config.AccountID,
+		AccessKeyID:     config.AccessKeyID.String(),
+		SecretAccessKey: config.SecretAccessKey.String(),
+		PublicDomain:    config.PublicDomain,
+		UsePublicURL:    config.UsePublicURL,
+	}
+}
+
+func copyObject(source, destination storage.FileStorage, key string) error {
+	file, err := source.GetFileForDownload(key)
+	if err != nil {
+		return err
+	}
+	defer file.Content.Close()
+
+	_, err = destination.Upload(key, file.Content, file.ContentType, file.Size)
+	return err
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	storageDeletionBatchSize   = 100
+	storageDeletionMaxAttempts = 8
+	storageDeletionBaseDelay   = time.Minute
+	storageDeletionInterval    = time.Minute
+	orphanReportSampleSize     = 100
+)
+
+var ErrUnknownStorageSource = apperrors.NotFound("storage source").WithCode("UNKNOWN_STORAGE_SOURCE")
+
+// storageKeySource lists the object keys referenced by the rows of one
+// table, all stored under prefix
+type storageKeySource struct {
+	prefix string
+	keys   func(ctx context.Context) ([]string, error)
+}
+
+type StorageLifecycleService interface {
+	ProcessDeletions(ctx context.Context) (int, error)
+	StartDeletionWorker(ctx context.Context)
+	ScanOrphans(ctx context.Context, source string, enqueue bool) (*dto.OrphanScanReport, error)
+	Stats(ctx context.Context) (*dto.StorageConsistencyStats, error)
+}
+
+type storageLifecycleService struct {
+	*Service
+	fileStorage storage.FileStorage
+	sources     map[string]storageKeySource
+
+	mu        sync.Mutex
+	lastScans map[string]*dto.OrphanScanReport
+}
+
+func NewStorageLifecycleService(container *Service, fileStorage storage.FileStorage) StorageLifecycleService {
+	return &storageLifecycleService{
+		Service:     container,
+		fileStorage: fileStorage,
+		sources: map[string]storageKeySource{
+			"backups": {prefix: "backups/", keys: container.store.BackupRepository.ListObjectKeys},
+		},
+		lastScans: make(map[string]*dto.OrphanScanReport),
+	}
+}
+
+// ProcessDeletions deletes the due objects of the queue. Failures are
+// retried with exponential backoff until they are marked failed.
+func (s *storageLifecycleService) ProcessDeletions(ctx context.Context) (int, error) {
+	deleted := 0
+
+	for {
+		deletions, err := s.store.StorageDeletionRepository.GetDue(ctx, s.Clock().Now(), storageDeletionBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, deletion := range deletions {
+			s.attemptDeletion(deletion)
+			if err := s.store.StorageDeletionRepository.Update(ctx, deletion); err != nil {
+				return deleted, err
+			}
+			if deletion.Status == models.StorageDeletionDeleted {
+				deleted++
+			}
+		}
+
+		if len(deletions) < storageDeletionBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+func (s *storageLifecycleService) attemptDeletion(deletion *models.StorageDeletion) {
+	deletion.Attempts++
+
+	if err := s.fileStorage.Delete(deletion.ObjectKey); err != nil {
+		deletion.LastError = err.Error()
+		if deletion.Attempts >= storageDeletionMaxAttempts {
+			deletion.Status = models.StorageDeletionFailed
+			s.Logger().Error("giving up on storage deletion",
+				zap.String("key", deletion.ObjectKey),
+				zap.Int("attempts", deletion.Attempts),
+				zap.Error(err),
+			)
+			return
+		}
+
+		delay := storageDeletionBaseDelay * time.Duration(math.Pow(2, float64(deletion.Attempts-1)))
+		deletion.NextAttemptAt = s.Clock().Now().Add(delay)
+		return
+	}
+
+	now := s.Clock().Now()
+	deletion.Status = models.StorageDeletionDeleted
+	deletion.DeletedAt = &now
+	deletion.LastError = ""
+}
+
+func (s *storageLifecycleService) StartDeletionWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(storageDeletionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "storage_deletions", func(ctx context.Context) error {
+					deleted, err := s.ProcessDeletions(ctx)
+					if deleted > 0 {
+						s.Logger().Info("deleted storage objects", zap.Int("deleted", deleted))
+					}
+					return err
+				})
+				if err != nil {
+					s.Logger().Error("storage deletion run failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// ScanOrphans lists the bucket objects under the prefix of source and
+// compares them to the keys referenced in the database. With enqueue, the
+// orphans are queued for deletion.
+func (s *storageLifecycleService) ScanOrphans(ctx context.Context, source string, enqueue bool) (*dto.OrphanScanReport, error) {
+	keySource, ok := s.sources[source]
+	if !ok {
+		return nil, ErrUnknownStorageSource
+	}
+
+	objects, err := s.fileStorage.List(keySource.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced, err := keySource.keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.store.StorageDeletionRepository.ListPendingKeys(ctx, keySource.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(referenced)+len(pending))
+	for _, key := range referenced {
+		known[key] = true
+	}
+	for _, key := range pending {
+		known[key] = true
+	}
+
+	inBucket := make(map[string]bool, len(objects))
+	report := &dto.OrphanScanReport{
+		Source:     source,
+		Prefix:     keySource.prefix,
+		ScannedAt:  s.Clock().Now(),
+		Objects:    len(objects),
+		Referenced: len(referenced),
+		Orphans:    []string{},
+		Missing:    []string{},
+	}
+
+	for _, key := range objects {
+		inBucket[key] = true
+		if !known[key] && !strings.HasSuffix(key, "/") {
+			report.Orphans = append(report.Orphans, key)
+		}
+	}
+	for _, key := range referenced {
+		if !inBucket[key] {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+	sort.Strings(report.Orphans)
+	sort.Strings(report.Missing)
+
+	if enqueue {
+		for _, key := range report.Orphans {
+			deletion := &models.StorageDeletion{
+				ObjectKey:     key,
+				Source:        source,
+				Status:        models.StorageDeletionPending,
+				NextAttemptAt: s.Clock().Now(),
+			}
+			if err := s.store.StorageDeletionRepository.Create(ctx, deletion); err != nil {
+				return nil, err
+			}
+			report.Enqueued++
+		}
+	}
+
+	s.mu.Lock()
+	s.lastScans[source] = summarizeOrphanReport(report)
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+// summarizeOrphanReport keeps a sample of the keys for the stats endpoint,
+// a bucket full of orphans shouldn't be held in memory
+func summarizeOrphanReport(report *dto.OrphanScanReport) *dto.OrphanScanReport {
+	summary := *report
+	summary.Orphans = report.Orphans[:min(len(report.Orphans), orphanReportSampleSize)]
+	summary.Missing = report.Missing[:min(len(report.Missing), orphanReportSampleSize)]
+	return &summary
+}
+
+func (s *storageLifecycleService) Stats(ctx context.Context) (*dto.StorageConsistencyStats, error) {
+	counts, err := s.store.StorageDeletionRepository.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dto.StorageConsistencyStats{
+		PendingDeletions: counts[models.StorageDeletionPending],
+		FailedDeletions:  counts[models.StorageDeletionFailed],
+		DeletedObjects:   counts[models.StorageDeletionDeleted],
+		LastScans:        []*dto.OrphanScanReport{},
+	}
+
+	s.mu.Lock()
+	for _, report := range s.lastScans {
+		stats.LastScans = append(stats.LastScans, report)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(stats.LastScans, func(i, j int) bool {
+		return stats.LastScans[i].Source < stats.LastScans[j].Source
+	})
+
+	return stats, nil
+}
@@ -0,0 +1,548 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/query"
+	"github.com/imlargo/go-api/pkg/medusa/core/sanitize"
+	"github.com/imlargo/go-api/pkg/medusa/services/email"
+	"github.com/imlargo/go-api/pkg/medusa/services/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	supportMaxAttachments     = 5
+	supportMaxAttachmentBytes = 10 << 20
+	supportBreachBatchSize    = 100
+	supportAttachmentURLTTL   = 15 * time.Minute
+)
+
+var (
+	ErrSupportInvalidTransition  = apperrors.Conflict("support ticket can't move to this status").WithCode("SUPPORT_INVALID_TRANSITION")
+	ErrSupportTicketClosed       = apperrors.Conflict("support ticket is closed").WithCode("SUPPORT_TICKET_CLOSED")
+	ErrSupportTooManyAttachments = apperrors.Validation(fmt.Sprintf("a support ticket takes at most %d attachments", supportMaxAttachments)).WithCode("SUPPORT_TOO_MANY_ATTACHMENTS")
+	ErrSupportAttachmentTooLarge = apperrors.Validation(fmt.Sprintf("support attachments can't be larger than %d MB", supportMaxAttachmentBytes>>20)).WithCode("SUPPORT_ATTACHMENT_TOO_LARGE")
+	ErrSupportEmptyText          = apperrors.Validation("support ticket text is empty once markup is removed").WithCode("SUPPORT_EMPTY_TEXT")
+)
+
+type supportSLA struct {
+	FirstResponse time.Duration
+	Resolution    time.Duration
+}
+
+// supportSLAs are the response times promised to each tier
+var supportSLAs = map[models.SupportTier]supportSLA{
+	models.SupportTierStandard:   {FirstResponse: 24 * time.Hour, Resolution: 5 * 24 * time.Hour},
+	models.SupportTierPriority:   {FirstResponse: 4 * time.Hour, Resolution: 48 * time.Hour},
+	models.SupportTierEnterprise: {FirstResponse: time.Hour, Resolution: 24 * time.Hour},
+}
+
+// supportTransitions are the statuses a ticket can move to from each
+// status. Closed is final, resolved tickets can be reopened.
+var supportTransitions = map[models.SupportTicketStatus][]models.SupportTicketStatus{
+	models.SupportTicketOpen:              {models.SupportTicketInProgress, models.SupportTicketWaitingOnCustomer, models.SupportTicketResolved, models.SupportTicketClosed},
+	models.SupportTicketInProgress:        {models.SupportTicketWaitingOnCustomer, models.SupportTicketResolved, models.SupportTicketClosed},
+	models.SupportTicketWaitingOnCustomer: {models.SupportTicketInProgress, models.SupportTicketResolved, models.SupportTicketClosed},
+	models.SupportTicketResolved:          {models.SupportTicketInProgress, models.SupportTicketClosed},
+}
+
+// supportTicketQuery are the filters and sorts of the admin queue. The
+// queue defaults to the tickets closest to their resolution deadline.
+var supportTicketQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"status":            {Column: "status", Operators: []query.Operator{query.Eq, query.Ne, query.In}},
+		"tier":              {Column: "tier", Operators: []query.Operator{query.Eq, query.In}},
+		"category":          {Column: "category", Operators: []query.Operator{query.Eq, query.In}},
+		"assigned_to":       {Column: "assigned_to"},
+		"user_id":           {Column: "user_id", Type: query.Int},
+		"created_at":        {Column: "created_at", Type: query.Time, Operators: []query.Operator{query.Gte, query.Lt}, Sortable: true},
+		"resolution_due_at": {Column: "resolution_due_at", Type: query.Time, Operators: []query.Operator{query.Gte, query.Lt}, Sortable: true},
+	},
+	DefaultSort: "resolution_due_at",
+}
+
+var supportEmailTemplate = template.Must(email.NewTemplate("support_ticket", `<p>{{.Heading}}</p>
+<p><strong>#{{.Ticket.ID}} {{.Ticket.Subject}}</strong><br>Status: {{.Ticket.Status}}</p>
+{{if .Message}}<blockquote>{{plaintext .Message}}</blockquote>{{end}}`))
+
+type SupportService interface {
+	CreateTicket(ctx context.Context, userID uint, req *dto.CreateSupportTicketRequest, attachments []*dto.SupportAttachmentUpload) (*models.SupportTicket, error)
+	ListMyTickets(ctx context.Context, userID uint) ([]*models.SupportTicket, error)
+	GetMyTicket(ctx context.Context, userID uint, ticketID uint) (*models.SupportTicket, error)
+	Reply(ctx context.Context, userID uint, ticketID uint, body string) (*models.SupportTicket, error)
+	Close(ctx context.Context, userID uint, ticketID uint) (*models.SupportTicket, error)
+	ListQueue(ctx context.Context, params url.Values, limit int) ([]*models.SupportTicket, error)
+	GetTicket(ctx context.Context, ticketID uint) (*models.SupportTicket, error)
+	UpdateTicket(ctx context.Context, actor string, ticketID uint, req *dto.UpdateSupportTicketRequest) (*models.SupportTicket, error)
+	SetUserTier(ctx context.Context, userID uint, tier models.SupportTier) error
+	CheckBreaches(ctx context.Context) (int, error)
+	StartSLAWorker(ctx context.Context)
+}
+
+type supportService struct {
+	*Service
+	fileStorage storage.FileStorage
+	emails      email.EmailService
+	alerts      ChatAlertService
+}
+
+// NewSupportService creates the support ticket service. A nil emails skips
+// the email notifications.
+func NewSupportService(container *Service, fileStorage storage.FileStorage, emails email.EmailService, alerts ChatAlertService) SupportService {
+	return &supportService{
+		Service:     container,
+		fileStorage: fileStorage,
+		emails:      emails,
+		alerts:      alerts,
+	}
+}
+
+// CreateTicket opens a ticket with the SLA of the user's tier. Attachments
+// are uploaded first and removed again if the ticket can't be saved. The
+// subject and description are stored as plain text, they end up in emails.
+func (s *supportService) CreateTicket(ctx context.Context, userID uint, req *dto.CreateSupportTicketRequest, attachments []*dto.SupportAttachmentUpload) (*models.SupportTicket, error) {
+	subject, description := sanitize.PlainText(req.Subject), sanitize.PlainText(req.Description)
+	if strings.TrimSpace(subject) == "" || strings.TrimSpace(description) == "" {
+		return nil, ErrSupportEmptyText
+	}
+	if len(attachments) > supportMaxAttachments {
+		return nil, ErrSupportTooManyAttachments
+	}
+	for _, attachment := range attachments {
+		if attachment.Size > supportMaxAttachmentBytes {
+			return nil, ErrSupportAttachmentTooLarge
+		}
+	}
+
+	user, err := s.store.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tier := user.SupportTier
+	sla, exists := supportSLAs[tier]
+	if !exists {
+		tier = models.SupportTierStandard
+		sla = supportSLAs[tier]
+	}
+
+	uploaded, err := s.uploadAttachments(userID, attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Clock().Now()
+	ticket := &models.SupportTicket{
+		UserID:             userID,
+		Tier:               tier,
+		Category:           models.SupportTicketCategory(req.Category),
+		Subject:            subject,
+		Description:        description,
+		Status:             models.SupportTicketOpen,
+		FirstResponseDueAt: now.Add(sla.FirstResponse),
+		ResolutionDueAt:    now.Add(sla.Resolution),
+	}
+
+	err = s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.store.SupportTicketRepository.Create(ctx, ticket); err != nil {
+			return err
+		}
+		for _, attachment := range uploaded {
+			attachment.TicketID = ticket.ID
+			if err := s.store.SupportTicketRepository.CreateAttachment(ctx, attachment); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.removeAttachments(uploaded)
+		return nil, err
+	}
+
+	ticket.Attachments = uploaded
+	s.signAttachments(ticket)
+
+	s.emailTeam(ticket, fmt.Sprintf("New %s support ticket from %s", ticket.Tier, user.Email), ticket.Description)
+	return ticket, nil
+}
+
+func (s *supportService) ListMyTickets(ctx context.Context, userID uint) ([]*models.SupportTicket, error) {
+	return s.store.SupportTicketRepository.ListByUser(ctx, userID)
+}
+
+func (s *supportService) GetMyTicket(ctx context.Context, userID uint, ticketID uint) (*models.SupportTicket, error) {
+	ticket, err := s.ownedTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	s.signAttachments(ticket)
+	return ticket, nil
+}
+
+// Reply adds a message from the user. A ticket waiting on the customer or
+// already resolved goes back to in progress.
+func (s *supportService) Reply(ctx context.Context, userID uint, ticketID uint, body string) (*models.SupportTicket, error) {
+	body = sanitize.PlainText(body)
+	if strings.TrimSpace(body) == "" {
+		return nil, ErrSupportEmptyText
+	}
+
+	var ticket *models.SupportTicket
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		ticket, err = s.ownedTicket(ctx, userID, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.Status == models.SupportTicketClosed {
+			return ErrSupportTicketClosed
+		}
+
+		message := &models.SupportTicketMessage{TicketID: ticket.ID, Body: body}
+		if err := s.store.SupportTicketRepository.CreateMessage(ctx, message); err != nil {
+			return err
+		}
+		ticket.Messages = append(ticket.Messages, message)
+
+		if ticket.Status == models.SupportTicketWaitingOnCustomer || ticket.Status == models.SupportTicketResolved {
+			if err := s.transition(ticket, models.SupportTicketInProgress); err != nil {
+				return err
+			}
+		}
+		return s.store.SupportTicketRepository.Update(ctx, ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.signAttachments(ticket)
+	s.emailTeam(ticket, "The customer replied", body)
+	return ticket, nil
+}
+
+func (s *supportService) Close(ctx context.Context, userID uint, ticketID uint) (*models.SupportTicket, error) {
+	var ticket *models.SupportTicket
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		ticket, err = s.ownedTicket(ctx, userID, ticketID)
+		if err != nil {
+			return err
+		}
+		if err := s.transition(ticket, models.SupportTicketClosed); err != nil {
+			return err
+		}
+		return s.store.SupportTicketRepository.Update(ctx, ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.signAttachments(ticket)
+	s.emailTeam(ticket, "The customer closed the ticket", "")
+	return ticket, nil
+}
+
+// ListQueue returns the tickets filtered and sorted by params, see
+// supportTicketQuery
+func (s *supportService) ListQueue(ctx context.Context, params url.Values, limit int) ([]*models.SupportTicket, error) {
+	filters, err := supportTicketQuery.Parse(params)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.SupportTicketRepository.List(ctx, filters, limit)
+}
+
+func (s *supportService) GetTicket(ctx context.Context, ticketID uint) (*models.SupportTicket, error) {
+	ticket, err := s.store.SupportTicketRepository.GetByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	s.signAttachments(ticket)
+	return ticket, nil
+}
+
+// UpdateTicket applies the changes of an admin. The first status change or
+// message from staff counts as the first response; assigning doesn't.
+func (s *supportService) UpdateTicket(ctx context.Context, actor string, ticketID uint, req *dto.UpdateSupportTicketRequest) (*models.SupportTicket, error) {
+	text := sanitize.PlainText(req.Message)
+	if req.Message != "" && strings.TrimSpace(text) == "" {
+		return nil, ErrSupportEmptyText
+	}
+
+	var ticket *models.SupportTicket
+	statusChanged := false
+	err := s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		ticket, err = s.store.SupportTicketRepository.GetByID(ctx, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.Status == models.SupportTicketClosed {
+			return ErrSupportTicketClosed
+		}
+
+		if req.Status != nil && models.SupportTicketStatus(*req.Status) != ticket.Status {
+			if err := s.transition(ticket, models.SupportTicketStatus(*req.Status)); err != nil {
+				return err
+			}
+			statusChanged = true
+		}
+		if req.AssignedTo != nil {
+			ticket.AssignedTo = *req.AssignedTo
+		}
+		if text != "" {
+			message := &models.SupportTicketMessage{TicketID: ticket.ID, Staff: true, Author: actor, Body: text}
+			if err := s.store.SupportTicketRepository.CreateMessage(ctx, message); err != nil {
+				return err
+			}
+			ticket.Messages = append(ticket.Messages, message)
+		}
+
+		if (statusChanged || text != "") && ticket.FirstRespondedAt == nil {
+			now := s.Clock().Now()
+			ticket.FirstRespondedAt = &now
+		}
+		return s.store.SupportTicketRepository.Update(ctx, ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.signAttachments(ticket)
+	if statusChanged || text != "" {
+		s.emailCustomer(ctx, ticket, text)
+		s.alerts.Notify(ctx, ChatAlertSupportTicketUpdated, &ticket.UserID, map[string]string{
+			"ticket_id": strconv.FormatUint(uint64(ticket.ID), 10),
+			"subject":   ticket.Subject,
+			"status":    string(ticket.Status),
+			"message":   text,
+		})
+	}
+	return ticket, nil
+}
+
+// SetUserTier changes the tier used for the user's next tickets. Open
+// tickets keep the SLA they were created with.
+func (s *supportService) SetUserTier(ctx context.Context, userID uint, tier models.SupportTier) error {
+	if _, exists := supportSLAs[tier]; !exists {
+		return fmt.Errorf("unknown support tier %s", tier)
+	}
+	return s.store.UserRepository.SetSupportTier(ctx, userID, tier)
+}
+
+// CheckBreaches flags the tickets that missed a deadline since the last
+// check and alerts the operations channels about each
+func (s *supportService) CheckBreaches(ctx context.Context) (int, error) {
+	now := s.Clock().Now()
+	flagged := 0
+
+	tickets, err := s.store.SupportTicketRepository.GetFirstResponseBreaches(ctx, now, supportBreachBatchSize)
+	if err != nil {
+		return flagged, err
+	}
+	for _, ticket := range tickets {
+		ticket.FirstResponseBreachedAt = &now
+		if err := s.store.SupportTicketRepository.Update(ctx, ticket); err != nil {
+			return flagged, err
+		}
+		s.alertBreach(ctx, ticket, "first response", ticket.FirstResponseDueAt)
+		flagged++
+	}
+
+	tickets, err = s.store.SupportTicketRepository.GetResolutionBreaches(ctx, now, supportBreachBatchSize)
+	if err != nil {
+		return flagged, err
+	}
+	for _, ticket := range tickets {
+		ticket.ResolutionBreachedAt = &now
+		if err := s.store.SupportTicketRepository.Update(ctx, ticket); err != nil {
+			return flagged, err
+		}
+		s.alertBreach(ctx, ticket, "resolution", ticket.ResolutionDueAt)
+		flagged++
+	}
+
+	return flagged, nil
+}
+
+func (s *supportService) StartSLAWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Support.SLACheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "support_sla", func(ctx context.Context) error {
+					flagged, err := s.CheckBreaches(ctx)
+					if flagged > 0 {
+						s.Logger().Warn("support tickets breached their SLA", zap.Int("count", flagged))
+					}
+					return err
+				})
+				if err != nil {
+					s.Logger().Error("support SLA check failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// transition moves the ticket to status, keeping ResolvedAt in sync
+func (s *supportService) transition(ticket *models.SupportTicket, status models.SupportTicketStatus) error {
+	if !slices.Contains(supportTransitions[ticket.Status], status) {
+		return fmt.Errorf("%w: %s to %s", ErrSupportInvalidTransition, ticket.Status, status)
+	}
+
+	ticket.Status = status
+	if ticket.IsOpen() {
+		ticket.ResolvedAt = nil
+	} else if ticket.ResolvedAt == nil {
+		now := s.Clock().Now()
+		ticket.ResolvedAt = &now
+	}
+	return nil
+}
+
+// ownedTicket loads a ticket, reporting tickets of another user as not found
+func (s *supportService) ownedTicket(ctx context.Context, userID uint, ticketID uint) (*models.SupportTicket, error) {
+	ticket, err := s.store.SupportTicketRepository.GetByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ticket, nil
+}
+
+func (s *supportService) uploadAttachments(userID uint, uploads []*dto.SupportAttachmentUpload) ([]*models.SupportTicketAttachment, error) {
+	attachments := make([]*models.SupportTicketAttachment, 0, len(uploads))
+	for _, upload := range uploads {
+		buf := make([]byte, 8)
+		rand.Read(buf)
+		key := fmt.Sprintf("support/%d/%s/%s", userID, hex.EncodeToString(buf), filepath.Base(upload.Name))
+
+		contentType := upload.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		if _, err := s.fileStorage.Upload(key, upload.Content, contentType, upload.Size); err != nil {
+			s.removeAttachments(attachments)
+			return nil, fmt.Errorf("failed to upload attachment %s: %w", upload.Name, err)
+		}
+
+		attachments = append(attachments, &models.SupportTicketAttachment{
+			Name:        upload.Name,
+			ContentType: contentType,
+			SizeBytes:   upload.Size,
+			StorageKey:  key,
+		})
+	}
+	return attachments, nil
+}
+
+func (s *supportService) removeAttachments(attachments []*models.SupportTicketAttachment) {
+	if len(attachments) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey)
+	}
+	if err := s.fileStorage.BulkDelete(keys); err != nil {
+		s.Logger().Warn("could not remove support attachments", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+// signAttachments fills in the download links of the attachments
+func (s *supportService) signAttachments(ticket *models.SupportTicket) {
+	for _, attachment := range ticket.Attachments {
+		signed, err := s.fileStorage.GetPresignedURL(attachment.StorageKey, supportAttachmentURLTTL)
+		if err != nil {
+			s.Logger().Warn("could not sign support attachment", zap.Uint("attachment_id", attachment.ID), zap.Error(err))
+			continue
+		}
+		attachment.URL = signed
+	}
+}
+
+func (s *supportService) alertBreach(ctx context.Context, ticket *models.SupportTicket, sla string, dueAt time.Time) {
+	s.Logger().Warn("support ticket breached its SLA",
+		zap.Uint("ticket_id", ticket.ID),
+		zap.String("sla", sla),
+		zap.String("tier", string(ticket.Tier)),
+	)
+	s.alerts.Notify(ctx, ChatAlertSupportSLABreached, nil, map[string]string{
+		"ticket_id":   strconv.FormatUint(uint64(ticket.ID), 10),
+		"subject":     ticket.Subject,
+		"tier":        string(ticket.Tier),
+		"sla":         sla,
+		"due_at":      dueAt.UTC().Format(time.RFC3339),
+		"assigned_to": ticket.AssignedTo,
+	})
+}
+
+// emailCustomer tells the owner of the ticket about a staff update
+func (s *supportService) emailCustomer(ctx context.Context, ticket *models.SupportTicket, message string) {
+	if s.emails == nil {
+		return
+	}
+	user, err := s.store.UserRepository.GetByID(ctx, ticket.UserID)
+	if err != nil {
+		s.Logger().Warn("could not load support ticket owner", zap.Uint("ticket_id", ticket.ID), zap.Error(err))
+		return
+	}
+	s.sendEmail(ticket, user.Email, "Your support ticket was updated", message)
+}
+
+// emailTeam tells the support inbox about a customer action
+func (s *supportService) emailTeam(ticket *models.SupportTicket, heading, message string) {
+	if s.emails == nil || s.config.Support.TeamEmail == "" {
+		return
+	}
+	s.sendEmail(ticket, s.config.Support.TeamEmail, heading, message)
+}
+
+// sendEmail delivers in the background, emails never fail the ticket
+// update that triggered them
+func (s *supportService) sendEmail(ticket *models.SupportTicket, to, heading, message string) {
+	html, err := email.RenderHTML(supportEmailTemplate, map[string]any{
+		"Ticket":  ticket,
+		"Heading": heading,
+		"Message": message,
+	})
+	if err != nil {
+		s.Logger().Error("could not render support email", zap.Uint("ticket_id", ticket.ID), zap.Error(err))
+		return
+	}
+
+	params := &email.SendEmailParams{
+		From:    s.config.Email.From,
+		To:      []string{to},
+		Subject: fmt.Sprintf("[#%d] %s", ticket.ID, ticket.Subject),
+		Html:    html,
+	}
+	go func() {
+		if _, err := s.emails.SendEmail(params); err != nil {
+			s.Logger().Warn("support email not delivered", zap.Uint("ticket_id", ticket.ID), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+)
+
+const (
+	DefaultSyncLimit = 100
+	MaxSyncLimit     = 500
+)
+
+var (
+	ErrInvalidSyncCursor = apperrors.Validation("invalid sync cursor").WithCode("INVALID_SYNC_CURSOR")
+	ErrUnknownSyncEntity = apperrors.Validation("unknown sync entity type").WithCode("UNKNOWN_SYNC_ENTITY")
+)
+
+type SyncService interface {
+	GetChanges(ctx context.Context, userID uint, cursor string, entityTypes []string, limit int) (*dto.SyncResponse, error)
+}
+
+// syncWatermark is the position reached for a single entity type. Both the
+// timestamp and the id are kept so rows sharing a timestamp are not skipped.
+type syncWatermark struct {
+	UpdatedAt time.Time `json:"u"`
+	UpdatedID uint      `json:"ui"`
+	DeletedAt time.Time `json:"d"`
+	DeletedID uint      `json:"di"`
+}
+
+type syncRecord struct {
+	ID        uint
+	ChangedAt time.Time
+	Data      any
+}
+
+type syncSource func(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]syncRecord, error)
+
+type syncService struct {
+	*Service
+	sources map[string]syncSource
+}
+
+func NewSyncService(container *Service) SyncService {
+	s := &syncService{
+		Service: container,
+	}
+
+	s.sources = map[string]syncSource{
+		models.EntityTypeUser:          s.userChanges,
+		models.EntityTypeSupportTicket: s.supportTicketChanges,
+	}
+
+	return s
+}
+
+func (s *syncService) GetChanges(ctx context.Context, userID uint, cursor string, entityTypes []string, limit int) (*dto.SyncResponse, error) {
+	if limit <= 0 {
+		limit = DefaultSyncLimit
+	}
+	if limit > MaxSyncLimit {
+		limit = MaxSyncLimit
+	}
+
+	if len(entityTypes) == 0 {
+		for entityType := range s.sources {
+			entityTypes = append(entityTypes, entityType)
+		}
+	}
+
+	watermarks, err := decodeSyncCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.SyncResponse{
+		Changes: make(map[string]*dto.SyncEntityChanges, len(entityTypes)),
+	}
+
+	for _, entityType := range entityTypes {
+		source, ok := s.sources[entityType]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSyncEntity, entityType)
+		}
+
+		mark := watermarks[entityType]
+		changes := &dto.SyncEntityChanges{
+			Updated: []any{},
+			Deleted: []uint{},
+		}
+
+		// Fetch one extra row to know whether the client has to page again
+		records, err := source(ctx, userID, mark.UpdatedAt, mark.UpdatedID, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > limit {
+			records = records[:limit]
+			changes.HasMore = true
+		}
+		for _, record := range records {
+			changes.Updated = append(changes.Updated, record.Data)
+			mark.UpdatedAt = record.ChangedAt
+			mark.UpdatedID = record.ID
+		}
+
+		tombstones, err := s.store.TombstoneRepository.GetSince(ctx, entityType, userID, mark.DeletedAt, mark.DeletedID, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(tombstones) > limit {
+			tombstones = tombstones[:limit]
+			changes.HasMore = true
+		}
+		for _, tombstone := range tombstones {
+			changes.Deleted = append(changes.Deleted, tombstone.EntityID)
+			mark.DeletedAt = tombstone.CreatedAt
+			mark.DeletedID = tombstone.ID
+		}
+
+		watermarks[entityType] = mark
+		response.Changes[entityType] = changes
+		response.HasMore = response.HasMore || changes.HasMore
+	}
+
+	response.NextCursor, err = encodeSyncCursor(watermarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *syncService) userChanges(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]syncRecord, error) {
+	users, err := s.store.UserRepository.GetUpdatedSince(ctx, userID, since, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]syncRecord, 0, len(users))
+	for _, user := range users {
+		records = append(records, syncRecord{ID: user.ID, ChangedAt: user.UpdatedAt, Data: user})
+	}
+
+	return records, nil
+}
+
+func (s *syncService) supportTicketChanges(ctx context.Context, userID uint, since time.Time, afterID uint, limit int) ([]syncRecord, error) {
+	tickets, err := s.store.SupportTicketRepository.GetUpdatedSince(ctx, userID, since, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]syncRecord, 0, len(tickets))
+	for _, ticket := range tickets {
+		records = append(records, syncRecord{ID: ticket.ID, ChangedAt: ticket.UpdatedAt, Data: ticket})
+	}
+
+	return records, nil
+}
+
+func decodeSyncCursor(cursor string) (map[string]syncWatermark, error) {
+	watermarks := make(map[string]syncWatermark)
+	if cursor == "" {
+		return watermarks, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidSyncCursor
+	}
+
+	if err := json.Unmarshal(raw, &watermarks); err != nil {
+		return nil, ErrInvalidSyncCursor
+	}
+
+	return watermarks, nil
+}
+
+func encodeSyncCursor(watermarks map[string]syncWatermark) (string, error) {
+	raw, err := json.Marshal(watermarks)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sync cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
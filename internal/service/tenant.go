@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imlargo/go-api/internal/database"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTenancyDisabled = apperrors.Validation("tenant isolation is not enabled, set TENANCY_ENABLED").WithCode("TENANCY_DISABLED")
+	ErrTenantExists    = apperrors.Conflict("tenant already exists").WithCode("TENANT_EXISTS")
+)
+
+type TenantService interface {
+	Provision(ctx context.Context, slug string) (*models.Tenant, error)
+	Deprovision(ctx context.Context, slug string) error
+	List(ctx context.Context) ([]*models.Tenant, error)
+	Exists(ctx context.Context, slug string) (bool, error)
+	MigrateAll(ctx context.Context) ([]*models.Tenant, error)
+}
+
+type tenantService struct {
+	*Service
+	db *gorm.DB
+}
+
+// NewTenantService needs the database itself because provisioning runs
+// DDL, which the repositories don't expose
+func NewTenantService(container *Service, db *gorm.DB) TenantService {
+	return &tenantService{Service: container, db: db}
+}
+
+// Provision creates the tenant schema with every migration applied, then
+// registers the tenant so requests start resolving to it
+func (s *tenantService) Provision(ctx context.Context, slug string) (*models.Tenant, error) {
+	if !s.config.Tenancy.Enabled {
+		return nil, ErrTenancyDisabled
+	}
+	if err := tenancy.ValidateSlug(slug); err != nil {
+		return nil, err
+	}
+
+	_, err := s.store.TenantRepository.GetBySlug(ctx, slug)
+	if err == nil {
+		return nil, ErrTenantExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	schema, err := database.CreateTenantSchema(s.db.WithContext(ctx), slug)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := &models.Tenant{
+		Slug:          slug,
+		Schema:        schema,
+		SchemaVersion: database.ExpectedSchemaVersion(),
+	}
+	if err := s.store.TenantRepository.Create(ctx, tenant); err != nil {
+		if dropErr := database.DropTenantSchema(s.db.WithContext(ctx), slug); dropErr != nil {
+			s.Logger().Error("could not drop schema of unregistered tenant", zap.String("tenant", slug), zap.Error(dropErr))
+		}
+		return nil, err
+	}
+
+	s.Logger().Info("tenant provisioned", zap.String("tenant", slug), zap.String("schema", schema))
+	return tenant, nil
+}
+
+// Deprovision unregisters the tenant before dropping its schema, so no
+// request is routed to a schema that is being deleted
+func (s *tenantService) Deprovision(ctx context.Context, slug string) error {
+	if !s.config.Tenancy.Enabled {
+		return ErrTenancyDisabled
+	}
+
+	tenant, err := s.store.TenantRepository.GetBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.TenantRepository.Delete(ctx, tenant.ID); err != nil {
+		return err
+	}
+	if err := database.DropTenantSchema(s.db.WithContext(ctx), slug); err != nil {
+		return err
+	}
+
+	s.Logger().Info("tenant deprovisioned", zap.String("tenant", slug), zap.String("schema", tenant.Schema))
+	return nil
+}
+
+func (s *tenantService) List(ctx context.Context) ([]*models.Tenant, error) {
+	return s.store.TenantRepository.List(ctx)
+}
+
+// Exists reports whether slug is a provisioned tenant, used to resolve the
+// tenant of each request
+func (s *tenantService) Exists(ctx context.Context, slug string) (bool, error) {
+	_, err := s.store.TenantRepository.GetBySlug(ctx, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MigrateAll applies the pending migrations to every tenant schema and
+// returns the tenants with their new version
+func (s *tenantService) MigrateAll(ctx context.Context) ([]*models.Tenant, error) {
+	if !s.config.Tenancy.Enabled {
+		return nil, ErrTenancyDisabled
+	}
+	if err := database.MigrateTenants(s.db.WithContext(ctx)); err != nil {
+		return nil, err
+	}
+	return s.store.TenantRepository.List(ctx)
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type UserService interface {
-	GetUserByID(userID uint) (*models.User, error)
+	GetUserByID(ctx context.Context, userID uint) (*models.User, error)
 }
 
 type userService struct {
@@ -20,9 +20,9 @@ func NewUserService(container *Service) UserService {
 	}
 }
 
-func (s *userService) GetUserByID(userID uint) (*models.User, error) {
+func (s *userService) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
 
-	user, err := s.store.UserRepository.GetByID(context.Background(), userID)
+	user, err := s.store.UserRepository.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imlargo/go-api/internal/dto"
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+	"github.com/imlargo/go-api/pkg/medusa/services/webhook"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookDeliveryBatchSize   = 100
+	webhookDeliveryMaxAttempts = 10
+	webhookDeliveryBaseDelay   = time.Minute
+	webhookDeliveryInterval    = 15 * time.Second
+	webhookDeliveryTimeout     = 15 * time.Second
+	webhookDeliveriesLimit     = 50
+	webhookDeliveriesMaxLimit  = 200
+)
+
+var (
+	ErrWebhookEncryptionDisabled = apperrors.Validation("webhooks require ENCRYPTION_KEY to be configured").WithCode("WEBHOOK_ENCRYPTION_DISABLED")
+	ErrUnknownWebhookEvent       = apperrors.Validation("unknown webhook event").WithCode("UNKNOWN_WEBHOOK_EVENT")
+)
+
+type WebhookService interface {
+	List(ctx context.Context) ([]*models.Webhook, error)
+	Create(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error)
+	Update(ctx context.Context, webhookID uint, req *dto.UpdateWebhookRequest) (*models.Webhook, error)
+	Delete(ctx context.Context, webhookID uint) error
+	ListDeliveries(ctx context.Context, webhookID uint, status models.WebhookDeliveryStatus, limit int) ([]*dto.WebhookDeliveryItem, error)
+	Publish(ctx context.Context, event string, data any) error
+	ProcessDeliveries(ctx context.Context) (int, error)
+	StartDeliveryWorker(ctx context.Context)
+}
+
+type webhookService struct {
+	*Service
+	sender webhook.Sender
+	events *changes.Registry
+}
+
+// NewWebhookService delivers the change events of events to the webhooks
+// subscribed to them
+func NewWebhookService(container *Service, sender webhook.Sender, events *changes.Registry) WebhookService {
+	return &webhookService{
+		Service: container,
+		sender:  sender,
+		events:  events,
+	}
+}
+
+func (s *webhookService) List(ctx context.Context) ([]*models.Webhook, error) {
+	return s.store.WebhookRepository.List(ctx)
+}
+
+// Create registers a webhook with a new secret, returned only here
+func (s *webhookService) Create(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error) {
+	if encryption.Default() == nil {
+		return nil, ErrWebhookEncryptionDisabled
+	}
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := s.validateEvents(req.Events); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	secret := "whsec_" + hex.EncodeToString(raw)
+
+	hook := &models.Webhook{
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      encryption.EncryptedString(secret),
+		Events:      req.Events,
+		Enabled:     true,
+	}
+	if err := s.store.WebhookRepository.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	return &dto.WebhookCreatedResponse{Webhook: hook, Secret: secret}, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, webhookID uint, req *dto.UpdateWebhookRequest) (*models.Webhook, error) {
+	hook, err := s.store.WebhookRepository.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := webhook.ValidateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *req.URL
+	}
+	if req.Events != nil {
+		if err := s.validateEvents(req.Events); err != nil {
+			return nil, err
+		}
+		hook.Events = req.Events
+	}
+	if req.Description != nil {
+		hook.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+
+	if err := s.store.WebhookRepository.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, webhookID uint) error {
+	if _, err := s.store.WebhookRepository.GetByID(ctx, webhookID); err != nil {
+		return err
+	}
+
+	return s.store.Transaction.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.store.WebhookRepository.Delete(ctx, webhookID)
+	})
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+func (s *webhookService) ListDeliveries(ctx context.Context, webhookID uint, status models.WebhookDeliveryStatus, limit int) ([]*dto.WebhookDeliveryItem, error) {
+	if _, err := s.store.WebhookRepository.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = webhookDeliveriesLimit
+	}
+	limit = min(limit, webhookDeliveriesMaxLimit)
+
+	deliveries, err := s.store.WebhookRepository.ListDeliveries(ctx, webhookID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*dto.WebhookDeliveryItem, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		items = append(items, &dto.WebhookDeliveryItem{
+			ID:             delivery.ID,
+			Event:          delivery.Event,
+			Status:         delivery.Status,
+			CreatedAt:      delivery.CreatedAt,
+			Attempts:       delivery.Attempts,
+			NextAttemptAt:  delivery.NextAttemptAt,
+			ResponseStatus: delivery.ResponseStatus,
+			LastError:      delivery.LastError,
+			DeliveredAt:    delivery.DeliveredAt,
+			Payload:        json.RawMessage(delivery.Payload),
+		})
+	}
+	return items, nil
+}
+
+// Publish queues event for every enabled webhook subscribed to it. The
+// delivery worker sends them.
+func (s *webhookService) Publish(ctx context.Context, event string, data any) error {
+	hooks, err := s.store.WebhookRepository.ListEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.Clock().Now()
+	var deliveries []*models.WebhookDelivery
+	var payload []byte
+	for _, hook := range hooks {
+		if !slices.Contains(hook.Events, event) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(dto.WebhookPayload{Event: event, OccurredAt: now, Data: data})
+			if err != nil {
+				return err
+			}
+		}
+
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	return s.store.WebhookRepository.CreateDeliveries(ctx, deliveries)
+}
+
+// ProcessDeliveries sends the due deliveries. Failures are retried with
+// exponential backoff until they are marked failed.
+func (s *webhookService) ProcessDeliveries(ctx context.Context) (int, error) {
+	delivered := 0
+	hooks := make(map[uint]*models.Webhook)
+
+	for {
+		deliveries, err := s.store.WebhookRepository.GetDueDeliveries(ctx, s.Clock().Now(), webhookDeliveryBatchSize)
+		if err != nil {
+			return delivered, err
+		}
+		webhookDeliveriesDue.Set(float64(len(deliveries)))
+
+		for _, delivery := range deliveries {
+			hook, exists := hooks[delivery.WebhookID]
+			if !exists {
+				if hook, err = s.store.WebhookRepository.GetByID(ctx, delivery.WebhookID); err != nil {
+					return delivered, err
+				}
+				hooks[delivery.WebhookID] = hook
+			}
+
+			s.attemptDelivery(ctx, hook, delivery)
+			if err := s.store.WebhookRepository.UpdateDelivery(ctx, delivery); err != nil {
+				return delivered, err
+			}
+			if delivery.Status == models.WebhookDeliveryDelivered {
+				delivered++
+			}
+		}
+
+		if len(deliveries) < webhookDeliveryBatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+func (s *webhookService) attemptDelivery(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) {
+	// Events queued before the webhook was disabled are not sent
+	if !hook.Enabled {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "webhook is disabled"
+		return
+	}
+
+	delivery.Attempts++
+
+	sendCtx, cancel := context.WithTimeout(ctx, webhookDeliveryTimeout)
+	defer cancel()
+
+	statusCode, err := s.sender.Send(sendCtx, &webhook.Request{
+		URL:        hook.URL,
+		Secret:     string(hook.Secret),
+		Event:      delivery.Event,
+		DeliveryID: strconv.FormatUint(uint64(delivery.ID), 10),
+		Body:       []byte(delivery.Payload),
+	})
+	delivery.ResponseStatus = statusCode
+
+	if err != nil {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= webhookDeliveryMaxAttempts {
+			webhookDeliveryAttempts.WithLabelValues("failed").Inc()
+			delivery.Status = models.WebhookDeliveryFailed
+			s.Logger().Warn("giving up on webhook delivery",
+				zap.Uint("webhook_id", hook.ID),
+				zap.Uint("delivery_id", delivery.ID),
+				zap.String("event", delivery.Event),
+				zap.Int("attempts", delivery.Attempts),
+				zap.Error(err),
+			)
+			return
+		}
+
+		delay := webhookDeliveryBaseDelay * time.Duration(math.Pow(2, float64(delivery.Attempts-1)))
+		delivery.NextAttemptAt = s.Clock().Now().Add(delay)
+		webhookDeliveryAttempts.WithLabelValues("retry").Inc()
+		return
+	}
+
+	now := s.Clock().Now()
+	delivery.Status = models.WebhookDeliveryDelivered
+	delivery.DeliveredAt = &now
+	delivery.LastError = ""
+	webhookDeliveryAttempts.WithLabelValues("delivered").Inc()
+}
+
+func (s *webhookService) StartDeliveryWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(webhookDeliveryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.runSingleton(ctx, "webhook_deliveries", func(ctx context.Context) error {
+					_, err := s.ProcessDeliveries(ctx)
+					return err
+				})
+				if err != nil {
+					s.Logger().Error("webhook delivery run failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// validateEvents checks every event is a published change event, e.g.
+// support_ticket.updated
+func (s *webhookService) validateEvents(events []string) error {
+	for _, event := range events {
+		entity, action, _ := strings.Cut(event, ".")
+		if !s.events.Publishes(entity, action) {
+			return fmt.Errorf("%w: %s", ErrUnknownWebhookEvent, event)
+		}
+	}
+	return nil
+}
@@ -7,12 +7,46 @@ import (
 
 type Store struct {
 	*medusarepo.Store
-	UserRepository repository.UserRepository
+	UserRepository            repository.UserRepository
+	TombstoneRepository       repository.TombstoneRepository
+	ApiUsageRepository        repository.ApiUsageRepository
+	CommissionRepository      repository.CommissionRepository
+	BackupRepository          repository.BackupRepository
+	StorageConfigRepository   repository.StorageConfigRepository
+	AdminActionRepository     repository.AdminActionRepository
+	SeedRepository            repository.SeedRepository
+	ActivityRepository        repository.ActivityRepository
+	StorageDeletionRepository repository.StorageDeletionRepository
+	TenantRepository          repository.TenantRepository
+	ChatChannelRepository     repository.ChatChannelRepository
+	SupportTicketRepository   repository.SupportTicketRepository
+	RefreshTokenRepository    repository.RefreshTokenRepository
+	UserIdentityRepository    repository.UserIdentityRepository
+	WebhookRepository         repository.WebhookRepository
+	AuditLogRepository        repository.AuditLogRepository
+	IncidentRepository        repository.IncidentRepository
 }
 
 func NewStore(store *medusarepo.Store) *Store {
 	return &Store{
-		Store:          store,
-		UserRepository: repository.NewUserRepository(store.BaseRepo),
+		Store:                     store,
+		UserRepository:            repository.NewUserRepository(store.BaseRepo),
+		TombstoneRepository:       repository.NewTombstoneRepository(store.BaseRepo),
+		ApiUsageRepository:        repository.NewApiUsageRepository(store.BaseRepo),
+		CommissionRepository:      repository.NewCommissionRepository(store.BaseRepo),
+		BackupRepository:          repository.NewBackupRepository(store.BaseRepo),
+		StorageConfigRepository:   repository.NewStorageConfigRepository(store.BaseRepo),
+		AdminActionRepository:     repository.NewAdminActionRepository(store.BaseRepo),
+		SeedRepository:            repository.NewSeedRepository(store.BaseRepo),
+		ActivityRepository:        repository.NewActivityRepository(store.BaseRepo),
+		StorageDeletionRepository: repository.NewStorageDeletionRepository(store.BaseRepo),
+		TenantRepository:          repository.NewTenantRepository(store.BaseRepo),
+		ChatChannelRepository:     repository.NewChatChannelRepository(store.BaseRepo),
+		SupportTicketRepository:   repository.NewSupportTicketRepository(store.BaseRepo),
+		RefreshTokenRepository:    repository.NewRefreshTokenRepository(store.BaseRepo),
+		UserIdentityRepository:    repository.NewUserIdentityRepository(store.BaseRepo),
+		WebhookRepository:         repository.NewWebhookRepository(store.BaseRepo),
+		AuditLogRepository:        repository.NewAuditLogRepository(store.BaseRepo),
+		IncidentRepository:        repository.NewIncidentRepository(store.BaseRepo),
 	}
 }
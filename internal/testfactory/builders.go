@@ -0,0 +1,246 @@
+package testfactory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/imlargo/go-api/internal/models"
+	"github.com/imlargo/go-api/pkg/medusa/core/encryption"
+)
+
+type UserBuilder struct {
+	f    *Factory
+	user models.User
+}
+
+func (f *Factory) User() *UserBuilder {
+	n := f.next()
+	return &UserBuilder{f: f, user: models.User{
+		Email:       fmt.Sprintf("user%d-%s@example.test", n, f.token()),
+		SupportTier: models.SupportTierStandard,
+	}}
+}
+
+func (b *UserBuilder) Email(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+func (b *UserBuilder) SupportTier(tier models.SupportTier) *UserBuilder {
+	b.user.SupportTier = tier
+	return b
+}
+
+// Build returns the user without storing it
+func (b *UserBuilder) Build() *models.User {
+	user := b.user
+	return &user
+}
+
+func (b *UserBuilder) Create() *models.User {
+	b.f.tb.Helper()
+	user := b.Build()
+	b.f.create(user)
+	return user
+}
+
+type CommissionRuleBuilder struct {
+	f    *Factory
+	rule models.CommissionRule
+}
+
+// CommissionRule builds a rule for every category and seller, in effect
+// since a day before Now
+func (f *Factory) CommissionRule() *CommissionRuleBuilder {
+	return &CommissionRuleBuilder{f: f, rule: models.CommissionRule{
+		RateBps:       f.intn(100, 2_000),
+		EffectiveFrom: f.now.Add(-24 * time.Hour),
+	}}
+}
+
+func (b *CommissionRuleBuilder) Category(category string) *CommissionRuleBuilder {
+	b.rule.Category = category
+	return b
+}
+
+func (b *CommissionRuleBuilder) Seller(seller *models.User) *CommissionRuleBuilder {
+	b.rule.SellerID = &seller.ID
+	return b
+}
+
+func (b *CommissionRuleBuilder) RateBps(rateBps int) *CommissionRuleBuilder {
+	b.rule.RateBps = rateBps
+	return b
+}
+
+func (b *CommissionRuleBuilder) MinCompletedOrders(orders int) *CommissionRuleBuilder {
+	b.rule.MinCompletedOrders = orders
+	return b
+}
+
+func (b *CommissionRuleBuilder) Effective(from time.Time, to *time.Time) *CommissionRuleBuilder {
+	b.rule.EffectiveFrom = from
+	b.rule.EffectiveTo = to
+	return b
+}
+
+func (b *CommissionRuleBuilder) Build() *models.CommissionRule {
+	rule := b.rule
+	return &rule
+}
+
+func (b *CommissionRuleBuilder) Create() *models.CommissionRule {
+	b.f.tb.Helper()
+	rule := b.Build()
+	b.f.create(rule)
+	return rule
+}
+
+type SupportTicketBuilder struct {
+	f        *Factory
+	ticket   models.SupportTicket
+	user     *models.User
+	messages []string
+}
+
+// SupportTicket builds an open ticket due a day from Now. Create stores a
+// user unless one is given, and the ticket takes the tier of its user.
+func (f *Factory) SupportTicket() *SupportTicketBuilder {
+	n := f.next()
+	categories := []models.SupportTicketCategory{
+		models.SupportCategoryBilling,
+		models.SupportCategoryTechnical,
+		models.SupportCategoryAccount,
+		models.SupportCategoryOther,
+	}
+	return &SupportTicketBuilder{f: f, ticket: models.SupportTicket{
+		Category:           categories[f.rand.IntN(len(categories))],
+		Subject:            fmt.Sprintf("Ticket %d", n),
+		Description:        "Something went wrong",
+		Status:             models.SupportTicketOpen,
+		FirstResponseDueAt: f.now.Add(8 * time.Hour),
+		ResolutionDueAt:    f.now.Add(24 * time.Hour),
+	}}
+}
+
+func (b *SupportTicketBuilder) User(user *models.User) *SupportTicketBuilder {
+	b.user = user
+	return b
+}
+
+func (b *SupportTicketBuilder) Category(category models.SupportTicketCategory) *SupportTicketBuilder {
+	b.ticket.Category = category
+	return b
+}
+
+func (b *SupportTicketBuilder) Subject(subject string) *SupportTicketBuilder {
+	b.ticket.Subject = subject
+	return b
+}
+
+func (b *SupportTicketBuilder) Status(status models.SupportTicketStatus) *SupportTicketBuilder {
+	b.ticket.Status = status
+	if !b.ticket.IsOpen() {
+		now := b.f.now
+		b.ticket.ResolvedAt = &now
+	}
+	return b
+}
+
+func (b *SupportTicketBuilder) AssignedTo(assignee string) *SupportTicketBuilder {
+	b.ticket.AssignedTo = assignee
+	return b
+}
+
+func (b *SupportTicketBuilder) Due(firstResponse, resolution time.Time) *SupportTicketBuilder {
+	b.ticket.FirstResponseDueAt = firstResponse
+	b.ticket.ResolutionDueAt = resolution
+	return b
+}
+
+// Message adds a reply from the user, stored with the ticket
+func (b *SupportTicketBuilder) Message(body string) *SupportTicketBuilder {
+	b.messages = append(b.messages, body)
+	return b
+}
+
+// Build returns the ticket without storing it or its user. A user that
+// wasn't given is left as id 0.
+func (b *SupportTicketBuilder) Build() *models.SupportTicket {
+	ticket := b.ticket
+	ticket.Tier = models.SupportTierStandard
+	if b.user != nil {
+		ticket.UserID = b.user.ID
+		ticket.Tier = b.user.SupportTier
+	}
+	for _, body := range b.messages {
+		ticket.Messages = append(ticket.Messages, &models.SupportTicketMessage{Body: body})
+	}
+	return &ticket
+}
+
+func (b *SupportTicketBuilder) Create() *models.SupportTicket {
+	b.f.tb.Helper()
+	if b.user == nil {
+		b.user = b.f.User().Create()
+	}
+
+	ticket := b.Build()
+	b.f.create(ticket)
+	return ticket
+}
+
+type WebhookBuilder struct {
+	f       *Factory
+	webhook models.Webhook
+}
+
+// Webhook builds an enabled webhook subscribed to support ticket updates
+func (f *Factory) Webhook() *WebhookBuilder {
+	n := f.next()
+	return &WebhookBuilder{f: f, webhook: models.Webhook{
+		URL:     fmt.Sprintf("https://hooks.example.test/%d", n),
+		Secret:  encryption.EncryptedString("whsec_" + f.token() + f.token()),
+		Events:  []string{models.EntityTypeSupportTicket + ".updated"},
+		Enabled: true,
+	}}
+}
+
+func (b *WebhookBuilder) URL(url string) *WebhookBuilder {
+	b.webhook.URL = url
+	return b
+}
+
+func (b *WebhookBuilder) Secret(secret string) *WebhookBuilder {
+	b.webhook.Secret = encryption.EncryptedString(secret)
+	return b
+}
+
+func (b *WebhookBuilder) Events(events ...string) *WebhookBuilder {
+	b.webhook.Events = events
+	return b
+}
+
+func (b *WebhookBuilder) Disabled() *WebhookBuilder {
+	b.webhook.Enabled = false
+	return b
+}
+
+func (b *WebhookBuilder) Build() *models.Webhook {
+	webhook := b.webhook
+	return &webhook
+}
+
+func (b *WebhookBuilder) Create() *models.Webhook {
+	b.f.tb.Helper()
+	webhook := b.Build()
+	b.f.create(webhook)
+	// Enabled defaults to true in the database, a false value is skipped
+	// on insert
+	if !b.webhook.Enabled {
+		if err := b.f.db.Model(webhook).Update("enabled", false).Error; err != nil {
+			b.f.tb.Fatalf("testfactory: could not disable webhook: %v", err)
+		}
+	}
+	return webhook
+}
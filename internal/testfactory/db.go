@@ -0,0 +1,36 @@
+package testfactory
+
+import (
+	"os"
+	"testing"
+
+	"github.com/imlargo/go-api/internal/database"
+	"gorm.io/gorm"
+)
+
+// OpenDB connects to the scratch database of TEST_DATABASE_URL and applies
+// the migrations. Tests calling it are skipped when the variable is unset,
+// so go test passes without Postgres.
+func OpenDB(tb testing.TB) *gorm.DB {
+	tb.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresDatabase(url, database.PoolConfig{})
+	if err != nil {
+		tb.Fatalf("testfactory: could not connect: %v", err)
+	}
+	tb.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := database.Migrate(db); err != nil {
+		tb.Fatalf("testfactory: could not migrate: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,74 @@
+package testfactory
+
+import (
+	"testing"
+
+	"github.com/imlargo/go-api/internal/models"
+)
+
+func TestSameSeedBuildsSameDefaults(t *testing.T) {
+	first, second := New(t, nil, 7), New(t, nil, 7)
+
+	for range 3 {
+		a, b := first.User().Build(), second.User().Build()
+		if a.Email != b.Email {
+			t.Fatalf("emails differ for the same seed: %s and %s", a.Email, b.Email)
+		}
+
+		ruleA, ruleB := first.CommissionRule().Build(), second.CommissionRule().Build()
+		if ruleA.RateBps != ruleB.RateBps || !ruleA.EffectiveFrom.Equal(ruleB.EffectiveFrom) {
+			t.Fatalf("rules differ for the same seed: %+v and %+v", ruleA, ruleB)
+		}
+
+		ticketA, ticketB := first.SupportTicket().Build(), second.SupportTicket().Build()
+		if ticketA.Category != ticketB.Category || ticketA.Subject != ticketB.Subject {
+			t.Fatalf("tickets differ for the same seed: %+v and %+v", ticketA, ticketB)
+		}
+	}
+
+	other := New(t, nil, 8).User().Build()
+	if other.Email == New(t, nil, 7).User().Build().Email {
+		t.Errorf("different seeds built the same email %s", other.Email)
+	}
+}
+
+func TestBuildersKeepUniqueColumnsUnique(t *testing.T) {
+	f := New(t, nil, 1)
+
+	emails := make(map[string]bool)
+	urls := make(map[string]bool)
+	for range 50 {
+		email := f.User().Build().Email
+		if emails[email] {
+			t.Fatalf("email %s built twice", email)
+		}
+		emails[email] = true
+
+		url := f.Webhook().Build().URL
+		if urls[url] {
+			t.Fatalf("webhook url %s built twice", url)
+		}
+		urls[url] = true
+	}
+}
+
+func TestSupportTicketStatusSetsTimestamps(t *testing.T) {
+	f := New(t, nil, 1)
+
+	if open := f.SupportTicket().Build(); open.ResolvedAt != nil {
+		t.Errorf("open ticket has ResolvedAt %v", open.ResolvedAt)
+	}
+
+	resolved := f.SupportTicket().Status(models.SupportTicketResolved).Build()
+	if resolved.ResolvedAt == nil || !resolved.ResolvedAt.Equal(f.Now()) {
+		t.Errorf("ResolvedAt = %v, want %v", resolved.ResolvedAt, f.Now())
+	}
+
+	user := &models.User{ID: 4, SupportTier: models.SupportTierEnterprise}
+	if ticket := f.SupportTicket().User(user).Build(); ticket.UserID != 4 || ticket.Tier != models.SupportTierEnterprise {
+		t.Errorf("Build() = user %d tier %s, want 4 and %s", ticket.UserID, ticket.Tier, models.SupportTierEnterprise)
+	}
+	if ticket := f.SupportTicket().Build(); ticket.UserID != 0 {
+		t.Errorf("Build() without a user = user %d, want 0", ticket.UserID)
+	}
+}
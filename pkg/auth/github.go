@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+)
+
+type githubProvider struct {
+	oauthClient
+}
+
+// NewGitHubProvider signs users in with their GitHub account
+func NewGitHubProvider(config Config) Provider {
+	return &githubProvider{newOAuthClient(config, endpoints{
+		authURL:  "https://github.com/login/oauth/authorize",
+		tokenURL: "https://github.com/login/oauth/access_token",
+		scopes:   []string{"read:user", "user:email"},
+	})}
+}
+
+func (p *githubProvider) Name() string {
+	return "github"
+}
+
+func (p *githubProvider) AuthCodeURL(state string) string {
+	return p.authCodeURL(state)
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code string) (*Identity, error) {
+	accessToken, err := p.exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.getJSON(ctx, "https://api.github.com/user", accessToken, &user); err != nil {
+		return nil, err
+	}
+
+	// The profile email is optional and unverified, the primary address
+	// of the account is the one GitHub vouches for
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, "https://api.github.com/user/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: p.Name(),
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+	if identity.Email == "" {
+		return nil, ErrEmailUnavailable
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import "context"
+
+type googleProvider struct {
+	oauthClient
+}
+
+// NewGoogleProvider signs users in with their Google account through
+// OpenID Connect
+func NewGoogleProvider(config Config) Provider {
+	return &googleProvider{newOAuthClient(config, endpoints{
+		authURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		tokenURL: "https://oauth2.googleapis.com/token",
+		scopes:   []string{"openid", "email", "profile"},
+	})}
+}
+
+func (p *googleProvider) Name() string {
+	return "google"
+}
+
+func (p *googleProvider) AuthCodeURL(state string) string {
+	return p.authCodeURL(state)
+}
+
+func (p *googleProvider) Exchange(ctx context.Context, code string) (*Identity, error) {
+	accessToken, err := p.exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := p.getJSON(ctx, "https://openidconnect.googleapis.com/v1/userinfo", accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, ErrEmailUnavailable
+	}
+
+	return &Identity{
+		Provider:      p.Name(),
+		Subject:       user.Sub,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Name:          user.Name,
+	}, nil
+}
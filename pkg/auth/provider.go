@@ -0,0 +1,136 @@
+// Package auth signs users in with third party OAuth2 providers. Each
+// provider runs the authorization code flow and reports who signed in.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrEmailUnavailable = errors.New("provider did not return an email")
+
+// Config is the OAuth app registered with a provider. RedirectURL must be
+// one of the callback URLs of the app.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Identity is the account a user signed in with. Subject is the stable id
+// of the account at the provider; emails can change.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider interface {
+	Name() string
+	// AuthCodeURL is the consent page the user is sent to. state comes back
+	// untouched on the callback.
+	AuthCodeURL(state string) string
+	// Exchange trades the code of the callback for the identity of the user
+	Exchange(ctx context.Context, code string) (*Identity, error)
+}
+
+type endpoints struct {
+	authURL  string
+	tokenURL string
+	scopes   []string
+}
+
+// oauthClient holds what the providers share: building the consent URL and
+// exchanging the code for an access token
+type oauthClient struct {
+	config    Config
+	endpoints endpoints
+	http      *http.Client
+}
+
+func newOAuthClient(config Config, endpoints endpoints) oauthClient {
+	return oauthClient{
+		config:    config,
+		endpoints: endpoints,
+		http:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c oauthClient) authCodeURL(state string) string {
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.config.ClientID},
+		"redirect_uri":  {c.config.RedirectURL},
+		"scope":         {strings.Join(c.endpoints.scopes, " ")},
+		"state":         {state},
+	}
+	return c.endpoints.authURL + "?" + params.Encode()
+}
+
+func (c oauthClient) exchange(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.config.RedirectURL},
+		"client_id":     {c.config.ClientID},
+		"client_secret": {c.config.ClientSecret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := c.do(req, &token); err != nil {
+		return "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+	// GitHub reports a bad code with a 200 and an error field
+	if token.Error != "" {
+		return "", fmt.Errorf("failed to exchange code: %s: %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("failed to exchange code: no access token returned")
+	}
+	return token.AccessToken, nil
+}
+
+// getJSON reads a provider API with the access token of the user
+func (c oauthClient) getJSON(ctx context.Context, endpoint, accessToken string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return c.do(req, out)
+}
+
+func (c oauthClient) do(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -6,19 +6,32 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/imlargo/go-api/pkg/medusa/core/server"
 )
 
+const defaultShutdownTimeout = 15 * time.Second
+
+// ShutdownHook releases a resource once the servers stopped, e.g. closes a
+// client. ctx expires with the shutdown timeout.
+type ShutdownHook func(ctx context.Context) error
+
 type App struct {
-	name    string
-	servers []server.Server
+	name            string
+	servers         []server.Server
+	shutdownTimeout time.Duration
+	hooks           []ShutdownHook
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 type Option func(a *App)
 
 func NewApp(opts ...Option) *App {
-	a := &App{}
+	a := &App{shutdownTimeout: defaultShutdownTimeout}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
 	for _, opt := range opts {
 		opt(a)
 	}
@@ -37,17 +50,42 @@ func WithName(name string) Option {
 	}
 }
 
+// WithShutdownTimeout bounds the time in-flight requests get to finish and
+// the shutdown hooks get to run
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(a *App) {
+		if timeout > 0 {
+			a.shutdownTimeout = timeout
+		}
+	}
+}
+
+// Context is cancelled as soon as the app starts shutting down. Background
+// workers should run with it so they stop before the resources they use are
+// closed.
+func (a *App) Context() context.Context {
+	return a.ctx
+}
+
+// OnShutdown registers a hook run after the servers drained. Hooks run in
+// reverse order of registration, so resources opened first close last.
+func (a *App) OnShutdown(hook ShutdownHook) {
+	a.hooks = append(a.hooks, hook)
+}
+
+// Run starts the servers and blocks until SIGINT, SIGTERM or ctx ends. It
+// then stops the background workers, drains the servers and runs the
+// shutdown hooks, all within the shutdown timeout.
 func (a *App) Run(ctx context.Context) error {
-	var cancel context.CancelFunc
-	ctx, cancel = context.WithCancel(ctx)
-	defer cancel()
+	defer a.cancel()
 
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
 
 	for _, srv := range a.servers {
 		go func(srv server.Server) {
-			err := srv.Start(ctx)
+			err := srv.Start(a.ctx)
 			if err != nil {
 				log.Printf("Server start err: %v", err)
 			}
@@ -63,13 +101,25 @@ func (a *App) Run(ctx context.Context) error {
 		log.Println("Context canceled")
 	}
 
+	a.cancel()
+
+	// Not derived from ctx, which may be the reason we are stopping
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
 	// Gracefully stop the servers
 	for _, srv := range a.servers {
-		err := srv.Stop(ctx)
+		err := srv.Stop(shutdownCtx)
 		if err != nil {
 			log.Printf("Server stop err: %v", err)
 		}
 	}
 
+	for i := len(a.hooks) - 1; i >= 0; i-- {
+		if err := a.hooks[i](shutdownCtx); err != nil {
+			log.Printf("Shutdown hook err: %v", err)
+		}
+	}
+
 	return nil
 }
@@ -11,6 +11,8 @@ type Config struct {
 type ServerConfig struct {
 	Host string
 	Port int
+	// ShutdownTimeout is how long in-flight requests get to finish on exit
+	ShutdownTimeout time.Duration
 }
 
 type AuthConfig struct {
@@ -22,4 +24,11 @@ type AuthConfig struct {
 
 type DbConfig struct {
 	URL string
+	// ReplicaURL is an optional read replica for lag tolerant reads
+	ReplicaURL string
+
+	// Pool limits, applied to the primary and the replica each
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
 }
@@ -0,0 +1,133 @@
+// Package apperrors types the expected failures services return, so a
+// single middleware can turn them into HTTP responses. The Kind picks the
+// status and the Code is what clients switch on.
+//
+//	var ErrTicketClosed = apperrors.Conflict("ticket is closed").WithCode("SUPPORT_TICKET_CLOSED")
+//
+// Anything that isn't an *Error is reported as an internal error.
+package apperrors
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindValidation
+	KindConflict
+	KindForbidden
+	KindRateLimited
+	KindUnauthorized
+)
+
+// defaultCodes are the codes of errors created without WithCode, the same
+// as the generic codes of the responses package
+var defaultCodes = map[Kind]string{
+	KindInternal:     "INTERNAL_SERVER_ERROR",
+	KindNotFound:     "NOT_FOUND",
+	KindValidation:   "VALIDATION_FAILED",
+	KindConflict:     "CONFLICT",
+	KindForbidden:    "FORBIDDEN",
+	KindRateLimited:  "TOO_MANY_REQUESTS",
+	KindUnauthorized: "UNAUTHORIZED",
+}
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details any
+
+	cause error
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Code: defaultCodes[kind], Message: message}
+}
+
+// NotFound reports a missing resource, e.g. NotFound("support ticket")
+func NotFound(resource string) *Error {
+	return New(KindNotFound, resource+" not found")
+}
+
+func Validation(message string) *Error {
+	return New(KindValidation, message)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+func RateLimited(message string) *Error {
+	return New(KindRateLimited, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors of the same kind and code, so copies made by the With
+// methods still match the sentinel they came from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// WithCode returns a copy of e with a machine-readable code
+func (e *Error) WithCode(code string) *Error {
+	copied := *e
+	copied.Code = code
+	return &copied
+}
+
+// WithDetails returns a copy of e carrying details for the response
+func (e *Error) WithDetails(details any) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap returns a copy of e caused by err
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.cause = err
+	return &copied
+}
+
+// As returns the *Error in the chain of err
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// NotFoundIf reports a gorm record not found error as a missing resource,
+// naming it in the response. Other errors are returned unchanged.
+func NotFoundIf(err error, resource string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound(resource).Wrap(err)
+	}
+	return err
+}
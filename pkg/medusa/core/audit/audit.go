@@ -0,0 +1,337 @@
+// Package audit records the writes made while handling a request. The GORM
+// plugin notes every committed create, update and delete run with a
+// context that carries a Recorder, with the columns it changed when the
+// row is known by primary key: a write by id costs one extra primary key
+// lookup, bulk writes are recorded without a diff.
+//
+// Columns hidden from the API with a json:"-" tag, like secrets and token
+// hashes, are never recorded.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const beforeKey = "audit:before"
+
+type ctxKey struct{}
+
+// Change is the value of a column before and after a write. Before is
+// empty for creates and After for deletes.
+type Change struct {
+	Before any `json:"before,omitempty"`
+	After  any `json:"after,omitempty"`
+}
+
+// Entry is a committed write, e.g. an update of support_tickets 12
+type Entry struct {
+	Entity   string            `json:"entity"`
+	EntityID string            `json:"entity_id,omitempty"`
+	Action   string            `json:"action"`
+	Changes  map[string]Change `json:"changes,omitempty"`
+}
+
+// Request is a mutating API request and the writes it made
+type Request struct {
+	// Actor is user:<id> for authenticated users, api_key:<role> for API
+	// keys, or anonymous
+	Actor     string
+	UserID    *uint
+	Method    string
+	Route     string
+	Path      string
+	Status    int
+	IP        string
+	RequestID string
+	Entries   []Entry
+}
+
+// Recorder collects the entries of a request
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+	stopped bool
+}
+
+// WithRecorder returns a context that records the writes run with it
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	recorder := &Recorder{}
+	return context.WithValue(ctx, ctxKey{}, recorder), recorder
+}
+
+// Entries returns the recorded entries and stops recording, so writing
+// them doesn't record the audit log itself
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopped = true
+	return append([]Entry(nil), r.entries...)
+}
+
+func (r *Recorder) add(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.stopped {
+		r.entries = append(r.entries, entry)
+	}
+}
+
+func recorderFrom(ctx context.Context) *Recorder {
+	if ctx == nil {
+		return nil
+	}
+	recorder, _ := ctx.Value(ctxKey{}).(*Recorder)
+	if recorder == nil || recorder.isStopped() {
+		return nil
+	}
+	return recorder
+}
+
+func (r *Recorder) isStopped() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopped
+}
+
+type plugin struct{}
+
+// New returns a GORM plugin that records writes to the Recorder of their
+// context
+func New() gorm.Plugin {
+	return &plugin{}
+}
+
+func (p *plugin) Name() string {
+	return "audit"
+}
+
+func (p *plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	registrations := []error{
+		callbacks.Update().Before("gorm:update").Register("audit:before_update", p.snapshotBefore),
+		callbacks.Delete().Before("gorm:delete").Register("audit:before_delete", p.snapshotBefore),
+		callbacks.Create().After("gorm:create").Register("audit:create", p.record(changes.ActionCreated)),
+		callbacks.Update().After("gorm:update").Register("audit:update", p.record(changes.ActionUpdated)),
+		callbacks.Delete().After("gorm:delete").Register("audit:delete", p.record(changes.ActionDeleted)),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotBefore loads the row an update or delete is about to change
+func (p *plugin) snapshotBefore(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || recorderFrom(db.Statement.Context) == nil {
+		return
+	}
+
+	id, ok := primaryKey(db.Statement)
+	if !ok {
+		return
+	}
+	if row := snapshot(db, id); row != nil {
+		db.InstanceSet(beforeKey, row)
+	}
+}
+
+func (p *plugin) record(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		recorder := recorderFrom(stmt.Context)
+		if db.Error != nil || db.RowsAffected == 0 || stmt.Schema == nil || recorder == nil {
+			return
+		}
+
+		for _, entry := range entries(db, action) {
+			medusarepo.AfterCommit(stmt.Context, func() {
+				recorder.add(entry)
+			})
+		}
+	}
+}
+
+func entries(db *gorm.DB, action string) []Entry {
+	stmt := db.Statement
+
+	// Batch creates are recorded without a diff
+	if action == changes.ActionCreated && stmt.ReflectValue.Kind() == reflect.Slice {
+		var created []Entry
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			id, _ := primaryKeyOf(stmt, stmt.ReflectValue.Index(i))
+			created = append(created, Entry{Entity: stmt.Table, EntityID: formatID(id), Action: action})
+		}
+		return created
+	}
+
+	entry := Entry{Entity: stmt.Table, Action: action}
+	id, ok := primaryKey(stmt)
+	if ok {
+		entry.EntityID = formatID(id)
+	}
+
+	before, _ := db.InstanceGet(beforeKey)
+	beforeRow, _ := before.(map[string]any)
+
+	switch {
+	case action == changes.ActionDeleted:
+		entry.Changes = diff(stmt.Schema, beforeRow, nil)
+	case ok:
+		entry.Changes = diff(stmt.Schema, beforeRow, snapshot(db, id))
+	default:
+		// Bulk updates only know the values they set
+		entry.Changes = diff(stmt.Schema, nil, assignments(stmt))
+	}
+	return []Entry{entry}
+}
+
+// snapshot loads the row with primary key id in the connection of db, so
+// it sees the uncommitted writes of its transaction
+func snapshot(db *gorm.DB, id any) map[string]any {
+	stmt := db.Statement
+	row := map[string]any{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table(stmt.Table).
+		Where(clause.Eq{Column: clause.Column{Name: stmt.Schema.PrioritizedPrimaryField.DBName}, Value: id}).
+		Limit(1).
+		Find(&row).Error
+	if err != nil || len(row) == 0 {
+		return nil
+	}
+	return row
+}
+
+// primaryKey returns the primary key of the single row a statement writes,
+// from its model or its where clause
+func primaryKey(stmt *gorm.Statement) (any, bool) {
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil, false
+	}
+
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		if id, ok := primaryKeyOf(stmt, stmt.ReflectValue); ok {
+			return id, true
+		}
+	}
+
+	where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok {
+		return nil, false
+	}
+	for _, expr := range where.Exprs {
+		switch expr := expr.(type) {
+		case clause.Eq:
+			if isPrimaryColumn(expr.Column, field) {
+				return expr.Value, true
+			}
+		case clause.IN:
+			if isPrimaryColumn(expr.Column, field) && len(expr.Values) == 1 {
+				return expr.Values[0], true
+			}
+		case clause.Expr:
+			if len(expr.Vars) == 1 && strings.ReplaceAll(expr.SQL, " ", "") == field.DBName+"=?" {
+				return expr.Vars[0], true
+			}
+		}
+	}
+	return nil, false
+}
+
+func primaryKeyOf(stmt *gorm.Statement, value reflect.Value) (any, bool) {
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil, false
+	}
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	id, zero := field.ValueOf(stmt.Context, value)
+	return id, !zero
+}
+
+func isPrimaryColumn(column any, field *schema.Field) bool {
+	col, ok := column.(clause.Column)
+	if !ok {
+		name, _ := column.(string)
+		return name == field.DBName
+	}
+	return col.Name == clause.PrimaryKey || col.Name == field.DBName
+}
+
+// assignments returns the columns set by an update given a map, e.g.
+// Updates(map[string]any{"status": "released"})
+func assignments(stmt *gorm.Statement) map[string]any {
+	values, ok := stmt.Dest.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	row := make(map[string]any, len(values))
+	for name, value := range values {
+		if field := stmt.Schema.LookUpField(name); field != nil {
+			row[field.DBName] = value
+		}
+	}
+	return row
+}
+
+// diff returns the columns that differ between before and after, leaving
+// out the primary key, hidden and timestamp columns
+func diff(s *schema.Schema, before, after map[string]any) map[string]Change {
+	columns := make(map[string]bool, len(before)+len(after))
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+
+	result := make(map[string]Change)
+	for column := range columns {
+		field := s.LookUpField(column)
+		if field == nil || field.PrimaryKey || field.Tag.Get("json") == "-" || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+			continue
+		}
+
+		beforeValue, afterValue := before[column], after[column]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		result[column] = Change{Before: beforeValue, After: afterValue}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func formatID(id any) string {
+	if id == nil {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
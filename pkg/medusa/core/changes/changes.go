@@ -0,0 +1,208 @@
+// Package changes turns repository writes into change events. Models that
+// implement Tracked are announced to their audience after the write
+// commits, e.g. a support ticket update becomes a "support_ticket.updated"
+// event for the user who opened it.
+package changes
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// Tracked is implemented by models whose writes are announced
+type Tracked interface {
+	// ChangeEntity is the entity type, e.g. support_ticket
+	ChangeEntity() string
+	// ChangeAudience are the users notified of a change
+	ChangeAudience() []uint
+}
+
+// Schema documents the change events of an entity type
+type Schema struct {
+	Entity      string   `json:"entity"`
+	Actions     []string `json:"actions"`
+	Description string   `json:"description"`
+}
+
+// EventName is the name of the event sent for an action on entity
+func EventName(entity, action string) string {
+	return entity + "." + action
+}
+
+// EntityOf returns the entity of a change event name, or "" when the name
+// is not a change event
+func EntityOf(event string) string {
+	entity, _, found := strings.Cut(event, ".")
+	if !found {
+		return ""
+	}
+	return entity
+}
+
+// Registry holds the schemas of the entities whose changes are published.
+// Writes of unregistered entities are not announced.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]Schema
+}
+
+func NewRegistry(schemas ...Schema) *Registry {
+	registry := &Registry{schemas: make(map[string]Schema)}
+	for _, schema := range schemas {
+		registry.Register(schema)
+	}
+	return registry
+}
+
+func (r *Registry) Register(schema Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[schema.Entity] = schema
+}
+
+// Publishes reports whether action on entity is a registered event
+func (r *Registry) Publishes(entity, action string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[entity]
+	if !ok {
+		return false
+	}
+	for _, registered := range schema.Actions {
+		if registered == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Registry) Has(entity string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.schemas[entity]
+	return ok
+}
+
+func (r *Registry) Schemas() []Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schemas := make([]Schema, 0, len(r.schemas))
+	for _, schema := range r.schemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Entity < schemas[j].Entity
+	})
+	return schemas
+}
+
+// Event is a committed write of a tracked model
+type Event struct {
+	Name     string
+	Entity   string
+	Action   string
+	Audience []uint
+	Model    any
+}
+
+// Notifier delivers an event to its audience
+type Notifier func(ctx context.Context, event *Event)
+
+type plugin struct {
+	registry *Registry
+	notify   Notifier
+}
+
+// New returns a GORM plugin that notifies the registered changes
+func New(registry *Registry, notify Notifier) gorm.Plugin {
+	return &plugin{registry: registry, notify: notify}
+}
+
+func (p *plugin) Name() string {
+	return "changes"
+}
+
+func (p *plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	registrations := []error{
+		callbacks.Create().After("gorm:create").Register("changes:create", p.callback(ActionCreated)),
+		callbacks.Update().After("gorm:update").Register("changes:update", p.callback(ActionUpdated)),
+		callbacks.Delete().After("gorm:delete").Register("changes:delete", p.callback(ActionDeleted)),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) callback(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.RowsAffected == 0 {
+			return
+		}
+
+		ctx := db.Statement.Context
+		for _, tracked := range trackedModels(db.Statement.ReflectValue) {
+			entity := tracked.ChangeEntity()
+			if !p.registry.Publishes(entity, action) {
+				continue
+			}
+
+			event := &Event{
+				Name:     EventName(entity, action),
+				Entity:   entity,
+				Action:   action,
+				Audience: tracked.ChangeAudience(),
+				Model:    tracked,
+			}
+			medusarepo.AfterCommit(ctx, func() {
+				p.notify(ctx, event)
+			})
+		}
+	}
+}
+
+func trackedModels(value reflect.Value) []Tracked {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		var models []Tracked
+		for i := 0; i < value.Len(); i++ {
+			models = append(models, trackedModels(value.Index(i))...)
+		}
+		return models
+	case reflect.Struct:
+		if value.CanAddr() {
+			if tracked, ok := value.Addr().Interface().(Tracked); ok {
+				return []Tracked{tracked}
+			}
+		}
+		if tracked, ok := value.Interface().(Tracked); ok {
+			return []Tracked{tracked}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package encryption
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	defaultMu        sync.RWMutex
+	defaultEncryptor *Encryptor
+)
+
+var ErrNoEncryptor = errors.New("no default encryptor configured")
+
+// SetDefault sets the encryptor used by EncryptedString. Passing nil
+// disables encryption of new values.
+func SetDefault(e *Encryptor) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultEncryptor = e
+}
+
+// Default returns the configured encryptor, or nil
+func Default() *Encryptor {
+	return getDefault()
+}
+
+func getDefault() *Encryptor {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultEncryptor
+}
+
+// EncryptedString is a string column encrypted at rest with the default
+// encryptor. When no encryptor is configured values are stored as plain
+// text, so encryption can be enabled on an existing table.
+type EncryptedString string
+
+// Value implements driver.Valuer
+func (s EncryptedString) Value() (driver.Value, error) {
+	e := getDefault()
+	if e == nil || s == "" {
+		return string(s), nil
+	}
+	return e.Encrypt(string(s))
+}
+
+// Scan implements sql.Scanner
+func (s *EncryptedString) Scan(value any) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EncryptedString", value)
+	}
+
+	if !IsEncrypted(raw) {
+		*s = EncryptedString(raw)
+		return nil
+	}
+
+	e := getDefault()
+	if e == nil {
+		return ErrNoEncryptor
+	}
+
+	plaintext, err := e.Decrypt(raw)
+	if err != nil {
+		return err
+	}
+
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// GormDataType keeps the column a text column
+func (EncryptedString) GormDataType() string {
+	return "text"
+}
+
+// String returns the plain value
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// Redacted is the representation to use in logs and error reports
+func (s EncryptedString) Redacted() string {
+	if s == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+const errorRateBuckets = 10
+
+// ErrorRate counts requests and failures over a sliding window, in ten
+// buckets so old failures age out gradually
+type ErrorRate struct {
+	bucketSize time.Duration
+
+	mu      sync.Mutex
+	buckets [errorRateBuckets]errorRateBucket
+}
+
+type errorRateBucket struct {
+	start    time.Time
+	requests int
+	failures int
+}
+
+func NewErrorRate(window time.Duration) *ErrorRate {
+	return &ErrorRate{bucketSize: window / errorRateBuckets}
+}
+
+func (e *ErrorRate) Record(now time.Time, failed bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := now.Truncate(e.bucketSize)
+	bucket := &e.buckets[start.UnixNano()/int64(e.bucketSize)%errorRateBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = errorRateBucket{start: start}
+	}
+	bucket.requests++
+	if failed {
+		bucket.failures++
+	}
+}
+
+// Rate returns the share of failed requests in the window ending at now,
+// and the number of requests it is computed from
+func (e *ErrorRate) Rate(now time.Time) (float64, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	oldest := now.Truncate(e.bucketSize).Add(-e.bucketSize * (errorRateBuckets - 1))
+	requests, failures := 0, 0
+	for _, bucket := range e.buckets {
+		if bucket.start.Before(oldest) {
+			continue
+		}
+		requests += bucket.requests
+		failures += bucket.failures
+	}
+	if requests == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(requests), requests
+}
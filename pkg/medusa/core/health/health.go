@@ -0,0 +1,141 @@
+// Package health runs named dependency probes for the liveness and
+// readiness endpoints
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDegraded Status = "degraded"
+)
+
+// Checker probes a dependency, returning an error when it is unusable
+type Checker func(ctx context.Context) error
+
+type check struct {
+	name     string
+	checker  Checker
+	optional bool
+}
+
+// Result is the outcome of a single probe
+type Result struct {
+	Status    Status `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Optional  bool   `json:"optional,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the outcome of every probe. Status is down when a required
+// dependency is down, and degraded when only optional ones are.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+	// MockExternals flags a process running against in-memory stand-ins
+	// for its external services, so it is never mistaken for a real one
+	MockExternals bool `json:"mock_externals,omitempty"`
+}
+
+// Registry holds the probes of the process. Each run is capped by timeout
+// so a hanging dependency reports down instead of stalling the probe.
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a dependency the process can't serve traffic without
+func (r *Registry) Register(name string, checker Checker) {
+	r.add(check{name: name, checker: checker})
+}
+
+// RegisterOptional adds a dependency whose failure degrades the process
+// without taking it out of rotation, e.g. an external API with a fallback
+func (r *Registry) RegisterOptional(name string, checker Checker) {
+	r.add(check{name: name, checker: checker, optional: true})
+}
+
+func (r *Registry) add(c check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, c)
+	sort.Slice(r.checks, func(i, j int) bool {
+		return r.checks[i].name < r.checks[j].name
+	})
+}
+
+// Run probes every dependency concurrently
+func (r *Registry) Run(ctx context.Context) *Report {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status == StatusUp {
+			continue
+		}
+		if !c.optional {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run waits for the probe or the deadline, whichever comes first
+func run(ctx context.Context, c check) Result {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.checker(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Status:    StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Optional:  c.optional,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
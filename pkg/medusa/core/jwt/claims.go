@@ -5,4 +5,12 @@ import "github.com/golang-jwt/jwt/v5"
 type CustomClaims struct {
 	jwt.RegisteredClaims
 	UserID uint `json:"user_id"`
+
+	// ClaimsVersion is the user's claims version when the token was issued.
+	// Tokens with an older version than the user's current one are rejected.
+	ClaimsVersion int64 `json:"cv"`
+
+	// Tenant is the slug of the tenant the user belongs to, empty when the
+	// API doesn't isolate tenants
+	Tenant string `json:"tnt,omitempty"`
 }
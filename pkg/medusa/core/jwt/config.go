@@ -1,5 +1,9 @@
 package jwt
 
+import "github.com/imlargo/go-api/pkg/medusa/core/clock"
+
 type Config struct {
 	Secret string
+	// Clock dates and validates tokens, the wall clock when nil
+	Clock clock.Clock
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/imlargo/go-api/pkg/medusa/core/clock"
 )
 
 type JWT struct {
@@ -14,17 +15,22 @@ type JWT struct {
 }
 
 func NewJwt(cfg Config) *JWT {
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
 	return &JWT{config: cfg}
 }
 
-func (j *JWT) GenerateToken(userID uint, expiresAt time.Time) (string, error) {
-
+func (j *JWT) GenerateToken(userID uint, claimsVersion int64, tenant string, expiresAt time.Time) (string, error) {
+	now := j.config.Clock.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, CustomClaims{
-		UserID: userID,
+		UserID:        userID,
+		ClaimsVersion: claimsVersion,
+		Tenant:        tenant,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "",
 			Subject:   "",
 			ID:        "",
@@ -33,7 +39,7 @@ func (j *JWT) GenerateToken(userID uint, expiresAt time.Time) (string, error) {
 	})
 
 	// Sign and get the complete encoded token as a string using the key
-	tokenString, err := token.SignedString(j.config.Secret)
+	tokenString, err := token.SignedString([]byte(j.config.Secret))
 	if err != nil {
 		return "", err
 	}
@@ -55,6 +61,7 @@ func (j *JWT) ParseToken(tokenString string) (*CustomClaims, error) {
 		return []byte(j.config.Secret), nil
 	},
 		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(j.config.Clock.Now),
 		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
 	)
 	if err != nil {
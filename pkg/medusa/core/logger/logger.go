@@ -1,6 +1,11 @@
 package logger
 
-import "go.uber.org/zap"
+import (
+	"context"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/querytag"
+	"go.uber.org/zap"
+)
 
 type Logger struct {
 	*zap.Logger
@@ -10,3 +15,13 @@ func NewLogger() *Logger {
 	logger, _ := zap.NewProduction()
 	return &Logger{Logger: logger}
 }
+
+// WithContext adds the request id of ctx to every entry, so service logs can
+// be matched with the access log line of the request that caused them
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	requestID := querytag.RequestID(ctx)
+	if requestID == "" {
+		return l
+	}
+	return &Logger{Logger: l.With(zap.String("request_id", requestID))}
+}
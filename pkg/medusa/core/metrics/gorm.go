@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "metrics:query_start"
+
+var dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "db_query_duration_seconds",
+	Help:    "Database statement duration by operation and table",
+	Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+}, []string{"operation", "table", "status"})
+
+type gormPlugin struct{}
+
+// NewGormPlugin returns a GORM plugin that times every statement into
+// db_query_duration_seconds. Raw statements have no table.
+func NewGormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	registrations := []error{
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", p.start),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", p.observe("create")),
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", p.start),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", p.observe("query")),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", p.start),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", p.observe("update")),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", p.start),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", p.observe("delete")),
+		callbacks.Row().Before("gorm:row").Register("metrics:before_row", p.start),
+		callbacks.Row().After("gorm:row").Register("metrics:after_row", p.observe("row")),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", p.start),
+		callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", p.observe("raw")),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gormPlugin) start(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (p *gormPlugin) observe(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		status := "ok"
+		if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+			status = "error"
+		}
+		dbQueryDuration.WithLabelValues(operation, db.Statement.Table, status).Observe(time.Since(start).Seconds())
+	}
+}
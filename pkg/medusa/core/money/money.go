@@ -0,0 +1,294 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("money amount overflow")
+	ErrInvalidAmount    = errors.New("invalid money amount")
+)
+
+// RoundingMode decides how fractional minor units are resolved
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds halves away from zero
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds halves to the nearest even unit (banker's rounding)
+	RoundHalfEven
+	// RoundDown truncates toward zero
+	RoundDown
+)
+
+// Money is an amount in minor units of a currency. It is meant to be
+// embedded in GORM models, where it maps to the amount and currency
+// columns.
+type Money struct {
+	Amount   int64    `json:"amount" gorm:"not null"`
+	Currency Currency `json:"currency" gorm:"not null;size:3"`
+}
+
+// New creates an amount in minor units
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero returns a zero amount of currency
+func Zero(currency Currency) Money {
+	return Money{Currency: currency}
+}
+
+// FromMajor parses a decimal string in major units, e.g. "12.34". Amounts
+// with more decimals than the currency allows are rejected.
+func FromMajor(value string, currency Currency) (Money, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	if negative || strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	exponent := currency.Exponent()
+	if !isDigits(whole) || (fraction != "" && !isDigits(fraction)) || len(fraction) > exponent {
+		return Money{}, ErrInvalidAmount
+	}
+
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+
+	if negative {
+		amount = -amount
+	}
+	return New(amount, currency), nil
+}
+
+// FromFloat converts a legacy float price in major units, rounding to the
+// nearest minor unit with mode. Only use it at boundaries that still carry
+// floats.
+func FromFloat(value float64, currency Currency, mode RoundingMode) (Money, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	// The shortest decimal representation avoids binary artifacts such as
+	// 0.285 being stored as 0.28499999
+	rat, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(currency.Exponent())), nil)
+	rat.Mul(rat, new(big.Rat).SetInt(scale))
+
+	amount, err := roundRat(rat, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, currency), nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return New(sum, m.Currency), nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	negated, err := other.Neg()
+	if err != nil {
+		return Money{}, err
+	}
+	return m.Add(negated)
+}
+
+// Neg returns -m. The smallest amount has no positive counterpart.
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return New(-m.Amount, m.Currency), nil
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or 1
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Mul returns m * factor
+func (m Money) Mul(factor int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(factor))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return New(product.Int64(), m.Currency), nil
+}
+
+// MulBps applies a rate in basis points (1/100 of a percent), rounding the
+// result to a whole minor unit with mode
+func (m Money) MulBps(bps int64, mode RoundingMode) (Money, error) {
+	rat := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(bps)),
+		big.NewInt(10000),
+	)
+
+	amount, err := roundRat(rat, mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Allocate splits m by weights without losing minor units. The remainder is
+// handed out one unit at a time to the first shares, so the parts always
+// add up to m.
+func (m Money) Allocate(weights ...int64) ([]Money, error) {
+	var total int64
+	for _, weight := range weights {
+		if weight < 0 {
+			return nil, ErrInvalidAmount
+		}
+		total += weight
+	}
+	if total == 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	parts := make([]Money, len(weights))
+	remainder := m.Amount
+	for i, weight := range weights {
+		share := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(weight))
+		share.Quo(share, big.NewInt(total))
+		parts[i] = New(share.Int64(), m.Currency)
+		remainder -= share.Int64()
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if weights[i] == 0 {
+			continue
+		}
+		parts[i].Amount += step
+		remainder -= step
+	}
+
+	return parts, nil
+}
+
+// Major formats the amount in major units, e.g. "12.34"
+func (m Money) Major() string {
+	exponent := m.Currency.Exponent()
+	amount := m.Amount
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(absUint(amount), 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	split := len(digits) - exponent
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+func (m Money) String() string {
+	return m.Major() + " " + string(m.Currency)
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+// roundRat rounds a rational number of minor units to an int64
+func roundRat(rat *big.Rat, mode RoundingMode) (int64, error) {
+	quotient, remainder := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+
+	if remainder.Sign() != 0 && mode != RoundDown {
+		// Compare twice the remainder with the denominator to find which
+		// side of the half the fraction is on
+		twice := new(big.Int).Abs(remainder)
+		twice.Lsh(twice, 1)
+		half := twice.Cmp(rat.Denom())
+
+		awayFromZero := half > 0 ||
+			(half == 0 && mode == RoundHalfUp) ||
+			(half == 0 && mode == RoundHalfEven && quotient.Bit(0) == 1)
+
+		if awayFromZero {
+			quotient.Add(quotient, big.NewInt(int64(rat.Sign())))
+		}
+	}
+
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
+
+func absUint(value int64) uint64 {
+	if value < 0 {
+		return uint64(-(value + 1)) + 1
+	}
+	return uint64(value)
+}
+
+// isDigits reports whether value is a non-empty run of ASCII digits
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMulBpsRounding(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		bps    int64
+		mode   RoundingMode
+		want   int64
+	}{
+		{"exact", 10000, 1000, RoundHalfUp, 1000},
+		{"half up below half", 12, 1000, RoundHalfUp, 1},
+		{"half up above half", 17, 1000, RoundHalfUp, 2},
+		{"half up on 0.5", 5, 1000, RoundHalfUp, 1},
+		{"half up on 1.5", 15, 1000, RoundHalfUp, 2},
+		{"half up on 2.5", 25, 1000, RoundHalfUp, 3},
+		{"half even on 0.5", 5, 1000, RoundHalfEven, 0},
+		{"half even on 1.5", 15, 1000, RoundHalfEven, 2},
+		{"half even on 2.5", 25, 1000, RoundHalfEven, 2},
+		{"half even above half", 26, 1000, RoundHalfEven, 3},
+		{"down truncates", 19, 1000, RoundDown, 1},
+		{"negative half up on -2.5", -25, 1000, RoundHalfUp, -3},
+		{"negative half even on -2.5", -25, 1000, RoundHalfEven, -2},
+		{"negative half even on -3.5", -35, 1000, RoundHalfEven, -4},
+		{"negative below half", -12, 1000, RoundHalfUp, -1},
+		{"negative down truncates toward zero", -19, 1000, RoundDown, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.amount, "USD").MulBps(tt.bps, tt.mode)
+			if err != nil {
+				t.Fatalf("MulBps() error = %v", err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("MulBps(%d, %d) = %d, want %d", tt.amount, tt.bps, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFloatRounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		currency Currency
+		mode     RoundingMode
+		want     int64
+	}{
+		{"binary artifact", 0.285, "USD", RoundHalfUp, 29},
+		{"binary artifact half even", 0.285, "USD", RoundHalfEven, 28},
+		{"half even rounds to even", 0.295, "USD", RoundHalfEven, 30},
+		{"zero decimal currency", 1234.5, "JPY", RoundHalfUp, 1235},
+		{"zero decimal currency half even", 1234.5, "JPY", RoundHalfEven, 1234},
+		{"three decimal currency", 1.0005, "KWD", RoundHalfUp, 1001},
+		{"negative half up", -0.125, "USD", RoundHalfUp, -13},
+		{"negative half even", -0.125, "USD", RoundHalfEven, -12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromFloat(tt.value, tt.currency, tt.mode)
+			if err != nil {
+				t.Fatalf("FromFloat() error = %v", err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("FromFloat(%v) = %d, want %d", tt.value, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{"even split", 300, []int64{1, 1, 1}, []int64{100, 100, 100}},
+		{"remainder to the first shares", 100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{"weighted", 1000, []int64{70, 30}, []int64{700, 300}},
+		{"weighted with remainder", 1001, []int64{70, 30}, []int64{701, 300}},
+		{"zero weight gets nothing", 101, []int64{1, 0, 1}, []int64{51, 0, 50}},
+		{"single unit", 1, []int64{1, 1, 1}, []int64{1, 0, 0}},
+		{"negative", -100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{"negative weighted", -1001, []int64{70, 30}, []int64{-701, -300}},
+		{"zero", 0, []int64{1, 2}, []int64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := New(tt.amount, "USD").Allocate(tt.weights...)
+			if err != nil {
+				t.Fatalf("Allocate() error = %v", err)
+			}
+
+			var sum int64
+			for i, part := range parts {
+				sum += part.Amount
+				if part.Amount != tt.want[i] {
+					t.Errorf("part %d = %d, want %d", i, part.Amount, tt.want[i])
+				}
+				if part.Currency != "USD" {
+					t.Errorf("part %d currency = %s, want USD", i, part.Currency)
+				}
+			}
+			if sum != tt.amount {
+				t.Errorf("parts add up to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestAllocateRejectsInvalidWeights(t *testing.T) {
+	for _, weights := range [][]int64{{}, {0, 0}, {1, -1}} {
+		if _, err := New(100, "USD").Allocate(weights...); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Allocate(%v) error = %v, want ErrInvalidAmount", weights, err)
+		}
+	}
+}
+
+func TestFromMajorAndMajor(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency Currency
+		amount   int64
+		major    string
+	}{
+		{"12.34", "USD", 1234, "12.34"},
+		{"12.3", "USD", 1230, "12.30"},
+		{"0.05", "USD", 5, "0.05"},
+		{"-0.05", "USD", -5, "-0.05"},
+		{"+7", "USD", 700, "7.00"},
+		{"1500", "JPY", 1500, "1500"},
+		{"1.234", "KWD", 1234, "1.234"},
+	}
+
+	for _, tt := range tests {
+		got, err := FromMajor(tt.value, tt.currency)
+		if err != nil {
+			t.Fatalf("FromMajor(%q) error = %v", tt.value, err)
+		}
+		if got.Amount != tt.amount {
+			t.Errorf("FromMajor(%q) = %d, want %d", tt.value, got.Amount, tt.amount)
+		}
+		if major := got.Major(); major != tt.major {
+			t.Errorf("Major() = %q, want %q", major, tt.major)
+		}
+	}
+
+	for _, value := range []string{"1.234", "", ".5", "abc", "+-5", "--5", "-+5", "1.-5", "- 5"} {
+		if _, err := FromMajor(value, "USD"); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("FromMajor(%q) error = %v, want ErrInvalidAmount", value, err)
+		}
+	}
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	usd := New(100, "USD")
+
+	if _, err := usd.Add(New(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() across currencies error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := New(1<<62, "USD").Mul(4); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Mul() error = %v, want ErrOverflow", err)
+	}
+	if _, err := New(1<<62, "USD").Add(New(1<<62, "USD")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Add() error = %v, want ErrOverflow", err)
+	}
+	if _, err := New(math.MinInt64, "USD").Neg(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Neg() of the smallest amount error = %v, want ErrOverflow", err)
+	}
+	if _, err := usd.Sub(New(math.MinInt64, "USD")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Sub() error = %v, want ErrOverflow", err)
+	}
+}
@@ -0,0 +1,19 @@
+package money
+
+import "strings"
+
+// ToStripe returns the amount and currency as the Stripe API expects them.
+// Stripe amounts are already in the smallest currency unit, and currency
+// codes are lower case.
+func (m Money) ToStripe() (int64, string) {
+	return m.Amount, strings.ToLower(string(m.Currency))
+}
+
+// FromStripe converts an amount and currency received from Stripe
+func FromStripe(amount int64, currency string) (Money, error) {
+	parsed, err := ParseCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, parsed), nil
+}
@@ -0,0 +1,242 @@
+// Package query turns list query params into a GORM scope. Only the fields
+// a Schema declares can be filtered or sorted on, and values are always
+// bound as parameters, so params never reach the SQL text.
+//
+//	?status=failed&created_at[gte]=2025-01-01&sort=-created_at
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"gorm.io/gorm"
+)
+
+const SortParam = "sort"
+
+var ErrInvalidQuery = apperrors.Validation("invalid query").WithCode("INVALID_QUERY")
+
+type FieldType int
+
+const (
+	String FieldType = iota
+	Int
+	Bool
+	Time
+)
+
+type Operator string
+
+const (
+	Eq  Operator = "eq"
+	Ne  Operator = "ne"
+	Gt  Operator = "gt"
+	Gte Operator = "gte"
+	Lt  Operator = "lt"
+	Lte Operator = "lte"
+	In  Operator = "in"
+)
+
+var operatorSQL = map[Operator]string{
+	Eq:  "=",
+	Ne:  "<>",
+	Gt:  ">",
+	Gte: ">=",
+	Lt:  "<",
+	Lte: "<=",
+	In:  "IN",
+}
+
+// Field is a column exposed to the query params. Operators defaults to Eq.
+type Field struct {
+	Column    string
+	Type      FieldType
+	Operators []Operator
+	Sortable  bool
+}
+
+// Schema maps param names to the columns they filter. DefaultSort uses the
+// sort param syntax and applies when the request has none.
+type Schema struct {
+	Fields      map[string]Field
+	DefaultSort string
+}
+
+type condition struct {
+	column   string
+	operator Operator
+	value    any
+}
+
+type order struct {
+	column string
+	desc   bool
+}
+
+// Query is the validated filters and sort of a request
+type Query struct {
+	conditions []condition
+	orders     []order
+}
+
+// Parse reads the filters and sort of values. Params that are not in the
+// schema are left alone, they may belong to the handler, e.g. limit.
+func (s Schema) Parse(values url.Values) (*Query, error) {
+	q := &Query{}
+
+	for param, raw := range values {
+		if param == SortParam {
+			continue
+		}
+
+		name, operator, err := splitParam(param)
+		if err != nil {
+			return nil, err
+		}
+
+		field, exists := s.Fields[name]
+		if !exists {
+			if operator != "" {
+				return nil, fmt.Errorf("%w: %s can't be filtered", ErrInvalidQuery, name)
+			}
+			continue
+		}
+		if operator == "" {
+			operator = Eq
+		}
+		if !field.allows(operator) {
+			return nil, fmt.Errorf("%w: %s doesn't support %s", ErrInvalidQuery, name, operator)
+		}
+
+		for _, value := range raw {
+			parsed, err := field.parseValue(operator, value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, param, err)
+			}
+			q.conditions = append(q.conditions, condition{column: field.Column, operator: operator, value: parsed})
+		}
+	}
+
+	sort := values.Get(SortParam)
+	if sort == "" {
+		sort = s.DefaultSort
+	}
+	orders, err := s.parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	q.orders = orders
+
+	return q, nil
+}
+
+// Scope applies the query, e.g. db.Scopes(q.Scope)
+func (q *Query) Scope(db *gorm.DB) *gorm.DB {
+	if q == nil {
+		return db
+	}
+
+	for _, c := range q.conditions {
+		if c.operator == In {
+			db = db.Where(fmt.Sprintf("%s IN ?", c.column), c.value)
+			continue
+		}
+		db = db.Where(fmt.Sprintf("%s %s ?", c.column, operatorSQL[c.operator]), c.value)
+	}
+	for _, o := range q.orders {
+		direction := "ASC"
+		if o.desc {
+			direction = "DESC"
+		}
+		db = db.Order(o.column + " " + direction)
+	}
+	return db
+}
+
+func (s Schema) parseSort(sort string) ([]order, error) {
+	if sort == "" {
+		return nil, nil
+	}
+
+	var orders []order
+	for _, part := range strings.Split(sort, ",") {
+		name, desc := strings.CutPrefix(strings.TrimSpace(part), "-")
+		field, exists := s.Fields[name]
+		if !exists || !field.Sortable {
+			return nil, fmt.Errorf("%w: can't sort by %s", ErrInvalidQuery, name)
+		}
+		orders = append(orders, order{column: field.Column, desc: desc})
+	}
+	return orders, nil
+}
+
+// splitParam splits "created_at[gte]" into its name and operator
+func splitParam(param string) (string, Operator, error) {
+	name, rest, found := strings.Cut(param, "[")
+	if !found {
+		return param, "", nil
+	}
+
+	operator, ok := strings.CutSuffix(rest, "]")
+	if !ok {
+		return "", "", fmt.Errorf("%w: malformed param %s", ErrInvalidQuery, param)
+	}
+	if _, known := operatorSQL[Operator(operator)]; !known {
+		return "", "", fmt.Errorf("%w: unknown operator %s", ErrInvalidQuery, operator)
+	}
+	return name, Operator(operator), nil
+}
+
+func (f Field) allows(operator Operator) bool {
+	if len(f.Operators) == 0 {
+		return operator == Eq
+	}
+	for _, allowed := range f.Operators {
+		if allowed == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Field) parseValue(operator Operator, value string) (any, error) {
+	if operator != In {
+		return f.parseScalar(value)
+	}
+
+	parts := strings.Split(value, ",")
+	values := make([]any, 0, len(parts))
+	for _, part := range parts {
+		parsed, err := f.parseScalar(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, parsed)
+	}
+	return values, nil
+}
+
+func (f Field) parseScalar(value string) (any, error) {
+	switch f.Type {
+	case Int:
+		return strconv.ParseInt(value, 10, 64)
+	case Bool:
+		return strconv.ParseBool(value)
+	case Time:
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, errors.New("expected an RFC 3339 time or YYYY-MM-DD date")
+		}
+		return parsed, nil
+	default:
+		return value, nil
+	}
+}
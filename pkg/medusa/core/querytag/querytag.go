@@ -0,0 +1,166 @@
+// Package querytag prefixes every SQL statement with a comment naming the
+// service, the repository method and the request that issued it, e.g.
+//
+//	/* svc=api op=commissionRepository.GetApplicable req=3f2a... */ SELECT ...
+//
+// Postgres ignores comments when fingerprinting statements, so tagged
+// queries still aggregate in pg_stat_statements, while slow query logs and
+// pg_stat_activity show where each one came from.
+package querytag
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const tagClause = "QUERY_TAG"
+
+type ctxKey string
+
+const (
+	requestIDKey ctxKey = "querytag:request_id"
+	operationKey ctxKey = "querytag:operation"
+)
+
+// WithRequestID tags the queries run with ctx with a request id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request id stored in ctx
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithOperation overrides the operation name, which is otherwise the
+// calling repository method
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey, operation)
+}
+
+type plugin struct {
+	service string
+}
+
+// New returns a GORM plugin that tags the queries of service
+func New(service string) gorm.Plugin {
+	return &plugin{service: sanitize(service)}
+}
+
+func (p *plugin) Name() string {
+	return "querytag"
+}
+
+func (p *plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	registrations := []error{
+		callbacks.Create().Before("gorm:create").Register("querytag:create", p.tag),
+		callbacks.Query().Before("gorm:query").Register("querytag:query", p.tag),
+		callbacks.Update().Before("gorm:update").Register("querytag:update", p.tag),
+		callbacks.Delete().Before("gorm:delete").Register("querytag:delete", p.tag),
+		callbacks.Row().Before("gorm:row").Register("querytag:row", p.tag),
+		callbacks.Raw().Before("gorm:raw").Register("querytag:raw", p.tag),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) tag(db *gorm.DB) {
+	stmt := db.Statement
+	comment := p.comment(stmt.Context)
+
+	// Raw statements are already built, the rest are built from clauses
+	// after this callback
+	if stmt.SQL.Len() > 0 {
+		sql := stmt.SQL.String()
+		if strings.HasPrefix(sql, "/* svc=") {
+			return
+		}
+		stmt.SQL.Reset()
+		stmt.SQL.WriteString(comment)
+		stmt.SQL.WriteByte(' ')
+		stmt.SQL.WriteString(sql)
+		return
+	}
+
+	stmt.Clauses[tagClause] = clause.Clause{
+		Name: tagClause,
+		Builder: func(_ clause.Clause, builder clause.Builder) {
+			builder.WriteString(comment)
+		},
+	}
+	if len(stmt.BuildClauses) == 0 || stmt.BuildClauses[0] != tagClause {
+		stmt.BuildClauses = append([]string{tagClause}, stmt.BuildClauses...)
+	}
+}
+
+func (p *plugin) comment(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey).(string)
+	if operation == "" {
+		operation = caller()
+	}
+
+	var b strings.Builder
+	b.WriteString("/* svc=")
+	b.WriteString(p.service)
+	b.WriteString(" op=")
+	b.WriteString(sanitize(operation))
+	if requestID := RequestID(ctx); requestID != "" {
+		b.WriteString(" req=")
+		b.WriteString(sanitize(requestID))
+	}
+	b.WriteString(" */")
+	return b.String()
+}
+
+// caller returns the first function on the stack outside GORM and the
+// base repository, usually a repository method
+func caller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		if !strings.Contains(name, "gorm.io/") &&
+			!strings.Contains(name, "/medusa/core/repository.") &&
+			!strings.Contains(name, "/medusa/core/querytag.") {
+			// github.com/org/repo/internal/repository.(*commissionRepository).GetApplicable
+			// becomes commissionRepository.GetApplicable
+			name = name[strings.LastIndex(name, "/")+1:]
+			if _, method, ok := strings.Cut(name, "."); ok {
+				name = method
+			}
+			return name
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// sanitize keeps tag values from closing the comment or breaking the
+// key=value format
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '_' || r == '-' || r == '.' || r == ':':
+			return r
+		default:
+			return -1
+		}
+	}, value)
+}
@@ -0,0 +1,84 @@
+package ratelimiter
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds a check so a slow Redis delays requests by at most
+// this much
+const redisTimeout = 200 * time.Millisecond
+
+// slidingWindowScript counts the requests of the last window in a sorted
+// set scored by time, adding the current one when it fits. It returns
+// whether the request is allowed and, when it isn't, the milliseconds
+// until the oldest request leaves the window.
+//
+// The time is read from Redis, replicas whose clocks drift apart would
+// otherwise evict each other's requests or never see them expire. Scripts
+// replicate their effects since Redis 5, so calling TIME before writing is
+// allowed.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", key, 0, now - window)
+local count = redis.call("ZCARD", key)
+if count < limit then
+	redis.call("ZADD", key, now, ARGV[3])
+	redis.call("PEXPIRE", key, window)
+	return {1, limit - count - 1}
+end
+
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+type slidingWindowLimiter struct {
+	client *redis.Client
+	prefix string
+	config Config
+}
+
+// NewSlidingWindowLimiter allows cfg.RequestsPerTimeFrame requests per key
+// in any cfg.TimeFrame, counted in Redis so every replica shares the
+// limit. name separates the counters of limiters sharing a Redis.
+//
+// Allow returns the requests left in the window when allowed, and the
+// seconds until the next request fits when not. Requests are let through
+// when Redis can't be reached, an outage must not take the API down.
+func NewSlidingWindowLimiter(client *redis.Client, name string, cfg Config) RateLimiter {
+	return &slidingWindowLimiter{
+		client: client,
+		prefix: "ratelimit:" + name + ":",
+		config: cfg,
+	}
+}
+
+func (l *slidingWindowLimiter) Allow(key string) (bool, float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	// Requests in the same millisecond need distinct members
+	member := strconv.FormatUint(rand.Uint64(), 36)
+
+	result, err := slidingWindowScript.Run(ctx, l.client, []string{l.prefix + key},
+		l.config.TimeFrame.Milliseconds(), l.config.RequestsPerTimeFrame, member,
+	).Int64Slice()
+	if err != nil || len(result) != 2 {
+		return true, 0
+	}
+
+	if result[0] == 1 {
+		return true, float64(result[1])
+	}
+	return false, time.Duration(result[1] * int64(time.Millisecond)).Seconds()
+}
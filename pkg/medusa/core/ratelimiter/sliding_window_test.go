@@ -0,0 +1,50 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Two instances share a window through Redis. Their clocks disagree with
+// each other and with Redis, so only the Redis clock may move the window.
+func TestSlidingWindowUsesRedisClock(t *testing.T) {
+	server := miniredis.RunT(t)
+	start := time.Now().Add(-time.Hour)
+	server.SetTime(start)
+
+	cfg := Config{TimeFrame: time.Minute, RequestsPerTimeFrame: 2}
+	first := NewSlidingWindowLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}), "skew", cfg)
+	second := NewSlidingWindowLimiter(redis.NewClient(&redis.Options{Addr: server.Addr()}), "skew", cfg)
+
+	if allowed, _ := first.Allow("user:1"); !allowed {
+		t.Fatal("first request was denied")
+	}
+	server.SetTime(start.Add(10 * time.Second))
+	if allowed, _ := second.Allow("user:1"); !allowed {
+		t.Fatal("second request was denied")
+	}
+
+	// The window of the first request ends 50s later on the Redis clock,
+	// whatever the instances' clocks say
+	server.SetTime(start.Add(20 * time.Second))
+	for _, limiter := range []RateLimiter{first, second} {
+		allowed, retryAfter := limiter.Allow("user:1")
+		if allowed {
+			t.Fatal("third request was allowed over the limit")
+		}
+		if retryAfter < 39 || retryAfter > 40 {
+			t.Errorf("retry after = %.1fs, want 40s", retryAfter)
+		}
+	}
+
+	server.SetTime(start.Add(61 * time.Second))
+	if allowed, _ := second.Allow("user:1"); !allowed {
+		t.Error("request was denied after the first one left the window")
+	}
+	if allowed, _ := first.Allow("user:1"); allowed {
+		t.Error("request was allowed while the window was full again")
+	}
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// ForEachBatch streams the rows matched by query in primary key order,
+// batchSize rows at a time, so jobs over large tables keep a single batch
+// in memory. It stops when fn returns an error or ctx is cancelled.
+//
+// The batch slice is reused, so it is only valid until fn returns. Copy the
+// rows fn needs to keep.
+func ForEachBatch[T any](ctx context.Context, query *gorm.DB, batchSize int, fn func(batch []T) error) error {
+	var batch []T
+
+	return query.WithContext(ctx).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(batch)
+	}).Error
+}
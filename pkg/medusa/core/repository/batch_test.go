@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+const benchBatchRows = 20000
+
+type benchBatchRow struct {
+	ID      uint
+	Payload string
+}
+
+func (benchBatchRow) TableName() string {
+	return "bench_batch_rows"
+}
+
+// seedBenchBatchRows fills a scratch table with rows of about 200 bytes
+func seedBenchBatchRows(b *testing.B) {
+	b.Helper()
+
+	db := openTestDB(b)
+	if err := db.Migrator().DropTable(&benchBatchRow{}); err != nil {
+		b.Fatalf("failed to drop table: %v", err)
+	}
+	if err := db.Migrator().CreateTable(&benchBatchRow{}); err != nil {
+		b.Fatalf("failed to create table: %v", err)
+	}
+	b.Cleanup(func() { db.Migrator().DropTable(&benchBatchRow{}) })
+
+	err := db.Exec("INSERT INTO bench_batch_rows (payload) SELECT repeat('x', 200) FROM generate_series(1, ?)", benchBatchRows).Error
+	if err != nil {
+		b.Fatalf("failed to seed rows: %v", err)
+	}
+}
+
+// BenchmarkFindAll is the baseline ForEachBatch replaces, loading every row
+// at once
+func BenchmarkFindAll(b *testing.B) {
+	seedBenchBatchRows(b)
+	db := openTestDB(b)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var rows []benchBatchRow
+		if err := db.Find(&rows).Error; err != nil {
+			b.Fatal(err)
+		}
+		if len(rows) != benchBatchRows {
+			b.Fatalf("loaded %d rows, want %d", len(rows), benchBatchRows)
+		}
+	}
+}
+
+func BenchmarkForEachBatch(b *testing.B) {
+	seedBenchBatchRows(b)
+	db := openTestDB(b)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		seen := 0
+		err := ForEachBatch(context.Background(), db.Model(&benchBatchRow{}), 500, func(batch []benchBatchRow) error {
+			seen += len(batch)
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if seen != benchBatchRows {
+			b.Fatalf("visited %d rows, want %d", seen, benchBatchRows)
+		}
+	}
+}
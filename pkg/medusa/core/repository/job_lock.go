@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// jobLockCheckInterval is how often a running job checks that the connection
+// holding its lock is still alive
+var jobLockCheckInterval = 10 * time.Second
+
+// JobLocker runs a job on at most one instance at a time
+type JobLocker interface {
+	RunExclusive(ctx context.Context, job string, fn func(ctx context.Context) error) (bool, error)
+}
+
+type advisoryJobLocker struct {
+	db *gorm.DB
+}
+
+// NewAdvisoryJobLocker uses Postgres session advisory locks. The lock lives
+// on a dedicated connection, so if the holder crashes the connection drops
+// and Postgres releases the lock on its own.
+func NewAdvisoryJobLocker(db *gorm.DB) JobLocker {
+	return &advisoryJobLocker{db: db}
+}
+
+// RunExclusive runs fn while holding the job's lock. It returns false
+// without running fn when another instance holds the lock.
+func (l *advisoryJobLocker) RunExclusive(ctx context.Context, job string, fn func(ctx context.Context) error) (bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection for job lock: %w", err)
+	}
+	defer conn.Close()
+
+	key := advisoryLockKey(job)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to acquire job lock %s: %w", job, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	// Unlock on a fresh context so a cancelled job still frees the lock
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go watchLockConn(jobCtx, cancel, conn)
+
+	return true, fn(jobCtx)
+}
+
+// watchLockConn cancels the job when the connection holding its lock dies,
+// since Postgres has released the lock and another instance may take it
+func watchLockConn(ctx context.Context, cancel context.CancelFunc, conn *sql.Conn) {
+	ticker := time.NewTicker(jobLockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func advisoryLockKey(job string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(job))
+	return int64(h.Sum64())
+}
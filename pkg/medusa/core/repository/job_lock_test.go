@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to TEST_DATABASE_URL. Each call opens its own pool, so
+// two lockers behave like two instances of the API.
+func openTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestRunExclusiveRunsOnce(t *testing.T) {
+	job := "test_job_lock_" + t.Name()
+	lockers := []JobLocker{
+		NewAdvisoryJobLocker(openTestDB(t)),
+		NewAdvisoryJobLocker(openTestDB(t)),
+	}
+
+	var (
+		runs  atomic.Int32
+		start = make(chan struct{})
+		done  = make(chan struct{})
+		wg    sync.WaitGroup
+	)
+	ran := make([]bool, len(lockers))
+	errs := make([]error, len(lockers))
+
+	for i, locker := range lockers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			ran[i], errs[i] = locker.RunExclusive(context.Background(), job, func(ctx context.Context) error {
+				runs.Add(1)
+				// Hold the lock until both schedulers tried to take it
+				<-done
+				return nil
+			})
+		}()
+	}
+
+	close(start)
+	time.Sleep(500 * time.Millisecond)
+	close(done)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("locker %d: %v", i, err)
+		}
+	}
+	if got := runs.Load(); got != 1 {
+		t.Fatalf("job ran %d times, want 1", got)
+	}
+	if ran[0] == ran[1] {
+		t.Fatalf("RunExclusive results = %v, want exactly one true", ran)
+	}
+
+	// The lock is free again once the job returned
+	ok, err := lockers[0].RunExclusive(context.Background(), job, func(ctx context.Context) error { return nil })
+	if err != nil || !ok {
+		t.Fatalf("RunExclusive after release = %v, %v, want true", ok, err)
+	}
+}
+
+func TestRunExclusiveReleasesOnLostConnection(t *testing.T) {
+	previous := jobLockCheckInterval
+	jobLockCheckInterval = 100 * time.Millisecond
+	t.Cleanup(func() { jobLockCheckInterval = previous })
+
+	job := "test_job_lock_" + t.Name()
+	admin := openTestDB(t)
+	first := NewAdvisoryJobLocker(openTestDB(t))
+	second := NewAdvisoryJobLocker(openTestDB(t))
+
+	running := make(chan struct{})
+	cancelled := make(chan struct{})
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := first.RunExclusive(context.Background(), job, func(ctx context.Context) error {
+			close(running)
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		})
+		result <- err
+	}()
+	<-running
+
+	// Kill the session holding the advisory lock, as if the instance lost
+	// its connection to Postgres
+	key := advisoryLockKey(job)
+	err := admin.Exec(
+		"SELECT pg_terminate_backend(pid) FROM pg_locks WHERE locktype = 'advisory' AND classid::bigint = ? AND objid::bigint = ?",
+		uint32(uint64(key)>>32), uint32(uint64(key)),
+	).Error
+	if err != nil {
+		t.Fatalf("failed to terminate lock holder: %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not cancelled after its lock connection was lost")
+	}
+	<-result
+
+	ok, err := second.RunExclusive(context.Background(), job, func(ctx context.Context) error { return nil })
+	if err != nil || !ok {
+		t.Fatalf("RunExclusive after lost connection = %v, %v, want true", ok, err)
+	}
+}
@@ -1,5 +1,7 @@
 package repository
 
+import "gorm.io/gorm"
+
 type RepositoryOption func(*Repository)
 
 func WithEntity(entity any) RepositoryOption {
@@ -7,3 +9,11 @@ func WithEntity(entity any) RepositoryOption {
 		r.entity = entity
 	}
 }
+
+// WithReplica sends the reads of ReadDB to the connections of replica, a
+// read-only copy of the database. Writes and DB always use the primary.
+func WithReplica(replica *gorm.DB) RepositoryOption {
+	return func(r *Repository) {
+		r.replica = replica.ConnPool
+	}
+}
@@ -11,10 +11,15 @@ type ctxKey string
 
 const txKey ctxKey = "tx"
 
+const readPrimaryKey ctxKey = "read_primary"
+
 type Repository struct {
 	entity any
 	logger *logger.Logger
 	db     *gorm.DB
+
+	// replica serves ReadDB when set, see WithReplica
+	replica gorm.ConnPool
 }
 
 func NewRepository(db *gorm.DB, logger *logger.Logger, opts ...RepositoryOption) *Repository {
@@ -37,3 +42,29 @@ func (r *Repository) DB(ctx context.Context) *gorm.DB {
 	}
 	return r.db.WithContext(ctx)
 }
+
+// ReadDB is DB for reads that tolerate replication lag, like listings and
+// analytics. It uses the replica unless there is none, ctx is in a
+// transaction or ctx asks for the primary with ReadPrimary.
+func (r *Repository) ReadDB(ctx context.Context) *gorm.DB {
+	if r.replica == nil || ctx.Value(txKey) != nil || readsPrimary(ctx) {
+		return r.DB(ctx)
+	}
+
+	// Only the connections change, the callbacks of the primary, e.g. the
+	// tenancy plugin, still apply
+	db := r.db.WithContext(ctx)
+	db.Statement.ConnPool = r.replica
+	return db
+}
+
+// ReadPrimary returns a context whose reads all go to the primary, for a
+// request that must see its own or a previous request's writes
+func ReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPrimaryKey, true)
+}
+
+func readsPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(readPrimaryKey).(bool)
+	return primary
+}
@@ -8,11 +8,13 @@ import (
 type Store struct {
 	BaseRepo    *Repository
 	Transaction TransactionManager
+	JobLocker   JobLocker
 }
 
-func NewStore(db *gorm.DB, logger *logger.Logger) *Store {
+func NewStore(db *gorm.DB, logger *logger.Logger, opts ...RepositoryOption) *Store {
 	return &Store{
-		BaseRepo:    NewRepository(db, logger),
+		BaseRepo:    NewRepository(db, logger, opts...),
 		Transaction: NewTransactionManager(db),
+		JobLocker:   NewAdvisoryJobLocker(db),
 	}
 }
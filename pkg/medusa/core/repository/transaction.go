@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"sync"
 
 	"gorm.io/gorm"
 )
@@ -31,8 +32,49 @@ func (tm *transactionManager) WithTransactionOpts(ctx context.Context, opts *sql
 		return fn(ctx)
 	}
 
-	return tm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	hooks := &afterCommitHooks{}
+	ctx = context.WithValue(ctx, afterCommitKey, hooks)
+
+	err := tm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		txCtx := context.WithValue(ctx, txKey, tx)
 		return fn(txCtx)
 	}, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+const afterCommitKey ctxKey = "after_commit"
+
+type afterCommitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction in ctx commits, and drops it on
+// rollback. Outside a transaction fn runs right away. Use it for side
+// effects other processes observe, like notifications, so they never
+// announce a write that was rolled back.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey).(*afterCommitHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
+
+// ContextWithTx returns a context whose repository calls and transactions
+// run in tx, e.g. a test transaction rolled back at cleanup. The caller
+// commits or rolls back tx, after commit hooks run right away.
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey, tx)
 }
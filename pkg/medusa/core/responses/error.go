@@ -9,12 +9,16 @@ import (
 type ErrorCode string
 
 const (
-	ErrBindJson       ErrorCode = "BIND_JSON"
-	ErrNotFound       ErrorCode = "NOT_FOUND"
-	ErrInternalServer ErrorCode = "INTERNAL_SERVER_ERROR"
-	ErrBadRequest     ErrorCode = "BAD_REQUEST"
-	ErrToManyRequests ErrorCode = "TOO_MANY_REQUESTS"
-	ErrUnauthorized   ErrorCode = "UNAUTHORIZED"
+	ErrBindJson           ErrorCode = "BIND_JSON"
+	ErrNotFound           ErrorCode = "NOT_FOUND"
+	ErrInternalServer     ErrorCode = "INTERNAL_SERVER_ERROR"
+	ErrBadRequest         ErrorCode = "BAD_REQUEST"
+	ErrValidation         ErrorCode = "VALIDATION_FAILED"
+	ErrToManyRequests     ErrorCode = "TOO_MANY_REQUESTS"
+	ErrUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrForbidden          ErrorCode = "FORBIDDEN"
+	ErrConflict           ErrorCode = "CONFLICT"
+	ErrServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
 )
 
 type ErrorResponse struct {
@@ -52,6 +56,14 @@ func ErrorUnauthorized(c *gin.Context, message string) {
 	WriteErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, message, nil)
 }
 
+func ErrorForbidden(c *gin.Context, message string) {
+	WriteErrorResponse(c, http.StatusForbidden, ErrForbidden, message, nil)
+}
+
+func ErrorConflict(c *gin.Context, message string) {
+	WriteErrorResponse(c, http.StatusConflict, ErrConflict, message, nil)
+}
+
 func WriteErrorResponse(c *gin.Context, status int, code ErrorCode, message string, details interface{}) {
 	c.JSON(status, ErrorResponse{
 		Status:  status,
@@ -4,21 +4,24 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/warnings"
 )
 
 type SuccessResponse struct {
-	Status  int         `json:"status"`
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Status   int                `json:"status"`
+	Success  bool               `json:"success"`
+	Message  string             `json:"message,omitempty"`
+	Data     interface{}        `json:"data,omitempty"`
+	Warnings []warnings.Warning `json:"warnings,omitempty"`
 }
 
 func WriteSuccessResponse(c *gin.Context, status int, message string, data interface{}) {
 	c.JSON(status, SuccessResponse{
-		Status:  status,
-		Success: true,
-		Message: message,
-		Data:    data,
+		Status:   status,
+		Success:  true,
+		Message:  message,
+		Data:     data,
+		Warnings: warnings.From(c.Request.Context()),
 	})
 }
 
@@ -0,0 +1,72 @@
+// Package sanitize cleans user-provided text before it is stored or
+// embedded in HTML, e.g. in emails. Each field class has a bluemonday
+// policy: plain text fields keep no markup at all, rich text fields keep
+// the formatting of user generated content and safe links.
+package sanitize
+
+import (
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// PlainTextPolicy keeps no markup, for names, titles and chat messages
+	PlainTextPolicy = bluemonday.StrictPolicy()
+
+	// RichTextPolicy keeps the formatting of user generated content and
+	// absolute http(s)/mailto links, for descriptions written with the
+	// rich text editor
+	RichTextPolicy = richTextPolicy()
+)
+
+func richTextPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowURLSchemes("http", "https", "mailto")
+	// Relative links would resolve against whatever site shows the text,
+	// e.g. the email client
+	policy.AllowRelativeURLs(false)
+	policy.RequireNoReferrerOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return policy
+}
+
+// PlainText returns the text of s with every tag removed and entities
+// decoded. Escape it before embedding it in HTML; html/template does.
+func PlainText(s string) string {
+	return html.UnescapeString(PlainTextPolicy.Sanitize(s))
+}
+
+// RichText returns s reduced to the elements of RichTextPolicy, with every
+// element closed
+func RichText(s string) string {
+	return balance(RichTextPolicy.Sanitize(s))
+}
+
+// RichTextHTML sanitizes s for use as trusted HTML in html/template
+func RichTextHTML(s string) template.HTML {
+	return template.HTML(RichText(s))
+}
+
+// balance parses s as the content of a <div> and renders it back, which
+// closes unclosed elements and drops stray end tags, so the text can't
+// break out of the markup it is embedded in
+func balance(s string) string {
+	context := &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(s), context)
+	if err != nil {
+		return html.EscapeString(s)
+	}
+
+	var builder strings.Builder
+	for _, node := range nodes {
+		if err := nethtml.Render(&builder, node); err != nil {
+			return html.EscapeString(s)
+		}
+	}
+	return builder.String()
+}
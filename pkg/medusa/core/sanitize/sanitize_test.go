@@ -0,0 +1,239 @@
+package sanitize
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	nethtml "golang.org/x/net/html"
+)
+
+var xssPayloads = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=//evil.example/x.js></SCRIPT>`,
+	`<script>alert(1)`,
+	`<scr<script>ipt>alert(1)</script>`,
+	`<<script>script>alert(1)<</script>/script>`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<a href="JaVaScRiPt:alert(1)">x</a>`,
+	`<a href=" javascript:alert(1)">x</a>`,
+	"<a href=\"java\tscript:alert(1)\">x</a>",
+	`<a href="jav&#x61;script:alert(1)">x</a>`,
+	`<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`,
+	`<a href="javascript&colon;alert(1)">x</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+	`<a href="vbscript:msgbox(1)">x</a>`,
+	`<img src=x onerror=alert(1)>`,
+	`<p onclick="alert(1)">x</p>`,
+	`<b onmouseover=alert(1)>x</b>`,
+	`<a href="https://example.com" onclick="alert(1)">x</a>`,
+	`<svg onload=alert(1)>`,
+	`<svg/onload=alert(1)>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+	`<iframe src="javascript:alert(1)"></iframe>`,
+	`<style>body{background:url(javascript:alert(1))}</style>`,
+	`<p style="background:url(javascript:alert(1))">x</p>`,
+	`<form action="javascript:alert(1)"><button>x</button></form>`,
+	`<a href="https://example.com"><p><b>unclosed`,
+	`<b><i>nested</b></i>`,
+	`</p></b></a><script>alert(1)</script>`,
+	`<p>"><script>alert(1)</script></p>`,
+	`<!--<script>alert(1)</script>-->`,
+	`<![CDATA[<script>alert(1)</script>]]>`,
+	`<noscript><p title="</noscript><img src=x onerror=alert(1)>"></noscript>`,
+	`<textarea><script>alert(1)</script></textarea>`,
+}
+
+// unsafeElements run script, load content or submit data; none may survive
+var unsafeElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"svg": true, "math": true, "form": true, "button": true, "input": true,
+	"textarea": true, "noscript": true, "base": true, "meta": true, "link": true,
+}
+
+// assertSafe parses out again and fails on any scripting element, event
+// handler, inline style or URL outside http(s) and mailto
+func assertSafe(t *testing.T, input, out string) {
+	t.Helper()
+
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(out))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			return
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if unsafeElements[token.Data] {
+				t.Errorf("Sanitize(%q) = %q, keeps element <%s>", input, out, token.Data)
+			}
+			for _, attr := range token.Attr {
+				if strings.HasPrefix(attr.Key, "on") || attr.Key == "style" {
+					t.Errorf("Sanitize(%q) = %q, keeps attribute %s", input, out, attr.Key)
+				}
+				if (attr.Key == "href" || attr.Key == "src") && !allowedURL(attr.Val) {
+					t.Errorf("Sanitize(%q) = %q, keeps %s %q", input, out, attr.Key, attr.Val)
+				}
+			}
+		case nethtml.CommentToken, nethtml.DoctypeToken:
+			t.Errorf("Sanitize(%q) = %q, keeps %q", input, out, token.String())
+		}
+	}
+}
+
+func allowedURL(value string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func TestRichTextXSS(t *testing.T) {
+	for _, payload := range xssPayloads {
+		out := RichText(payload)
+		assertSafe(t, payload, out)
+
+		// Payload text may remain, escaped, but never as markup
+		if strings.Contains(strings.ToLower(out), "<script") {
+			t.Errorf("RichText(%q) = %q, contains a script tag", payload, out)
+		}
+	}
+}
+
+func TestPlainTextXSS(t *testing.T) {
+	for _, payload := range xssPayloads {
+		out := PlainTextPolicy.Sanitize(payload)
+		if strings.ContainsAny(out, "<>") {
+			t.Errorf("Sanitize(%q) = %q, want no markup", payload, out)
+		}
+	}
+}
+
+func TestRichText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "keeps formatting",
+			input: `<p>Hello <b>bold</b> and <em>em</em></p>`,
+			want:  `<p>Hello <b>bold</b> and <em>em</em></p>`,
+		},
+		{
+			name:  "drops script with its content",
+			input: `<p>hi</p><script>alert(1)</script>`,
+			want:  `<p>hi</p>`,
+		},
+		{
+			name:  "drops unclosed script to the end",
+			input: `<p>hi</p><script>alert(1)<p>more</p>`,
+			want:  `<p>hi</p>`,
+		},
+		{
+			name:  "drops svg markup",
+			input: `a<svg onload=alert(1)><text>b</text></svg>c`,
+			want:  `abc`,
+		},
+		{
+			name:  "keeps layout elements without handlers",
+			input: `<div onclick="x"><span>text</span></div>`,
+			want:  `<div><span>text</span></div>`,
+		},
+		{
+			name:  "drops event handlers",
+			input: `<p onclick="alert(1)" onmouseover="alert(2)">x</p>`,
+			want:  `<p>x</p>`,
+		},
+		{
+			name:  "keeps safe links",
+			input: `<a href="https://example.com/a?b=1&c=2">x</a>`,
+			want:  `<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noreferrer noopener" target="_blank">x</a>`,
+		},
+		{
+			name:  "keeps mailto links",
+			input: `<a href="mailto:help@example.com">x</a>`,
+			want:  `<a href="mailto:help@example.com" rel="nofollow noreferrer">x</a>`,
+		},
+		{
+			name:  "drops links with a javascript href",
+			input: `<a href="javascript:alert(1)">x</a>`,
+			want:  `x`,
+		},
+		{
+			name:  "drops links with an entity encoded javascript href",
+			input: `<a href="&#x6A;avascript&#x3A;alert(1)">x</a>`,
+			want:  `x`,
+		},
+		{
+			name:  "drops links with a data href",
+			input: `<a href="data:text/html,<script>alert(1)</script>">x</a>`,
+			want:  `x`,
+		},
+		{
+			name:  "drops links with a relative href",
+			input: `<a href="//evil.example">x</a>`,
+			want:  `x`,
+		},
+		{
+			name:  "closes unclosed tags",
+			input: `<p><b>bold`,
+			want:  `<p><b>bold</b></p>`,
+		},
+		{
+			name:  "closes misnested tags in order",
+			input: `<b><i>x</b>y</i>`,
+			want:  `<b><i>x</i></b><i>y</i>`,
+		},
+		{
+			name:  "ignores stray end tags",
+			input: `</p></blockquote>text`,
+			want:  `<p></p>text`,
+		},
+		{
+			name:  "escapes text",
+			input: `1 < 2 & "quoted"`,
+			want:  `1 &lt; 2 &amp; &#34;quoted&#34;`,
+		},
+		{
+			name:  "escapes attribute breakouts",
+			input: `<a href='https://example.com/"onmouseover="alert(1)'>x</a>`,
+			want:  `<a href="https://example.com/%22onmouseover=%22alert%281%29" rel="nofollow noreferrer noopener" target="_blank">x</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RichText(tt.input); got != tt.want {
+				t.Errorf("RichText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`<b>Jane</b> Doe`, `Jane Doe`},
+		{`Tom &amp; Jerry`, `Tom & Jerry`},
+		{`<script>alert(1)</script>Name`, `Name`},
+		{`<img src=x onerror=alert(1)>Name`, `Name`},
+		{`a < b`, `a < b`},
+	}
+
+	for _, tt := range tests {
+		if got := PlainText(tt.input); got != tt.want {
+			t.Errorf("PlainText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/imlargo/go-api/pkg/medusa/core/logger"
@@ -57,15 +56,17 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop stops accepting connections and waits for the in-flight requests
+// until ctx expires. Requests still running then are cut off.
 func (s *Server) Stop(ctx context.Context) error {
+	if s.httpSrv == nil {
+		return nil
+	}
 	s.logger.Sugar().Info("Shutting down server...")
 
-	// The context is used to inform the server it has 5 seconds to finish
-	// the request it is currently handling
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
 	if err := s.httpSrv.Shutdown(ctx); err != nil {
-		s.logger.Sugar().Fatal("Server forced to shutdown: ", err)
+		s.logger.Sugar().Warn("Server forced to shutdown: ", err)
+		return s.httpSrv.Close()
 	}
 
 	s.logger.Sugar().Info("Server exiting")
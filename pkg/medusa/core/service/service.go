@@ -1,17 +1,45 @@
 package service
 
 import (
+	"github.com/imlargo/go-api/pkg/medusa/core/clock"
 	"github.com/imlargo/go-api/pkg/medusa/core/logger"
 )
 
 type Service struct {
 	logger *logger.Logger
+	clock  clock.Clock
+}
+
+type ServiceOption func(*Service)
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake in tests
+func WithClock(c clock.Clock) ServiceOption {
+	return func(s *Service) {
+		s.clock = c
+	}
 }
 
 func NewService(
 	logger *logger.Logger,
+	opts ...ServiceOption,
 ) *Service {
-	return &Service{
-		logger,
+	s := &Service{
+		logger: logger,
+		clock:  clock.Real(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
+}
+
+func (s *Service) Logger() *logger.Logger {
+	return s.logger
+}
+
+// Clock is the source of the current time for deadlines and timestamps
+func (s *Service) Clock() clock.Clock {
+	return s.clock
 }
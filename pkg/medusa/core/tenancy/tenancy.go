@@ -0,0 +1,176 @@
+// Package tenancy isolates tenants in their own Postgres schema. The
+// tenant of a request is carried in its context, and the GORM plugin
+// qualifies every table of the statements run with that context, e.g.
+// "support_tickets" becomes "tenant_acme"."support_tickets".
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"gorm.io/gorm"
+)
+
+const schemaPrefix = "tenant_"
+
+var (
+	ErrInvalidSlug = apperrors.Validation("invalid tenant slug, use 2 to 32 lowercase letters, digits or underscores starting with a letter").WithCode("INVALID_TENANT_SLUG")
+	ErrCrossSchema = errors.New("query references a schema outside the current tenant")
+	ErrRawQuery    = errors.New("raw queries are not allowed in a tenant context")
+	ErrNoTenant    = errors.New("query on a tenant table without a tenant in context")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+// Shared is implemented by models stored in the public schema for every
+// tenant, like the tenant registry itself
+type Shared interface {
+	TenantShared()
+}
+
+type ctxKey string
+
+const (
+	tenantKey   ctxKey = "tenancy:tenant"
+	allowRawKey ctxKey = "tenancy:allow_raw"
+	publicKey   ctxKey = "tenancy:public"
+)
+
+func ValidateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return ErrInvalidSlug
+	}
+	return nil
+}
+
+// SchemaName is the Postgres schema holding the data of a tenant
+func SchemaName(slug string) string {
+	return schemaPrefix + slug
+}
+
+// WithTenant runs the queries issued with ctx in the schema of slug
+func WithTenant(ctx context.Context, slug string) context.Context {
+	return context.WithValue(ctx, tenantKey, slug)
+}
+
+// Tenant returns the tenant slug stored in ctx, or "" outside a tenant
+func Tenant(ctx context.Context) string {
+	slug, _ := ctx.Value(tenantKey).(string)
+	return slug
+}
+
+// Key scopes a key prefix to the tenant of ctx, e.g. "api_usage" becomes
+// "api_usage:acme", for state kept outside the database like Redis keys.
+// Ids repeat across tenant schemas, so keys built from them must be scoped.
+func Key(ctx context.Context, prefix string) string {
+	if tenant := Tenant(ctx); tenant != "" {
+		return prefix + ":" + tenant
+	}
+	return prefix
+}
+
+// AllowRaw lets raw SQL run in a tenant context. The caller is responsible
+// for qualifying its tables with the tenant schema.
+func AllowRaw(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowRawKey, true)
+}
+
+// Public marks ctx as intentionally running outside any tenant, e.g. to
+// migrate the public schema. Only needed when tenants are required.
+func Public(ctx context.Context) context.Context {
+	return context.WithValue(ctx, publicKey, true)
+}
+
+type plugin struct {
+	requireTenant bool
+}
+
+// New returns a GORM plugin routing the statements of a tenant context to
+// the tenant schema. With requireTenant, statements on tenant tables fail
+// unless ctx carries a tenant or is marked Public, so code that drops the
+// request context can't read another schema by accident.
+func New(requireTenant bool) gorm.Plugin {
+	return &plugin{requireTenant: requireTenant}
+}
+
+func (p *plugin) Name() string {
+	return "tenancy"
+}
+
+func (p *plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	registrations := []error{
+		callbacks.Create().Before("gorm:create").Register("tenancy:create", p.qualify),
+		callbacks.Query().Before("gorm:query").Register("tenancy:query", p.qualify),
+		callbacks.Update().Before("gorm:update").Register("tenancy:update", p.qualify),
+		callbacks.Delete().Before("gorm:delete").Register("tenancy:delete", p.qualify),
+		callbacks.Row().Before("gorm:row").Register("tenancy:row", p.qualify),
+		callbacks.Raw().Before("gorm:raw").Register("tenancy:raw", p.guardRaw),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) qualify(db *gorm.DB) {
+	stmt := db.Statement
+	if stmt.Table == "" {
+		return
+	}
+	if stmt.Schema != nil {
+		if _, shared := reflect.New(stmt.Schema.ModelType).Interface().(Shared); shared {
+			return
+		}
+	}
+
+	slug := Tenant(stmt.Context)
+	if slug == "" {
+		if public, _ := stmt.Context.Value(publicKey).(bool); p.requireTenant && !public {
+			db.AddError(ErrNoTenant)
+		}
+		return
+	}
+
+	schema := SchemaName(slug)
+
+	// Table("other_schema.table") keeps the schema in TableExpr
+	if stmt.TableExpr != nil {
+		qualifier, _, found := strings.Cut(strings.ReplaceAll(stmt.TableExpr.SQL, `"`, ""), ".")
+		if found && qualifier != schema {
+			db.AddError(ErrCrossSchema)
+			return
+		}
+		if found {
+			return
+		}
+		stmt.TableExpr = nil
+	}
+
+	if strings.Contains(stmt.Table, ".") {
+		if !strings.HasPrefix(stmt.Table, schema+".") {
+			db.AddError(ErrCrossSchema)
+		}
+		return
+	}
+
+	stmt.Table = schema + "." + stmt.Table
+}
+
+func (p *plugin) guardRaw(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if Tenant(ctx) == "" {
+		return
+	}
+	if allowed, _ := ctx.Value(allowRawKey).(bool); allowed {
+		return
+	}
+	db.AddError(ErrRawQuery)
+}
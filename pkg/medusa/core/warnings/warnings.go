@@ -0,0 +1,50 @@
+package warnings
+
+import (
+	"context"
+	"sync"
+)
+
+type ctxKey struct{}
+
+// Warning is a condition that doesn't fail the request but that the
+// client should know about, e.g. a default being applied
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type collector struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// WithCollector returns a context that collects the warnings added to it
+func WithCollector(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &collector{})
+}
+
+// Add records a warning on ctx. It does nothing when ctx has no collector,
+// so services can add warnings regardless of who calls them.
+func Add(ctx context.Context, code string, message string) {
+	c, ok := ctx.Value(ctxKey{}).(*collector)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, Warning{Code: code, Message: message})
+}
+
+// From returns the warnings collected on ctx
+func From(ctx context.Context) []Warning {
+	c, ok := ctx.Value(ctxKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Warning(nil), c.warnings...)
+}
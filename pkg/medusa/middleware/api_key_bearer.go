@@ -38,6 +38,8 @@ func BearerApiKeyMiddleware(apiKey string) gin.HandlerFunc {
 			return
 		}
 
+		// The single key has the admin role of BearerApiKeyRolesMiddleware
+		ctx.Set("apiKeyRole", "admin")
 		ctx.Next()
 	}
 }
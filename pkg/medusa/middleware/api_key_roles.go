@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+// BearerApiKeyRolesMiddleware authenticates a bearer API key against a set
+// of keys and stores the role of the matching key in the "apiKeyRole"
+// context key
+func BearerApiKeyRolesMiddleware(keys map[string]string) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		authHeader := ctx.GetHeader("Authorization")
+
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" || parts[1] == "" {
+			ctx.Abort()
+			responses.ErrorUnauthorized(ctx, "authorization header must be in format 'Bearer token'")
+			return
+		}
+
+		for key, role := range keys {
+			if key != "" && subtle.ConstantTimeCompare([]byte(parts[1]), []byte(key)) == 1 {
+				ctx.Set("apiKeyRole", role)
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.Abort()
+		responses.ErrorUnauthorized(ctx, "invalid API key")
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/audit"
+)
+
+type AuditRecorder interface {
+	RecordRequest(ctx context.Context, request *audit.Request) error
+}
+
+// NewAuditMiddleware records every POST, PUT, PATCH and DELETE with the
+// writes it made, see the audit package. The database must use the audit
+// plugin.
+func NewAuditMiddleware(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		ctx, writes := audit.WithRecorder(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// Unmatched paths changed nothing, recording them would only log
+		// scanners
+		if c.FullPath() == "" {
+			return
+		}
+
+		request := &audit.Request{
+			Actor:     "anonymous",
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			Status:    responseStatus(c),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString("requestID"),
+			Entries:   writes.Entries(),
+		}
+		if userID, ok := c.Get("userID"); ok {
+			if id, ok := userID.(uint); ok && id != 0 {
+				request.Actor = fmt.Sprintf("user:%d", id)
+				request.UserID = &id
+			}
+		} else if role := c.GetString("apiKeyRole"); role != "" {
+			request.Actor = "api_key:" + role
+		}
+
+		// The request is done, a client hanging up must not lose its record
+		// and auditing must never fail the request
+		_ = recorder.RecordRequest(context.WithoutCancel(c.Request.Context()), request)
+	}
+}
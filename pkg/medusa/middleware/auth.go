@@ -1,14 +1,24 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/imlargo/go-api/pkg/medusa/core/jwt"
 	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
 )
 
-func AuthTokenMiddleware(jwtAuthenticator *jwt.JWT) gin.HandlerFunc {
+// ClaimsVersionSource returns the current claims version of a user
+type ClaimsVersionSource interface {
+	ClaimsVersion(ctx context.Context, userID uint) (int64, error)
+}
+
+// AuthTokenMiddleware authenticates the bearer JWT. When versions is not
+// nil, tokens issued before the user's claims version was bumped are
+// rejected so the client signs in again and gets fresh claims.
+func AuthTokenMiddleware(jwtAuthenticator *jwt.JWT, versions ClaimsVersionSource) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 
@@ -39,6 +49,28 @@ func AuthTokenMiddleware(jwtAuthenticator *jwt.JWT) gin.HandlerFunc {
 			return
 		}
 
+		// A token is only valid on the tenant it was issued for, user ids
+		// repeat across tenant schemas
+		if tokenData.Tenant != tenancy.Tenant(ctx.Request.Context()) {
+			ctx.Abort()
+			responses.ErrorUnauthorized(ctx, "token was issued for another tenant")
+			return
+		}
+
+		if versions != nil {
+			current, err := versions.ClaimsVersion(ctx.Request.Context(), tokenData.UserID)
+			if err != nil {
+				ctx.Abort()
+				responses.ErrorInternalServerWithMessage(ctx, "could not validate session", err.Error())
+				return
+			}
+			if tokenData.ClaimsVersion < current {
+				ctx.Abort()
+				responses.ErrorUnauthorized(ctx, "session is outdated, sign in again")
+				return
+			}
+		}
+
 		ctx.Set("userID", tokenData.UserID)
 
 		ctx.Next()
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	bulkheadCapacity = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bulkhead_capacity",
+		Help: "Maximum in-flight requests allowed by a bulkhead",
+	}, []string{"bulkhead"})
+
+	bulkheadInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bulkhead_in_flight",
+		Help: "Requests currently running inside a bulkhead",
+	}, []string{"bulkhead"})
+
+	bulkheadQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bulkhead_queued",
+		Help: "Requests waiting for a slot in a bulkhead",
+	}, []string{"bulkhead"})
+
+	bulkheadWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bulkhead_wait_seconds",
+		Help:    "Time requests waited for a bulkhead slot",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"bulkhead"})
+
+	bulkheadRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bulkhead_rejected_total",
+		Help: "Requests rejected by a bulkhead",
+	}, []string{"bulkhead", "reason"})
+)
+
+type BulkheadConfig struct {
+	// MaxInFlight is the number of requests that run at the same time. Zero
+	// or less disables the bulkhead.
+	MaxInFlight int
+
+	// MaxQueue is the number of requests that wait for a slot once every
+	// slot is taken. Requests beyond it are rejected right away.
+	MaxQueue int
+
+	// MaxWait is how long a queued request waits before it is rejected
+	MaxWait time.Duration
+}
+
+// Bulkhead caps the concurrent requests of a route group so a burst on one
+// group can't take every database connection from the others
+type Bulkhead struct {
+	name   string
+	config BulkheadConfig
+	slots  chan struct{}
+	queue  chan struct{}
+}
+
+func NewBulkhead(name string, config BulkheadConfig) *Bulkhead {
+	bulkhead := &Bulkhead{
+		name:   name,
+		config: config,
+	}
+
+	if config.MaxInFlight > 0 {
+		bulkhead.slots = make(chan struct{}, config.MaxInFlight)
+		bulkhead.queue = make(chan struct{}, max(config.MaxQueue, 0))
+		bulkheadCapacity.WithLabelValues(name).Set(float64(config.MaxInFlight))
+	}
+
+	return bulkhead
+}
+
+// Middleware runs the request inside the bulkhead, answering 503 with
+// Retry-After when the queue is full or the wait times out
+func (b *Bulkhead) Middleware() gin.HandlerFunc {
+	if b.slots == nil {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	retryAfter := strconv.Itoa(max(1, int(math.Ceil(b.config.MaxWait.Seconds()))))
+
+	return func(ctx *gin.Context) {
+		if reason, ok := b.acquire(ctx); !ok {
+			bulkheadRejected.WithLabelValues(b.name, reason).Inc()
+			ctx.Header("Retry-After", retryAfter)
+			ctx.Abort()
+			responses.WriteErrorResponse(ctx, http.StatusServiceUnavailable, responses.ErrServiceUnavailable, "server is busy, try again later", gin.H{"bulkhead": b.name})
+			return
+		}
+
+		bulkheadInFlight.WithLabelValues(b.name).Inc()
+		defer func() {
+			<-b.slots
+			bulkheadInFlight.WithLabelValues(b.name).Dec()
+		}()
+
+		ctx.Next()
+	}
+}
+
+// acquire takes a slot, queueing for up to MaxWait. On failure it returns
+// the rejection reason used in the metrics.
+func (b *Bulkhead) acquire(ctx *gin.Context) (string, bool) {
+	select {
+	case b.slots <- struct{}{}:
+		bulkheadWait.WithLabelValues(b.name).Observe(0)
+		return "", true
+	default:
+	}
+
+	select {
+	case b.queue <- struct{}{}:
+	default:
+		return "queue_full", false
+	}
+
+	bulkheadQueued.WithLabelValues(b.name).Inc()
+	defer func() {
+		<-b.queue
+		bulkheadQueued.WithLabelValues(b.name).Dec()
+	}()
+
+	start := time.Now()
+	timer := time.NewTimer(b.config.MaxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		bulkheadWait.WithLabelValues(b.name).Observe(time.Since(start).Seconds())
+		return "", true
+	case <-timer.C:
+		return "timeout", false
+	case <-ctx.Request.Context().Done():
+		return "canceled", false
+	}
+}
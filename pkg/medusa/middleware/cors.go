@@ -33,6 +33,7 @@ func NewCorsMiddleware(host string, origins []string) gin.HandlerFunc {
 			"X-Real-IP",
 			"X-Forwarded-Host",
 			"X-Forwarded-Proto",
+			ReadConsistencyHeader,
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
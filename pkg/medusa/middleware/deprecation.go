@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware announces that the routes it wraps are deprecated
+// with the Deprecation header, and with Sunset when a removal date is set.
+// link, when not empty, points to the migration guide. Mark the handlers
+// @Deprecated as well so the spec diff allows their removal.
+func DeprecationMiddleware(sunset time.Time, link string) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", "true")
+		if !sunset.IsZero() {
+			ctx.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if link != "" {
+			ctx.Header("Link", "<"+link+`>; rel="deprecation"`)
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+type ResponseRecorder interface {
+	RecordResponse(route string, status int)
+}
+
+// NewErrorRateMiddleware reports the route and final status of every
+// matched request, e.g. to compute error rates for the status page
+func NewErrorRateMiddleware(recorder ResponseRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.FullPath() == "" {
+			return
+		}
+		recorder.RecordResponse(c.FullPath(), responseStatus(c))
+	}
+}
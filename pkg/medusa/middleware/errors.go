@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/logger"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var errorStatuses = map[apperrors.Kind]int{
+	apperrors.KindNotFound:     http.StatusNotFound,
+	apperrors.KindValidation:   http.StatusBadRequest,
+	apperrors.KindConflict:     http.StatusConflict,
+	apperrors.KindForbidden:    http.StatusForbidden,
+	apperrors.KindRateLimited:  http.StatusTooManyRequests,
+	apperrors.KindUnauthorized: http.StatusUnauthorized,
+}
+
+// ErrorHandlerMiddleware writes the response of handlers that failed with
+// c.Error(err). Typed errors get their status and code; anything else is
+// logged and reported as an internal error, without leaking its message.
+func ErrorHandlerMiddleware(logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		if appErr, ok := apperrors.As(err); ok && appErr.Kind != apperrors.KindInternal {
+			// Errors wrapped with fmt.Errorf add context worth returning,
+			// the cause of a wrapped *Error is internal
+			message := appErr.Message
+			if _, top := err.(*apperrors.Error); !top {
+				message = err.Error()
+			}
+			responses.WriteErrorResponse(c, errorStatuses[appErr.Kind], responses.ErrorCode(appErr.Code), message, appErr.Details)
+			return
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.ErrorNotFound(c, "resource")
+			return
+		}
+
+		logger.WithContext(c.Request.Context()).Error("request failed", zap.String("route", c.FullPath()), zap.Error(err))
+		responses.ErrorInternalServer(c, nil)
+	}
+}
+
+// responseStatus is the status of the response to c once
+// ErrorHandlerMiddleware has run, for middlewares that run inside it
+func responseStatus(c *gin.Context) int {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return c.Writer.Status()
+	}
+
+	err := c.Errors.Last().Err
+	if appErr, ok := apperrors.As(err); ok && appErr.Kind != apperrors.KindInternal {
+		return errorStatuses[appErr.Kind]
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -31,9 +31,10 @@ func NewMetricsMiddleware(metrics metrics.MetricsService) gin.HandlerFunc {
 		path := c.FullPath()
 		status := strconv.Itoa(c.Writer.Status())
 
-		// If no path was resolved (404), use the real URL
+		// Unmatched paths share one label, every scanned URL would be a
+		// new series
 		if path == "" {
-			path = c.Request.URL.Path
+			path = "unmatched"
 		}
 
 		// Record metrics
@@ -2,16 +2,42 @@ package middleware
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/imlargo/go-api/pkg/medusa/core/ratelimiter"
 	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
 )
 
 func NewRateLimiterMiddleware(rl ratelimiter.RateLimiter) gin.HandlerFunc {
+	return NewRouteRateLimiterMiddleware(rl, nil)
+}
+
+// NewRouteRateLimiterMiddleware limits each client with the limiter of the
+// longest path prefix in routes that matches the request, or fallback.
+// Authenticated clients are limited per user, so a team behind one NAT
+// doesn't share a limit; anonymous ones per IP.
+func NewRouteRateLimiterMiddleware(fallback ratelimiter.RateLimiter, routes map[string]ratelimiter.RateLimiter) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		ip := ctx.ClientIP()
-		allow, retryAfter := rl.Allow(ip)
+		rl := fallback
+		matched := ""
+		for prefix, limiter := range routes {
+			if strings.HasPrefix(ctx.Request.URL.Path, prefix) && len(prefix) > len(matched) {
+				rl, matched = limiter, prefix
+			}
+		}
+
+		key := "ip:" + ctx.ClientIP()
+		if userID, exists := ctx.Get("userID"); exists {
+			key = fmt.Sprintf("user:%v", userID)
+		}
+		// User ids repeat across tenant schemas
+		if tenant := tenancy.Tenant(ctx.Request.Context()); tenant != "" {
+			key = tenant + ":" + key
+		}
+
+		allow, retryAfter := rl.Allow(key)
 		if !allow {
 			message := "Rate limit exceeded. Try again in " + fmt.Sprintf("%.2f", retryAfter)
 			responses.ErrorTooManyRequests(ctx, message)
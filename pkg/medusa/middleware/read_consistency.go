@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	medusarepo "github.com/imlargo/go-api/pkg/medusa/core/repository"
+)
+
+// ReadConsistencyHeader set to primary makes a read request skip the
+// replica, e.g. to read back what the client just wrote
+const ReadConsistencyHeader = "X-Read-Consistency"
+
+// ReadConsistencyMiddleware sends every read of a request to the primary
+// database when the request writes, so it sees its own writes, or when the
+// client asks for it with ReadConsistencyHeader
+func ReadConsistencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if c.GetHeader(ReadConsistencyHeader) != "primary" {
+				c.Next()
+				return
+			}
+		}
+
+		c.Request = c.Request.WithContext(medusarepo.ReadPrimary(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+)
+
+// ReadOnlyMiddleware rejects every request that could write, letting reads
+// through. It is used when the API runs against a schema it can read but
+// should not write to.
+func ReadOnlyMiddleware() gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		ctx.Abort()
+		responses.WriteErrorResponse(ctx, http.StatusServiceUnavailable, responses.ErrServiceUnavailable, "the API is in read-only mode", nil)
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/querytag"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware reuses the X-Request-ID header of the request, or
+// generates one, echoes it in the response and stores it in the "requestID"
+// context key and in the request context, where database queries pick it up
+func RequestIDMiddleware() gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			requestID = hex.EncodeToString(buf)
+		}
+
+		ctx.Set("requestID", requestID)
+		ctx.Header(requestIDHeader, requestID)
+		ctx.Request = ctx.Request.WithContext(querytag.WithRequestID(ctx.Request.Context(), requestID))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/logger"
+	"go.uber.org/zap"
+)
+
+// RequestLoggerMiddleware writes a structured access log line per request.
+// It must run after RequestIDMiddleware, so the line carries the request id
+// the service logs of the request are tagged with.
+func RequestLoggerMiddleware(logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// SSE streams stay open for the whole connection
+		if c.GetHeader("Accept") == "text/event-stream" || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		// Process the request
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("request_id", c.GetString("requestID")),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("route", route),
+			zap.Int("status", status),
+			zap.Int64("latency_ms", time.Since(start).Milliseconds()),
+			zap.Int("bytes", c.Writer.Size()),
+			zap.String("client_ip", c.ClientIP()),
+		}
+
+		// Set by the auth middleware on authenticated routes
+		if userID, exists := c.Get("userID"); exists {
+			fields = append(fields, zap.Any("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("request", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("request", fields...)
+		default:
+			logger.Info("request", fields...)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/responses"
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+)
+
+// TenantExists reports whether a tenant slug is provisioned
+type TenantExists func(ctx context.Context, slug string) (bool, error)
+
+// TenantMiddleware resolves the tenant from header and runs the request's
+// queries in its schema. Requests without a known tenant are rejected.
+func TenantMiddleware(header string, exists TenantExists) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		slug := ctx.GetHeader(header)
+		if slug == "" {
+			ctx.Abort()
+			responses.ErrorBadRequest(ctx, header+" header is required")
+			return
+		}
+
+		if err := tenancy.ValidateSlug(slug); err != nil {
+			ctx.Abort()
+			responses.ErrorBadRequest(ctx, err.Error())
+			return
+		}
+
+		found, err := exists(ctx.Request.Context(), slug)
+		if err != nil {
+			ctx.Abort()
+			responses.ErrorInternalServerWithMessage(ctx, "could not resolve tenant", err.Error())
+			return
+		}
+		if !found {
+			ctx.Abort()
+			responses.WriteErrorResponse(ctx, http.StatusNotFound, responses.ErrNotFound, "tenant not found", nil)
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(tenancy.WithTenant(ctx.Request.Context(), slug))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageRecorder interface {
+	Record(ctx context.Context, userID uint, method, route string, bytesIn, bytesOut int64) error
+}
+
+// NewUsageMiddleware records request counts and byte volumes for
+// authenticated requests. It must run after AuthTokenMiddleware.
+func NewUsageMiddleware(recorder UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// Skip if it's an SSE request
+		if c.GetHeader("Accept") == "text/event-stream" || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		userID, ok := c.Get("userID")
+		if !ok {
+			return
+		}
+
+		id, ok := userID.(uint)
+		if !ok || id == 0 {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		bytesIn := c.Request.ContentLength
+		if bytesIn < 0 {
+			bytesIn = 0
+		}
+
+		bytesOut := int64(c.Writer.Size())
+		if bytesOut < 0 {
+			bytesOut = 0
+		}
+
+		// Usage tracking must never fail the request
+		_ = recorder.Record(c.Request.Context(), id, c.Request.Method, route, bytesIn, bytesOut)
+	}
+}
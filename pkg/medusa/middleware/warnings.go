@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imlargo/go-api/pkg/medusa/core/warnings"
+)
+
+// WarningsMiddleware attaches a warnings collector to the request context.
+// Warnings added while handling the request are returned in the warnings
+// field of the success response.
+func WarningsMiddleware() gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(warnings.WithCollector(ctx.Request.Context()))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// The hit ratio is hit over hit and miss
+	lookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_lookups_total",
+		Help: "Cache reads by result: hit, miss or error",
+	}, []string{"result"})
+
+	// The warm-hit ratio is warm_hit over all lookups of a name
+	warmLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_warm_lookups_total",
+		Help: "Warmed cache lookups by result: warm_hit, hit or miss",
+	}, []string{"name", "result"})
+
+	warmRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_warm_refreshes_total",
+		Help: "Values recomputed ahead of a request, by trigger",
+	}, []string{"name", "trigger"})
+)
@@ -29,10 +29,13 @@ func (r *redisCache) Get(ctx context.Context, key string, dest interface{}) erro
 	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
+			lookups.WithLabelValues("miss").Inc()
 			return ErrKeyNotFound
 		}
+		lookups.WithLabelValues("error").Inc()
 		return fmt.Errorf("failed to get key %s: %w", key, err)
 	}
+	lookups.WithLabelValues("hit").Inc()
 
 	if err := json.Unmarshal([]byte(val), dest); err != nil {
 		return fmt.Errorf("failed to unmarshal value for key %s: %w", key, err)
@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+	"github.com/redis/go-redis/v9"
+)
+
+const warmKeyPrefix = "cache_warm"
+
+// Loader recomputes the value cached for id
+type Loader func(ctx context.Context, id string) (any, error)
+
+type WarmerConfig struct {
+	// TopN is how many of the most accessed keys of each cohort are kept warm
+	TopN int
+	// Lead is how long before expiry a hot key is recomputed
+	Lead time.Duration
+}
+
+// Warmer caches expensive reads and recomputes the most accessed ones
+// before they expire, so popular pages never pay for a cold cache. Accesses
+// are counted per cohort, a group of users with similar traffic.
+type Warmer interface {
+	// Register adds a named loader whose values are cached for ttl
+	Register(name string, ttl time.Duration, load Loader)
+	// Get reads the value of id into dest, loading it on a miss
+	Get(ctx context.Context, name, cohort, id string, dest any) error
+	// Refresh recomputes id if it is cached, after the data behind it changed
+	Refresh(ctx context.Context, name, id string) error
+	// WarmExpiring recomputes the hot keys about to expire and returns how
+	// many were warmed
+	WarmExpiring(ctx context.Context) (int, error)
+}
+
+type warmedLoader struct {
+	ttl  time.Duration
+	load Loader
+}
+
+// warmEntry records whether a value was written ahead of the request, which
+// is what the warm-hit ratio measures
+type warmEntry struct {
+	Warmed bool            `json:"warmed"`
+	Value  json.RawMessage `json:"value"`
+}
+
+type redisWarmer struct {
+	client *redis.Client
+	config WarmerConfig
+
+	mu      sync.RWMutex
+	loaders map[string]warmedLoader
+}
+
+func NewRedisWarmer(client *redis.Client, config WarmerConfig) Warmer {
+	return &redisWarmer{
+		client:  client,
+		config:  config,
+		loaders: make(map[string]warmedLoader),
+	}
+}
+
+func (w *redisWarmer) Register(name string, ttl time.Duration, load Loader) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loaders[name] = warmedLoader{ttl: ttl, load: load}
+}
+
+// Get serves from the cache when it can. Redis errors are treated as a miss,
+// the cache never fails a read the database can answer.
+func (w *redisWarmer) Get(ctx context.Context, name, cohort, id string, dest any) error {
+	loader, err := w.loader(name)
+	if err != nil {
+		return err
+	}
+
+	w.recordAccess(ctx, name, cohort, id, loader.ttl)
+
+	var entry warmEntry
+	if data, err := w.client.Get(ctx, w.valueKey(ctx, name, id)).Bytes(); err == nil && json.Unmarshal(data, &entry) == nil {
+		result := "hit"
+		if entry.Warmed {
+			result = "warm_hit"
+		}
+		warmLookups.WithLabelValues(name, result).Inc()
+		return json.Unmarshal(entry.Value, dest)
+	}
+
+	warmLookups.WithLabelValues(name, "miss").Inc()
+	value, err := w.store(ctx, name, id, loader, false)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(value, dest)
+}
+
+func (w *redisWarmer) Refresh(ctx context.Context, name, id string) error {
+	loader, err := w.loader(name)
+	if err != nil {
+		return err
+	}
+
+	exists, err := w.client.Exists(ctx, w.valueKey(ctx, name, id)).Result()
+	if err != nil || exists == 0 {
+		return err
+	}
+
+	if _, err := w.store(ctx, name, id, loader, true); err != nil {
+		return err
+	}
+	warmRefreshes.WithLabelValues(name, "change").Inc()
+	return nil
+}
+
+func (w *redisWarmer) WarmExpiring(ctx context.Context) (int, error) {
+	w.mu.RLock()
+	loaders := make(map[string]warmedLoader, len(w.loaders))
+	for name, loader := range w.loaders {
+		loaders[name] = loader
+	}
+	w.mu.RUnlock()
+
+	warmed := 0
+	for name, loader := range loaders {
+		cohorts, err := w.client.SMembers(ctx, w.cohortsKey(ctx, name)).Result()
+		if err != nil {
+			return warmed, err
+		}
+
+		for _, cohort := range cohorts {
+			ids, err := w.client.ZRevRange(ctx, w.accessKey(ctx, name, cohort), 0, int64(w.config.TopN)-1).Result()
+			if err != nil {
+				return warmed, err
+			}
+
+			for _, id := range ids {
+				if err := ctx.Err(); err != nil {
+					return warmed, err
+				}
+
+				// Missing keys report a negative ttl and are warmed too
+				ttl, err := w.client.TTL(ctx, w.valueKey(ctx, name, id)).Result()
+				if err != nil {
+					return warmed, err
+				}
+				if ttl > w.config.Lead {
+					continue
+				}
+
+				if _, err := w.store(ctx, name, id, loader, true); err != nil {
+					return warmed, fmt.Errorf("failed to warm %s %s: %w", name, id, err)
+				}
+				warmRefreshes.WithLabelValues(name, "schedule").Inc()
+				warmed++
+			}
+		}
+	}
+	return warmed, nil
+}
+
+func (w *redisWarmer) loader(name string) (warmedLoader, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	loader, exists := w.loaders[name]
+	if !exists {
+		return warmedLoader{}, fmt.Errorf("no cache loader registered for %s", name)
+	}
+	return loader, nil
+}
+
+// store loads id and caches it. A failed write still returns the value.
+func (w *redisWarmer) store(ctx context.Context, name, id string, loader warmedLoader, warmed bool) (json.RawMessage, error) {
+	value, err := loader.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s %s: %w", name, id, err)
+	}
+
+	entry, err := json.Marshal(warmEntry{Warmed: warmed, Value: encoded})
+	if err != nil {
+		return nil, err
+	}
+	w.client.Set(ctx, w.valueKey(ctx, name, id), entry, loader.ttl)
+
+	return encoded, nil
+}
+
+// recordAccess counts a read of id. Cohorts that stop being read expire
+// with their counters, and each cohort only keeps its hottest ids.
+func (w *redisWarmer) recordAccess(ctx context.Context, name, cohort, id string, ttl time.Duration) {
+	accessKey := w.accessKey(ctx, name, cohort)
+	retention := 24 * time.Hour
+	if ttl*4 > retention {
+		retention = ttl * 4
+	}
+
+	pipe := w.client.Pipeline()
+	pipe.ZIncrBy(ctx, accessKey, 1, id)
+	pipe.ZRemRangeByRank(ctx, accessKey, 0, -int64(w.config.TopN*4)-1)
+	pipe.Expire(ctx, accessKey, retention)
+	pipe.SAdd(ctx, w.cohortsKey(ctx, name), cohort)
+	pipe.Expire(ctx, w.cohortsKey(ctx, name), retention)
+	pipe.Exec(ctx)
+}
+
+func (w *redisWarmer) valueKey(ctx context.Context, name, id string) string {
+	return fmt.Sprintf("%s:%s:%s", tenancy.Key(ctx, warmKeyPrefix), name, id)
+}
+
+func (w *redisWarmer) accessKey(ctx context.Context, name, cohort string) string {
+	return fmt.Sprintf("%s:access:%s:%s", tenancy.Key(ctx, warmKeyPrefix), name, cohort)
+}
+
+func (w *redisWarmer) cohortsKey(ctx context.Context, name string) string {
+	return fmt.Sprintf("%s:cohorts:%s", tenancy.Key(ctx, warmKeyPrefix), name)
+}
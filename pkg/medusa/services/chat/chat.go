@@ -0,0 +1,207 @@
+// Package chat posts alerts to Slack and Discord incoming webhooks
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/imlargo/go-api/pkg/medusa/core/ratelimiter"
+)
+
+type Provider string
+
+const (
+	ProviderSlack   Provider = "slack"
+	ProviderDiscord Provider = "discord"
+)
+
+func (p Provider) IsValid() bool {
+	return p == ProviderSlack || p == ProviderDiscord
+}
+
+type Level string
+
+const (
+	LevelInfo     Level = "info"
+	LevelWarning  Level = "warning"
+	LevelCritical Level = "critical"
+)
+
+// discordDescriptionLimit is the longest embed description Discord accepts
+const discordDescriptionLimit = 4096
+
+var (
+	ErrInvalidWebhookURL = apperrors.Validation("webhook url is not a slack or discord incoming webhook").WithCode("INVALID_WEBHOOK_URL")
+	ErrRateLimited       = apperrors.RateLimited("destination rate limit exceeded, message dropped").WithCode("CHAT_RATE_LIMITED")
+)
+
+// webhookHosts are the hosts each provider serves incoming webhooks from.
+// Only these are accepted so a destination can't be used to reach internal
+// services.
+var webhookHosts = map[Provider][]string{
+	ProviderSlack:   {"hooks.slack.com"},
+	ProviderDiscord: {"discord.com", "discordapp.com"},
+}
+
+var levelColors = map[Level]int{
+	LevelInfo:     0x2f80ed,
+	LevelWarning:  0xf2c94c,
+	LevelCritical: 0xeb5757,
+}
+
+// Field is a short key/value shown next to the message
+type Field struct {
+	Name  string
+	Value string
+}
+
+type Message struct {
+	Title  string
+	Text   string
+	Level  Level
+	Fields []Field
+}
+
+// Destination is a webhook messages are posted to. Key identifies it for
+// rate limiting.
+type Destination struct {
+	Key      string
+	Provider Provider
+	URL      string
+}
+
+type Notifier interface {
+	Send(ctx context.Context, destination Destination, message *Message) error
+}
+
+type webhookNotifier struct {
+	client  *http.Client
+	limiter ratelimiter.RateLimiter
+}
+
+// NewWebhookNotifier posts messages to incoming webhooks, at most
+// limit.RequestsPerTimeFrame per destination in each limit.TimeFrame
+func NewWebhookNotifier(limit ratelimiter.Config) Notifier {
+	return &webhookNotifier{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		limiter: ratelimiter.NewTokenBucketLimiter(limit),
+	}
+}
+
+// ValidateWebhookURL checks url is an https incoming webhook of provider
+func ValidateWebhookURL(provider Provider, webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil {
+		return ErrInvalidWebhookURL
+	}
+
+	for _, host := range webhookHosts[provider] {
+		if parsed.Host != host {
+			continue
+		}
+		if provider == ProviderDiscord && !strings.HasPrefix(parsed.Path, "/api/webhooks/") {
+			return ErrInvalidWebhookURL
+		}
+		return nil
+	}
+	return ErrInvalidWebhookURL
+}
+
+func (n *webhookNotifier) Send(ctx context.Context, destination Destination, message *Message) error {
+	if err := ValidateWebhookURL(destination.Provider, destination.URL); err != nil {
+		return err
+	}
+	if allowed, _ := n.limiter.Allow(destination.Key); !allowed {
+		return ErrRateLimited
+	}
+
+	var payload any
+	switch destination.Provider {
+	case ProviderSlack:
+		payload = slackPayload(message)
+	case ProviderDiscord:
+		payload = discordPayload(message)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, destination.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s webhook: %w", destination.Provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook returned %d: %s", destination.Provider, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// slackEscaper escapes the characters Slack reads as markup, so values can't
+// inject links or mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func slackPayload(message *Message) map[string]any {
+	fields := make([]map[string]any, 0, len(message.Fields))
+	for _, field := range message.Fields {
+		fields = append(fields, map[string]any{
+			"title": slackEscaper.Replace(field.Name),
+			"value": slackEscaper.Replace(field.Value),
+			"short": true,
+		})
+	}
+
+	return map[string]any{
+		"text": "*" + slackEscaper.Replace(message.Title) + "*",
+		"attachments": []map[string]any{{
+			"color":  fmt.Sprintf("#%06x", levelColors[message.Level]),
+			"text":   slackEscaper.Replace(message.Text),
+			"fields": fields,
+		}},
+	}
+}
+
+func discordPayload(message *Message) map[string]any {
+	fields := make([]map[string]any, 0, len(message.Fields))
+	for _, field := range message.Fields {
+		fields = append(fields, map[string]any{
+			"name":   field.Name,
+			"value":  field.Value,
+			"inline": true,
+		})
+	}
+
+	text := message.Text
+	if len(text) > discordDescriptionLimit {
+		text = text[:discordDescriptionLimit]
+	}
+
+	return map[string]any{
+		"embeds": []map[string]any{{
+			"title":       message.Title,
+			"description": text,
+			"color":       levelColors[message.Level],
+			"fields":      fields,
+		}},
+		// Never ping @everyone or roles from alert content
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
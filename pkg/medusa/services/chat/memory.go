@@ -0,0 +1,42 @@
+package chat
+
+import (
+	"context"
+	"sync"
+)
+
+// SentMessage is a message kept by a memory notifier
+type SentMessage struct {
+	Destination Destination
+	Message     Message
+}
+
+// MemoryNotifier keeps messages in memory instead of posting them. Webhook
+// URLs are still validated so misconfigured channels fail as they would.
+type MemoryNotifier struct {
+	mu   sync.Mutex
+	sent []SentMessage
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (n *MemoryNotifier) Send(ctx context.Context, destination Destination, message *Message) error {
+	if err := ValidateWebhookURL(destination.Provider, destination.URL); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.sent = append(n.sent, SentMessage{Destination: destination, Message: *message})
+	n.mu.Unlock()
+	return nil
+}
+
+// Sent returns a copy of the messages sent so far
+func (n *MemoryNotifier) Sent() []SentMessage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]SentMessage(nil), n.sent...)
+}
@@ -0,0 +1,117 @@
+package degrade
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a provider circuit
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// breaker is a circuit breaker that does not hold its lock while the
+// guarded call runs, so slow providers don't serialize callers
+type breaker struct {
+	mu          sync.Mutex
+	config      Config
+	state       CircuitState
+	failures    int
+	probing     bool
+	openedAt    time.Time
+	lastFailure time.Time
+	lastSuccess time.Time
+	lastError   string
+}
+
+func newBreaker(config Config) *breaker {
+	return &breaker{
+		config: config,
+		state:  CircuitClosed,
+	}
+}
+
+// allow reports whether a call may go through. In half-open state a single
+// probe call is allowed at a time.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.config.ResetTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success records a successful call and reports whether it closed the circuit
+func (b *breaker) success() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recovered := b.state != CircuitClosed
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+	b.lastSuccess = time.Now()
+	b.lastError = ""
+
+	return recovered
+}
+
+func (b *breaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.lastFailure = time.Now()
+	b.lastError = err.Error()
+	b.probing = false
+
+	if b.state == CircuitHalfOpen || b.failures >= b.config.MaxFailures {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *breaker) snapshot() ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == CircuitOpen && time.Since(b.openedAt) >= b.config.ResetTimeout {
+		state = CircuitHalfOpen
+	}
+
+	health := ProviderHealth{
+		State:     state,
+		Failures:  b.failures,
+		LastError: b.lastError,
+	}
+	if !b.lastFailure.IsZero() {
+		lastFailure := b.lastFailure
+		health.LastFailure = &lastFailure
+	}
+	if !b.lastSuccess.IsZero() {
+		lastSuccess := b.lastSuccess
+		health.LastSuccess = &lastSuccess
+	}
+
+	return health
+}
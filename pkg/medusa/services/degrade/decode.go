@@ -0,0 +1,25 @@
+package degrade
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// decodeInto copies value into dest through JSON, the same representation
+// used for last known values in the cache
+func decodeInto(value any, dest any) error {
+	if dest == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal provider value: %w", err)
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal provider value: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package degrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/services/cache"
+)
+
+const lastKnownKeyPrefix = "degrade:last_known"
+
+var ErrNoFallback = errors.New("provider unavailable and no last known value")
+
+// Config controls when a provider circuit opens and how long last known
+// values are kept
+type Config struct {
+	MaxFailures  int
+	ResetTimeout time.Duration
+	LastKnownTTL time.Duration
+}
+
+// DefaultConfig returns default degradation configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxFailures:  5,
+		ResetTimeout: time.Minute,
+		LastKnownTTL: 7 * 24 * time.Hour,
+	}
+}
+
+// Freshness describes where a value came from. It is meant to be embedded
+// in API responses so clients can show a stale data banner.
+type Freshness struct {
+	Stale        bool         `json:"stale"`
+	FetchedAt    time.Time    `json:"fetched_at"`
+	Provider     string       `json:"provider"`
+	CircuitState CircuitState `json:"circuit_state"`
+}
+
+// ProviderHealth is the circuit status of a provider
+type ProviderHealth struct {
+	State            CircuitState `json:"state"`
+	Failures         int          `json:"failures"`
+	LastError        string       `json:"last_error,omitempty"`
+	LastFailure      *time.Time   `json:"last_failure,omitempty"`
+	LastSuccess      *time.Time   `json:"last_success,omitempty"`
+	PendingRefreshes int          `json:"pending_refreshes"`
+}
+
+type lastKnown struct {
+	Value     any       `json:"value"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Guard wraps calls to external providers. Successful results are kept as
+// the last known value; when a provider fails or its circuit is open the
+// last known value is served instead and a refresh is queued for when the
+// provider recovers.
+type Guard struct {
+	cache    cache.Service
+	config   Config
+	mu       sync.Mutex
+	breakers map[string]*breaker
+	pending  map[string]map[string]func() // provider -> key -> refresh
+}
+
+func NewGuard(cache cache.Service, config Config) *Guard {
+	return &Guard{
+		cache:    cache,
+		config:   config,
+		breakers: make(map[string]*breaker),
+		pending:  make(map[string]map[string]func()),
+	}
+}
+
+// Fetch calls fn through the provider circuit and decodes the result, or
+// the last known value on failure, into dest
+func (g *Guard) Fetch(ctx context.Context, provider string, key string, dest any, fn func(ctx context.Context) (any, error)) (*Freshness, error) {
+	b := g.breaker(provider)
+	cacheKey := fmt.Sprintf("%s:%s:%s", lastKnownKeyPrefix, provider, key)
+
+	var callErr error
+	if b.allow() {
+		value, err := fn(ctx)
+		if err == nil {
+			if recovered := b.success(); recovered {
+				go g.drain(provider)
+			}
+
+			entry := lastKnown{Value: value, FetchedAt: time.Now()}
+			_ = g.cache.Set(ctx, cacheKey, entry, g.config.LastKnownTTL)
+
+			if err := decodeInto(value, dest); err != nil {
+				return nil, err
+			}
+
+			return &Freshness{
+				FetchedAt:    entry.FetchedAt,
+				Provider:     provider,
+				CircuitState: CircuitClosed,
+			}, nil
+		}
+
+		b.failure(err)
+		callErr = err
+	}
+
+	g.queueRefresh(provider, key, func() {
+		var discard any
+		_, _ = g.Fetch(context.Background(), provider, key, &discard, fn)
+	})
+
+	var entry lastKnown
+	if err := g.cache.Get(ctx, cacheKey, &entry); err != nil {
+		if callErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNoFallback, callErr)
+		}
+		return nil, ErrNoFallback
+	}
+
+	if err := decodeInto(entry.Value, dest); err != nil {
+		return nil, err
+	}
+
+	return &Freshness{
+		Stale:        true,
+		FetchedAt:    entry.FetchedAt,
+		Provider:     provider,
+		CircuitState: b.snapshot().State,
+	}, nil
+}
+
+// Health returns the circuit status of every provider seen so far
+func (g *Guard) Health() map[string]ProviderHealth {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	health := make(map[string]ProviderHealth, len(g.breakers))
+	for provider, b := range g.breakers {
+		snapshot := b.snapshot()
+		snapshot.PendingRefreshes = len(g.pending[provider])
+		health[provider] = snapshot
+	}
+	return health
+}
+
+// Check fails while any provider circuit is open. It matches
+// health.Checker.
+func (g *Guard) Check(ctx context.Context) error {
+	var open []string
+	for provider, health := range g.Health() {
+		if health.State == CircuitOpen {
+			open = append(open, provider)
+		}
+	}
+	if len(open) == 0 {
+		return nil
+	}
+
+	sort.Strings(open)
+	return fmt.Errorf("circuit open for %s", strings.Join(open, ", "))
+}
+
+// State returns the circuit state of a provider
+func (g *Guard) State(provider string) CircuitState {
+	return g.breaker(provider).snapshot().State
+}
+
+func (g *Guard) breaker(provider string) *breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.breakers[provider]
+	if !ok {
+		b = newBreaker(g.config)
+		g.breakers[provider] = b
+	}
+	return b
+}
+
+func (g *Guard) queueRefresh(provider, key string, refresh func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.pending[provider] == nil {
+		g.pending[provider] = make(map[string]func())
+	}
+	g.pending[provider][key] = refresh
+}
+
+// drain runs the refreshes queued while the provider was down
+func (g *Guard) drain(provider string) {
+	g.mu.Lock()
+	refreshes := g.pending[provider]
+	delete(g.pending, provider)
+	g.mu.Unlock()
+
+	for _, refresh := range refreshes {
+		refresh()
+	}
+}
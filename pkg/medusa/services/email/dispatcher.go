@@ -0,0 +1,279 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrQueueFull        = errors.New("email queue is full")
+	ErrDispatcherClosed = errors.New("email dispatcher is closed")
+)
+
+// Priority orders the queue. Transactional emails are sent before any
+// waiting digest.
+type Priority int
+
+const (
+	PriorityTransactional Priority = iota
+	PriorityDigest
+)
+
+func (p Priority) String() string {
+	if p == PriorityDigest {
+		return "digest"
+	}
+	return "transactional"
+}
+
+var (
+	emailsQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "email_queue_depth",
+		Help: "Emails waiting to be sent",
+	}, []string{"priority"})
+
+	emailsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "email_sent_total",
+		Help: "Emails handed to the provider, by outcome",
+	}, []string{"priority", "status"})
+
+	emailLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "email_send_latency_seconds",
+		Help:    "Time from queueing an email to the provider accepting it",
+		Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"priority"})
+)
+
+type DispatcherConfig struct {
+	Workers   int
+	QueueSize int
+	// DomainRate is the emails per second sent to each recipient domain,
+	// with bursts of DomainBurst. Mailbox providers throttle senders that
+	// flood them.
+	DomainRate  float64
+	DomainBurst int
+	// MaxAttempts bounds the sends of an email the provider rate limits.
+	// Retries wait RetryBackoff, doubled after each attempt.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		Workers:      4,
+		QueueSize:    1000,
+		DomainRate:   2,
+		DomainBurst:  5,
+		MaxAttempts:  5,
+		RetryBackoff: time.Second,
+	}
+}
+
+type queuedEmail struct {
+	params     *SendEmailParams
+	priority   Priority
+	enqueuedAt time.Time
+}
+
+// Dispatcher sends emails from a pool of workers so callers don't wait on
+// the provider. It is an EmailService itself: SendEmail queues the email as
+// transactional and returns once it is queued, with an empty ID.
+type Dispatcher struct {
+	sender EmailService
+	config DispatcherConfig
+	logger *logger.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queues [2]chan *queuedEmail
+
+	domainsMu sync.Mutex
+	domains   map[string]*rate.Limiter
+
+	// ctx is cancelled when Close runs out of time, aborting throttle waits
+	// and retries
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher starts the workers sending through sender
+func NewDispatcher(sender EmailService, config DispatcherConfig, logger *logger.Logger) *Dispatcher {
+	d := &Dispatcher{
+		sender:  sender,
+		config:  config,
+		logger:  logger,
+		domains: make(map[string]*rate.Limiter),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan *queuedEmail, config.QueueSize)
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	for i := 0; i < config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+func (d *Dispatcher) SendEmail(params *SendEmailParams) (*SendEmailResponse, error) {
+	if err := d.Enqueue(PriorityTransactional, params); err != nil {
+		return nil, err
+	}
+	return &SendEmailResponse{}, nil
+}
+
+// Enqueue queues an email without blocking. It fails with ErrQueueFull
+// when the queue of priority is at capacity.
+func (d *Dispatcher) Enqueue(priority Priority, params *SendEmailParams) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	select {
+	case d.queues[priority] <- &queuedEmail{params: params, priority: priority, enqueuedAt: time.Now()}:
+		emailsQueued.WithLabelValues(priority.String()).Inc()
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting emails and waits for the queued ones to be sent.
+// Emails still queued when ctx ends are dropped.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	transactional, digest := d.queues[PriorityTransactional], d.queues[PriorityDigest]
+	for transactional != nil || digest != nil {
+		// A waiting transactional email always goes first
+		select {
+		case queued, ok := <-transactional:
+			if !ok {
+				transactional = nil
+				continue
+			}
+			d.deliver(queued)
+			continue
+		default:
+		}
+
+		select {
+		case queued, ok := <-transactional:
+			if !ok {
+				transactional = nil
+				continue
+			}
+			d.deliver(queued)
+		case queued, ok := <-digest:
+			if !ok {
+				digest = nil
+				continue
+			}
+			d.deliver(queued)
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(queued *queuedEmail) {
+	priority := queued.priority.String()
+	emailsQueued.WithLabelValues(priority).Dec()
+
+	limiter := d.domainLimiter(recipientDomain(queued.params))
+	for attempt := 1; ; attempt++ {
+		if err := limiter.Wait(d.ctx); err != nil {
+			d.fail(queued, err)
+			return
+		}
+
+		_, err := d.sender.SendEmail(queued.params)
+		if err == nil {
+			emailsSent.WithLabelValues(priority, "sent").Inc()
+			emailLatency.WithLabelValues(priority).Observe(time.Since(queued.enqueuedAt).Seconds())
+			return
+		}
+		if !errors.Is(err, ErrRateLimited) || attempt >= d.config.MaxAttempts {
+			d.fail(queued, err)
+			return
+		}
+
+		emailsSent.WithLabelValues(priority, "retried").Inc()
+		select {
+		case <-d.ctx.Done():
+			d.fail(queued, d.ctx.Err())
+			return
+		case <-time.After(d.config.RetryBackoff << (attempt - 1)):
+		}
+	}
+}
+
+func (d *Dispatcher) fail(queued *queuedEmail, err error) {
+	emailsSent.WithLabelValues(queued.priority.String(), "failed").Inc()
+	d.logger.Warn("email not delivered",
+		zap.String("priority", queued.priority.String()),
+		zap.Strings("to", queued.params.To),
+		zap.String("subject", queued.params.Subject),
+		zap.Error(err),
+	)
+}
+
+func (d *Dispatcher) domainLimiter(domain string) *rate.Limiter {
+	d.domainsMu.Lock()
+	defer d.domainsMu.Unlock()
+
+	limiter, exists := d.domains[domain]
+	if !exists {
+		limiter = rate.NewLimiter(rate.Limit(d.config.DomainRate), d.config.DomainBurst)
+		d.domains[domain] = limiter
+	}
+	return limiter
+}
+
+// recipientDomain throttles by the first recipient, the one an email is
+// addressed to
+func recipientDomain(params *SendEmailParams) string {
+	if len(params.To) == 0 {
+		return ""
+	}
+	_, domain, _ := strings.Cut(params.To[0], "@")
+	return strings.ToLower(strings.TrimSuffix(domain, ">"))
+}
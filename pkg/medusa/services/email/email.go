@@ -1,5 +1,11 @@
 package email
 
+import "errors"
+
+// ErrRateLimited is returned by senders when the provider rejects an email
+// for exceeding its rate limit, so callers can retry later
+var ErrRateLimited = errors.New("email provider rate limit exceeded")
+
 type SendEmailParams struct {
 	From    string
 	To      []string
@@ -0,0 +1,35 @@
+package mock
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/imlargo/go-api/pkg/medusa/services/email"
+)
+
+// EmailClient keeps sent emails in memory instead of delivering them. IDs
+// follow the order emails were sent in, mock_email_000001 first.
+type EmailClient struct {
+	mu   sync.Mutex
+	sent []email.SendEmailParams
+}
+
+func NewEmailClient() *EmailClient {
+	return &EmailClient{}
+}
+
+func (e *EmailClient) SendEmail(params *email.SendEmailParams) (*email.SendEmailResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sent = append(e.sent, *params)
+	return &email.SendEmailResponse{ID: fmt.Sprintf("mock_email_%06d", len(e.sent))}, nil
+}
+
+// Sent returns a copy of the emails sent so far
+func (e *EmailClient) Sent() []email.SendEmailParams {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]email.SendEmailParams(nil), e.sent...)
+}
@@ -1,6 +1,9 @@
 package email
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/imlargo/go-api/pkg/medusa/services/email"
 	"github.com/resend/resend-go/v2"
 )
@@ -39,6 +42,9 @@ func (e *resendEmailClient) SendEmail(params *email.SendEmailParams) (*email.Sen
 	}
 
 	sent, err := e.client.Emails.Send(sendParams)
+	if errors.Is(err, resend.ErrRateLimit) {
+		return nil, fmt.Errorf("%w: %v", email.ErrRateLimited, err)
+	}
 	if err != nil {
 		return nil, err
 	}
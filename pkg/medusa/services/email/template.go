@@ -0,0 +1,29 @@
+package email
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/sanitize"
+)
+
+// templateFuncs are available in every email template. User-provided rich
+// text must go through richtext; html/template escapes everything else.
+var templateFuncs = template.FuncMap{
+	"richtext":  sanitize.RichTextHTML,
+	"plaintext": sanitize.PlainText,
+}
+
+// NewTemplate parses an HTML email template with the sanitization funcs
+func NewTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// RenderHTML executes tmpl into the Html body of an email
+func RenderHTML(tmpl *template.Template, data any) (string, error) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
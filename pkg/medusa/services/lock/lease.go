@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Lease is a held lock. Only the lease that acquired a lock can renew or
+// release it.
+type Lease struct {
+	manager *manager
+	name    string
+	token   string
+
+	// mu guards holder, which the KeepAlive goroutine updates on renewal
+	mu     sync.Mutex
+	holder Holder
+}
+
+func (l *Lease) Name() string {
+	return l.name
+}
+
+func (l *Lease) Holder() Holder {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder
+}
+
+// Renew extends the lease by LeaseTTL. It returns ErrLeaseLost when the
+// lease already expired or was force-released.
+func (l *Lease) Renew(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	renewed, err := renewScript.Run(ctx, l.manager.client, []string{keyPrefix + l.name},
+		l.token, now.Format(time.RFC3339Nano), l.manager.config.LeaseTTL.Milliseconds(),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("failed to renew lock %s: %w", l.name, err)
+	}
+
+	if renewed == 0 {
+		l.manager.record(l.name, func(s *Stats) { s.LeasesLost++ })
+		return ErrLeaseLost
+	}
+
+	l.mu.Lock()
+	l.holder.RenewedAt = now
+	l.mu.Unlock()
+	return nil
+}
+
+// Release frees the lock if this lease still holds it
+func (l *Lease) Release(ctx context.Context) error {
+	_, err := releaseScript.Run(ctx, l.manager.client, []string{keyPrefix + l.name}, l.token).Result()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.name, err)
+	}
+	return nil
+}
+
+// KeepAlive renews the lease every third of its TTL until ctx is done.
+// The returned context is cancelled as soon as the lease is lost, or when
+// renewals kept failing for a whole TTL, so work running under the lock
+// can stop before another holder takes over.
+func (l *Lease) KeepAlive(ctx context.Context) context.Context {
+	leaseCtx, cancel := context.WithCancel(ctx)
+	interval := l.manager.config.LeaseTTL / 3
+
+	go func() {
+		defer cancel()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+				err := l.Renew(leaseCtx)
+				if errors.Is(err, ErrLeaseLost) {
+					return
+				}
+				if err != nil && time.Since(l.Holder().RenewedAt) >= l.manager.config.LeaseTTL {
+					return
+				}
+			}
+		}
+	}()
+
+	return leaseCtx
+}
@@ -0,0 +1,238 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "lock:"
+
+var (
+	ErrLockHeld     = errors.New("lock is held by another holder")
+	ErrLockNotFound = apperrors.NotFound("lock").WithCode("LOCK_NOT_FOUND")
+	ErrLeaseLost    = errors.New("lock lease was lost")
+)
+
+// Holder identifies who owns a lock
+type Holder struct {
+	TaskID     string    `json:"task_id,omitempty"`
+	WorkerID   string    `json:"worker_id"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+}
+
+// LockInfo describes a held lock
+type LockInfo struct {
+	Name   string        `json:"name"`
+	Holder Holder        `json:"holder"`
+	TTL    time.Duration `json:"ttl"`
+}
+
+// Stats are the contention counters of a lock kind, the part of the lock
+// name before the first colon
+type Stats struct {
+	Acquired      int64 `json:"acquired"`
+	Contended     int64 `json:"contended"`
+	LeasesLost    int64 `json:"leases_lost"`
+	ForceReleased int64 `json:"force_released"`
+}
+
+type Config struct {
+	// LeaseTTL is how long a lock survives without a renewal. Holders that
+	// crash stop renewing, so their lock expires on its own.
+	LeaseTTL time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{LeaseTTL: 30 * time.Second}
+}
+
+type Manager interface {
+	Acquire(ctx context.Context, name string, holder Holder) (*Lease, error)
+	Inspect(ctx context.Context, name string) (*LockInfo, error)
+	List(ctx context.Context) ([]*LockInfo, error)
+	ForceRelease(ctx context.Context, name string) (bool, error)
+	Stats() map[string]Stats
+}
+
+type manager struct {
+	client *redis.Client
+	config Config
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+func NewManager(client *redis.Client, config Config) Manager {
+	return &manager{
+		client: client,
+		config: config,
+		stats:  make(map[string]*Stats),
+	}
+}
+
+var acquireScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], "token", ARGV[1], "task_id", ARGV[2], "worker_id", ARGV[3], "acquired_at", ARGV[4], "renewed_at", ARGV[4])
+redis.call("PEXPIRE", KEYS[1], ARGV[5])
+return 1
+`)
+
+var renewScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "token") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "renewed_at", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return 1
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "token") ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+// Acquire takes the lock for holder, or returns ErrLockHeld. The returned
+// lease expires after LeaseTTL unless it is renewed.
+func (m *manager) Acquire(ctx context.Context, name string, holder Holder) (*Lease, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	holder.AcquiredAt = now
+	holder.RenewedAt = now
+
+	acquired, err := acquireScript.Run(ctx, m.client, []string{keyPrefix + name},
+		token, holder.TaskID, holder.WorkerID, now.Format(time.RFC3339Nano), m.config.LeaseTTL.Milliseconds(),
+	).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+
+	if acquired == 0 {
+		m.record(name, func(s *Stats) { s.Contended++ })
+		return nil, ErrLockHeld
+	}
+
+	m.record(name, func(s *Stats) { s.Acquired++ })
+	return &Lease{
+		manager: m,
+		name:    name,
+		token:   token,
+		holder:  holder,
+	}, nil
+}
+
+func (m *manager) Inspect(ctx context.Context, name string) (*LockInfo, error) {
+	key := keyPrefix + name
+
+	pipe := m.client.Pipeline()
+	fields := pipe.HGetAll(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to inspect lock %s: %w", name, err)
+	}
+
+	if len(fields.Val()) == 0 {
+		return nil, ErrLockNotFound
+	}
+
+	return &LockInfo{
+		Name:   name,
+		Holder: parseHolder(fields.Val()),
+		TTL:    ttl.Val(),
+	}, nil
+}
+
+func (m *manager) List(ctx context.Context) ([]*LockInfo, error) {
+	locks := []*LockInfo{}
+
+	iter := m.client.Scan(ctx, 0, keyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		info, err := m.Inspect(ctx, strings.TrimPrefix(iter.Val(), keyPrefix))
+		if errors.Is(err, ErrLockNotFound) {
+			continue // Expired between the scan and the read
+		}
+		if err != nil {
+			return nil, err
+		}
+		locks = append(locks, info)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list locks: %w", err)
+	}
+
+	return locks, nil
+}
+
+// ForceRelease deletes a lock regardless of its holder. The holder notices
+// on its next renewal and stops working under the lock.
+func (m *manager) ForceRelease(ctx context.Context, name string) (bool, error) {
+	deleted, err := m.client.Del(ctx, keyPrefix+name).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to release lock %s: %w", name, err)
+	}
+
+	if deleted > 0 {
+		m.record(name, func(s *Stats) { s.ForceReleased++ })
+	}
+	return deleted > 0, nil
+}
+
+func (m *manager) Stats() map[string]Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]Stats, len(m.stats))
+	for kind, s := range m.stats {
+		stats[kind] = *s
+	}
+	return stats
+}
+
+func (m *manager) record(name string, update func(s *Stats)) {
+	kind, _, _ := strings.Cut(name, ":")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stats[kind]
+	if !ok {
+		s = &Stats{}
+		m.stats[kind] = s
+	}
+	update(s)
+}
+
+func parseHolder(fields map[string]string) Holder {
+	holder := Holder{
+		TaskID:   fields["task_id"],
+		WorkerID: fields["worker_id"],
+	}
+	holder.AcquiredAt, _ = time.Parse(time.RFC3339Nano, fields["acquired_at"])
+	holder.RenewedAt, _ = time.Parse(time.RFC3339Nano, fields["renewed_at"])
+	return holder
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
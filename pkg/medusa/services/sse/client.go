@@ -2,13 +2,18 @@ package sse
 
 import (
 	"context"
+	"sync"
 	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/changes"
 )
 
 type Connection interface {
 	GetChannel() <-chan *Message
 	GetContext() context.Context
 	UpdateLastSeen()
+	Replay() []*Message
+	Accept(message *Message) bool
 }
 
 type clientConn struct {
@@ -18,6 +23,13 @@ type clientConn struct {
 	Context  context.Context
 	Cancel   context.CancelFunc
 	LastSeen time.Time
+
+	replay        []*Message
+	mu            sync.Mutex
+	lastDelivered string
+
+	// entities filters the change events, nil accepts every event
+	entities map[string]bool
 }
 
 func (c *clientConn) GetChannel() <-chan *Message {
@@ -31,3 +43,33 @@ func (c *clientConn) GetContext() context.Context {
 func (c *clientConn) UpdateLastSeen() {
 	c.LastSeen = time.Now()
 }
+
+// Replay returns the messages missed since the Last-Event-ID the client
+// reconnected with. They must be written before any live message.
+func (c *clientConn) Replay() []*Message {
+	return c.replay
+}
+
+// Accept reports whether a message should be written to the client. Live
+// messages that were already replayed are skipped, so a message published
+// while the client was reconnecting is delivered exactly once.
+func (c *clientConn) Accept(message *Message) bool {
+	if c.entities != nil {
+		if entity := changes.EntityOf(message.Event); entity != "" && !c.entities[entity] {
+			return false
+		}
+	}
+
+	if message.ID == "" {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lastDelivered != "" && !eventIDAfter(message.ID, c.lastDelivered) {
+		return false
+	}
+	c.lastDelivered = message.ID
+	return true
+}
@@ -0,0 +1,32 @@
+package sse
+
+import "time"
+
+type Config struct {
+	// BufferSize is the number of messages queued per connection. A client
+	// that lets its queue fill up is disconnected and has to resume with
+	// Last-Event-ID.
+	BufferSize        int
+	HeartbeatInterval time.Duration
+	IdleTimeout       time.Duration
+	ReplayLimit       int
+
+	// MaxConnectionsPerUser and MaxConnections cap the open connections of
+	// a user and of the whole server. Reconnecting with the same client ID
+	// replaces the old connection and doesn't count twice. 0 disables a
+	// limit.
+	MaxConnectionsPerUser int
+	MaxConnections        int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BufferSize:        100,
+		HeartbeatInterval: 15 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ReplayLimit:       500,
+
+		MaxConnectionsPerUser: 10,
+		MaxConnections:        10000,
+	}
+}
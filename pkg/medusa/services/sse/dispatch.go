@@ -0,0 +1,285 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/apperrors"
+	"github.com/redis/go-redis/v9"
+)
+
+const dispatchKeyPrefix = "sse:dispatch:"
+
+var ErrDispatchNotFound = apperrors.NotFound("dispatch").WithCode("DISPATCH_NOT_FOUND")
+
+type DispatchStatus string
+
+const (
+	DispatchStatusRunning   DispatchStatus = "running"
+	DispatchStatusCompleted DispatchStatus = "completed"
+)
+
+// BulkMessage is sent to every recipient of a dispatch. String values in
+// Data are text/template templates rendered with the recipient's variables,
+// e.g. "Hi {{.name}}". Recipients without variables get Defaults.
+type BulkMessage struct {
+	Event    string
+	Data     map[string]any
+	Vars     map[uint]map[string]string
+	Defaults map[string]string
+}
+
+// Dispatch tracks the progress of a bulk send
+type Dispatch struct {
+	ID          string         `json:"id"`
+	Status      DispatchStatus `json:"status"`
+	Total       int            `json:"total"`
+	Sent        int            `json:"sent"`
+	Failed      int            `json:"failed"`
+	CreatedAt   time.Time      `json:"created_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+}
+
+// DeliveryResult is the outcome of a dispatch for one recipient
+type DeliveryResult struct {
+	UserID uint   `json:"user_id"`
+	Sent   bool   `json:"sent"`
+	Error  string `json:"error,omitempty"`
+}
+
+type DispatchConfig struct {
+	// BatchSize is how many recipients are sent to before yielding, so a
+	// large dispatch doesn't starve the regular publishers
+	BatchSize int
+	// Retention is how long progress and results are kept in Redis
+	Retention time.Duration
+}
+
+func DefaultDispatchConfig() DispatchConfig {
+	return DispatchConfig{
+		BatchSize: 100,
+		Retention: 7 * 24 * time.Hour,
+	}
+}
+
+type Dispatcher interface {
+	// Dispatch validates the templates and starts sending in the
+	// background. The returned dispatch can be polled with Get.
+	Dispatch(ctx context.Context, recipients []uint, message *BulkMessage) (*Dispatch, error)
+	Get(ctx context.Context, id string) (*Dispatch, error)
+	Results(ctx context.Context, id string) ([]DeliveryResult, error)
+}
+
+type dispatcher struct {
+	manager SSEManager
+	client  *redis.Client
+	config  DispatchConfig
+}
+
+func NewDispatcher(manager SSEManager, client *redis.Client, config DispatchConfig) Dispatcher {
+	return &dispatcher{
+		manager: manager,
+		client:  client,
+		config:  config,
+	}
+}
+
+func (d *dispatcher) Dispatch(ctx context.Context, recipients []uint, message *BulkMessage) (*Dispatch, error) {
+	templates, err := parseTemplates(message.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newDispatchID()
+	if err != nil {
+		return nil, err
+	}
+
+	recipients = uniqueRecipients(recipients)
+	dispatch := &Dispatch{
+		ID:        id,
+		Status:    DispatchStatusRunning,
+		Total:     len(recipients),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	key := dispatchKeyPrefix + id
+	pipe := d.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"status", string(dispatch.Status),
+		"total", dispatch.Total,
+		"sent", 0,
+		"failed", 0,
+		"created_at", dispatch.CreatedAt.Format(time.RFC3339Nano),
+	)
+	pipe.Expire(ctx, key, d.config.Retention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create dispatch: %w", err)
+	}
+
+	go d.run(context.Background(), dispatch, recipients, message, templates)
+
+	return dispatch, nil
+}
+
+func (d *dispatcher) run(ctx context.Context, dispatch *Dispatch, recipients []uint, message *BulkMessage, templates map[string]*template.Template) {
+	key := dispatchKeyPrefix + dispatch.ID
+	resultsKey := key + ":results"
+
+	for start := 0; start < len(recipients); start += d.config.BatchSize {
+		end := min(start+d.config.BatchSize, len(recipients))
+
+		results := make(map[string]any, end-start)
+		var sent, failed int64
+
+		for _, userID := range recipients[start:end] {
+			err := d.sendOne(userID, message, templates)
+
+			field := strconv.FormatUint(uint64(userID), 10)
+			if err != nil {
+				results[field] = err.Error()
+				failed++
+			} else {
+				results[field] = ""
+				sent++
+			}
+		}
+
+		// Progress is best effort, a Redis hiccup must not stop the sends
+		pipe := d.client.Pipeline()
+		pipe.HSet(ctx, resultsKey, results)
+		pipe.Expire(ctx, resultsKey, d.config.Retention)
+		pipe.HIncrBy(ctx, key, "sent", sent)
+		pipe.HIncrBy(ctx, key, "failed", failed)
+		pipe.Exec(ctx)
+	}
+
+	d.client.HSet(ctx, key,
+		"status", string(DispatchStatusCompleted),
+		"completed_at", time.Now().UTC().Format(time.RFC3339Nano),
+	)
+}
+
+func (d *dispatcher) sendOne(userID uint, message *BulkMessage, templates map[string]*template.Template) error {
+	vars, ok := message.Vars[userID]
+	if !ok {
+		vars = message.Defaults
+	}
+
+	data := make(map[string]any, len(message.Data))
+	for field, value := range message.Data {
+		tmpl, ok := templates[field]
+		if !ok {
+			data[field] = value
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return fmt.Errorf("failed to render %s: %w", field, err)
+		}
+		data[field] = buf.String()
+	}
+
+	return d.manager.Send(userID, &Message{Event: message.Event, Data: data})
+}
+
+func (d *dispatcher) Get(ctx context.Context, id string) (*Dispatch, error) {
+	fields, err := d.client.HGetAll(ctx, dispatchKeyPrefix+id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispatch %s: %w", id, err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrDispatchNotFound
+	}
+
+	dispatch := &Dispatch{
+		ID:     id,
+		Status: DispatchStatus(fields["status"]),
+	}
+	dispatch.Total, _ = strconv.Atoi(fields["total"])
+	dispatch.Sent, _ = strconv.Atoi(fields["sent"])
+	dispatch.Failed, _ = strconv.Atoi(fields["failed"])
+	dispatch.CreatedAt, _ = time.Parse(time.RFC3339Nano, fields["created_at"])
+	if completedAt, err := time.Parse(time.RFC3339Nano, fields["completed_at"]); err == nil {
+		dispatch.CompletedAt = &completedAt
+	}
+
+	return dispatch, nil
+}
+
+func (d *dispatcher) Results(ctx context.Context, id string) ([]DeliveryResult, error) {
+	if _, err := d.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	fields, err := d.client.HGetAll(ctx, dispatchKeyPrefix+id+":results").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispatch results %s: %w", id, err)
+	}
+
+	results := make([]DeliveryResult, 0, len(fields))
+	for field, sendErr := range fields {
+		userID, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		results = append(results, DeliveryResult{
+			UserID: uint(userID),
+			Sent:   sendErr == "",
+			Error:  sendErr,
+		})
+	}
+
+	return results, nil
+}
+
+// parseTemplates parses the string values of data that contain template
+// actions, failing the dispatch up front instead of once per recipient
+func parseTemplates(data map[string]any) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template)
+
+	for field, value := range data {
+		text, ok := value.(string)
+		if !ok || !strings.Contains(text, "{{") {
+			continue
+		}
+
+		tmpl, err := template.New(field).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template in %s: %w", field, err)
+		}
+		templates[field] = tmpl
+	}
+
+	return templates, nil
+}
+
+func uniqueRecipients(recipients []uint) []uint {
+	seen := make(map[uint]bool, len(recipients))
+	unique := make([]uint, 0, len(recipients))
+
+	for _, userID := range recipients {
+		if userID == 0 || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		unique = append(unique, userID)
+	}
+	return unique
+}
+
+func newDispatchID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate dispatch id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
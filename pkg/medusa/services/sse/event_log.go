@@ -0,0 +1,125 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventLog keeps the recent messages of every user so reconnecting clients
+// can replay what they missed
+type EventLog interface {
+	Append(ctx context.Context, userID uint, message *Message) (string, error)
+	Since(ctx context.Context, userID uint, lastEventID string, limit int) ([]*Message, error)
+}
+
+type redisEventLog struct {
+	client *redis.Client
+	maxLen int64
+	ttl    time.Duration
+}
+
+// NewRedisEventLog stores messages in a capped Redis stream per user. The
+// stream entry id is used as the SSE event id.
+func NewRedisEventLog(client *redis.Client, maxLen int64, ttl time.Duration) EventLog {
+	return &redisEventLog{
+		client: client,
+		maxLen: maxLen,
+		ttl:    ttl,
+	}
+}
+
+func (l *redisEventLog) Append(ctx context.Context, userID uint, message *Message) (string, error) {
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode message data: %w", err)
+	}
+
+	key := eventLogKey(userID)
+
+	pipe := l.client.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: l.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"event": message.Event,
+			"data":  string(data),
+		},
+	})
+	pipe.Expire(ctx, key, l.ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to append event: %w", err)
+	}
+
+	return add.Val(), nil
+}
+
+// Since returns the messages stored after lastEventID, oldest first
+func (l *redisEventLog) Since(ctx context.Context, userID uint, lastEventID string, limit int) ([]*Message, error) {
+	entries, err := l.client.XRangeN(ctx, eventLogKey(userID), "("+lastEventID, "+", int64(limit)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	messages := make([]*Message, 0, len(entries))
+	for _, entry := range entries {
+		event, _ := entry.Values["event"].(string)
+		data, _ := entry.Values["data"].(string)
+
+		messages = append(messages, &Message{
+			ID:    entry.ID,
+			Event: event,
+			Data:  json.RawMessage(data),
+		})
+	}
+
+	return messages, nil
+}
+
+func eventLogKey(userID uint) string {
+	return fmt.Sprintf("sse:events:%d", userID)
+}
+
+// IsValidEventID reports whether id has the form of a stream entry id
+func IsValidEventID(id string) bool {
+	_, _, ok := parseEventID(id)
+	return ok
+}
+
+// eventIDAfter reports whether id a was generated after id b
+func eventIDAfter(a, b string) bool {
+	aMs, aSeq, aOk := parseEventID(a)
+	bMs, bSeq, bOk := parseEventID(b)
+	if !aOk || !bOk {
+		return true
+	}
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+func parseEventID(id string) (uint64, uint64, bool) {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return ms, seq, true
+}
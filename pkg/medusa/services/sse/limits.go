@@ -0,0 +1,30 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrConnectionLimit = errors.New("connection limit reached")
+
+type LimitScope string
+
+const (
+	LimitScopeUser   LimitScope = "user"
+	LimitScopeGlobal LimitScope = "global"
+)
+
+// LimitError is returned by Subscribe when a connection limit is reached
+type LimitError struct {
+	Scope LimitScope `json:"scope"`
+	Limit int        `json:"limit"`
+	Open  int        `json:"open"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s connection limit reached: %d of %d open", e.Scope, e.Open, e.Limit)
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrConnectionLimit
+}
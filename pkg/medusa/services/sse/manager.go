@@ -9,9 +9,12 @@ import (
 
 type SSEManager interface {
 	Send(userID uint, message *Message) error
-	Subscribe(ctx context.Context, userID uint, clientID string) (Connection, error)
+	Subscribe(ctx context.Context, userID uint, clientID string, lastEventID string, entities []string) (Connection, error)
 	Unsubscribe(userID uint, clientID string) error
 	GetSSESubscriptions() map[string]interface{}
+	ConnectedUsers() []uint
+	Config() Config
+	Close()
 }
 
 type sseManager struct {
@@ -19,13 +22,20 @@ type sseManager struct {
 	userIndex  map[uint]map[string]*clientConn // userID -> clientID -> clientConn
 	mutex      sync.RWMutex
 	pingTicker *time.Ticker
+	config     Config
+	eventLog   EventLog
+	dropped    int64
 }
 
-func NewSSEManager() SSEManager {
+// NewSSEManager creates a manager. eventLog is optional; without it
+// messages are only delivered to connected clients and cannot be replayed.
+func NewSSEManager(config Config, eventLog EventLog) SSEManager {
 	service := &sseManager{
 		clients:    make(map[string]*clientConn),
 		userIndex:  make(map[uint]map[string]*clientConn),
 		pingTicker: time.NewTicker(30 * time.Second),
+		config:     config,
+		eventLog:   eventLog,
 	}
 
 	// Cleanup routine for dead connections
@@ -34,10 +44,46 @@ func NewSSEManager() SSEManager {
 	return service
 }
 
-func (sm *sseManager) Subscribe(ctx context.Context, userID uint, clientID string) (Connection, error) {
+// Subscribe opens a connection for the client. It fails with a
+// *LimitError when the user or the server has too many open connections.
+// When entities is not empty, change events of other entities are not
+// delivered to the connection.
+func (sm *sseManager) Subscribe(ctx context.Context, userID uint, clientID string, lastEventID string, entities []string) (Connection, error) {
+	client, err := sm.register(ctx, userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entities) > 0 {
+		client.entities = make(map[string]bool, len(entities))
+		for _, entity := range entities {
+			client.entities[entity] = true
+		}
+	}
+
+	// Replay is read after registering so messages sent in between are not
+	// lost; the connection drops the duplicates
+	if sm.eventLog != nil && IsValidEventID(lastEventID) {
+		missed, err := sm.eventLog.Since(ctx, userID, lastEventID, sm.config.ReplayLimit)
+		if err != nil {
+			sm.Unsubscribe(userID, clientID)
+			return nil, err
+		}
+		client.replay = missed
+	}
+
+	return client, nil
+}
+
+func (sm *sseManager) register(ctx context.Context, userID uint, clientID string) (*clientConn, error) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
+	if err := sm.checkLimitsUnsafe(userID, clientID); err != nil {
+		rejectedConnections.WithLabelValues(string(err.Scope)).Inc()
+		return nil, err
+	}
+
 	if existingClient, exists := sm.clients[clientID]; exists {
 		// Cancelar la conexión existente
 		existingClient.Cancel()
@@ -49,7 +95,7 @@ func (sm *sseManager) Subscribe(ctx context.Context, userID uint, clientID strin
 	client := &clientConn{
 		ID:       clientID,
 		UserID:   userID,
-		Channel:  make(chan *Message, 100), // Buffer para evitar bloqueos
+		Channel:  make(chan *Message, sm.config.BufferSize),
 		Context:  clientCtx,
 		Cancel:   cancel,
 		LastSeen: time.Now(),
@@ -63,9 +109,31 @@ func (sm *sseManager) Subscribe(ctx context.Context, userID uint, clientID strin
 
 	sm.userIndex[userID][clientID] = client
 
+	openConnections.Set(float64(len(sm.clients)))
+	connectedUsers.Set(float64(len(sm.userIndex)))
+
 	return client, nil
 }
 
+// checkLimitsUnsafe checks the connection limits for a new connection
+// (must be called with the mutex held)
+func (sm *sseManager) checkLimitsUnsafe(userID uint, clientID string) *LimitError {
+	existing, reconnecting := sm.clients[clientID]
+	if reconnecting && existing.UserID == userID {
+		return nil
+	}
+
+	if limit := sm.config.MaxConnectionsPerUser; limit > 0 && len(sm.userIndex[userID]) >= limit {
+		return &LimitError{Scope: LimitScopeUser, Limit: limit, Open: len(sm.userIndex[userID])}
+	}
+
+	if limit := sm.config.MaxConnections; limit > 0 && len(sm.clients) >= limit {
+		return &LimitError{Scope: LimitScopeGlobal, Limit: limit, Open: len(sm.clients)}
+	}
+
+	return nil
+}
+
 // Unsubscribe desuscribe un dispositivo
 func (sm *sseManager) Unsubscribe(userID uint, clientID string) error {
 	sm.mutex.Lock()
@@ -86,43 +154,74 @@ func (sm *sseManager) Unsubscribe(userID uint, clientID string) error {
 	return nil
 }
 
+// Send persists the message to the event log, when configured, and queues
+// it on every connection of the user. Connections whose queue is full are
+// disconnected instead of blocking the sender; they resume from the log.
 func (sm *sseManager) Send(userID uint, message *Message) error {
+	if sm.eventLog != nil {
+		id, err := sm.eventLog.Append(context.Background(), userID, message)
+		if err != nil {
+			return err
+		}
+		message = &Message{ID: id, Event: message.Event, Data: message.Data}
+	}
+
+	var slow []string
+
 	sm.mutex.RLock()
 	userClients, exists := sm.userIndex[userID]
 	if !exists {
 		sm.mutex.RUnlock()
+		if sm.eventLog != nil {
+			return nil
+		}
 		return fmt.Errorf("no subscribed clients for user: %d", userID)
 	}
 
-	// Prevent concurrentcy issues by copying the map
-	clients := make([]*clientConn, 0, len(userClients))
-	for _, client := range userClients {
-		clients = append(clients, client)
+	for clientID, client := range userClients {
+		select {
+		case client.Channel <- message:
+			// Notification queued
+		case <-client.Context.Done():
+			// clientConn disconnected, the cleanup routine removes it
+		default:
+			slow = append(slow, clientID)
+		}
 	}
 	sm.mutex.RUnlock()
 
-	var wg sync.WaitGroup
-	for _, client := range clients {
-		wg.Add(1)
-
-		go func(c *clientConn) {
-			defer wg.Done()
-			select {
-			case c.Channel <- message:
-				// Notification sent
-			case <-time.After(5 * time.Second):
-				// Timeout sending notification
-			case <-c.Context.Done():
-				// clientConn disconnected during send
+	if len(slow) > 0 {
+		sm.mutex.Lock()
+		for _, clientID := range slow {
+			if client, exists := sm.clients[clientID]; exists {
+				client.Cancel()
+				sm.removeClientUnsafe(clientID)
+				sm.dropped++
+				reapedConnections.WithLabelValues("slow").Inc()
 			}
-		}(client)
+		}
+		sm.mutex.Unlock()
 	}
 
-	wg.Wait()
-
 	return nil
 }
 
+// ConnectedUsers returns the users with at least one open connection
+func (sm *sseManager) ConnectedUsers() []uint {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	users := make([]uint, 0, len(sm.userIndex))
+	for userID := range sm.userIndex {
+		users = append(users, userID)
+	}
+	return users
+}
+
+func (sm *sseManager) Config() Config {
+	return sm.config
+}
+
 // removeClientUnsafe remueve un cliente (debe llamarse con mutex bloqueado)
 func (sm *sseManager) removeClientUnsafe(clientID string) {
 	client, exists := sm.clients[clientID]
@@ -141,6 +240,24 @@ func (sm *sseManager) removeClientUnsafe(clientID string) {
 			delete(sm.userIndex, client.UserID)
 		}
 	}
+
+	openConnections.Set(float64(len(sm.clients)))
+	connectedUsers.Set(float64(len(sm.userIndex)))
+}
+
+// Close disconnects every client so their streams end and the server can
+// drain. Clients reconnect to another instance and resume from the log.
+func (sm *sseManager) Close() {
+	sm.pingTicker.Stop()
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for clientID, client := range sm.clients {
+		client.Cancel()
+		sm.removeClientUnsafe(clientID)
+		reapedConnections.WithLabelValues("shutdown").Inc()
+	}
 }
 
 func (sm *sseManager) cleanupRoutine() {
@@ -155,9 +272,10 @@ func (sm *sseManager) cleanupRoutine() {
 				toRemove = append(toRemove, clientID)
 			default:
 				// Check if the connection is too old
-				if now.Sub(client.LastSeen) > 2*time.Minute {
+				if now.Sub(client.LastSeen) > sm.config.IdleTimeout {
 					client.Cancel()
 					toRemove = append(toRemove, clientID)
+					reapedConnections.WithLabelValues("idle").Inc()
 				}
 			}
 		}
@@ -178,7 +296,8 @@ func (sm *sseManager) GetSSESubscriptions() map[string]interface{} {
 	deviceCount := len(sm.clients)
 
 	return map[string]interface{}{
-		"users":   userCount,
-		"devices": deviceCount,
+		"users":        userCount,
+		"devices":      deviceCount,
+		"slow_dropped": sm.dropped,
 	}
 }
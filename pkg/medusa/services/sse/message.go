@@ -1,6 +1,56 @@
 package sse
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
 type Message struct {
+	ID    string `json:"id,omitempty"`
 	Event string `json:"event"`
 	Data  any    `json:"data"`
 }
+
+// Write writes the message in SSE wire format, including the id line used
+// by clients to resume with Last-Event-ID
+func (m *Message) Write(w io.Writer) error {
+	var builder strings.Builder
+
+	if m.ID != "" {
+		fmt.Fprintf(&builder, "id: %s\n", m.ID)
+	}
+	if m.Event != "" {
+		fmt.Fprintf(&builder, "event: %s\n", m.Event)
+	}
+
+	var data string
+	switch v := m.Data.(type) {
+	case string:
+		data = v
+	case json.RawMessage:
+		data = string(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&builder, "data: %s\n", line)
+	}
+	builder.WriteString("\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// WriteComment writes an SSE comment line. Clients ignore comments, which
+// makes them a cheap keep-alive for proxies that drop idle connections.
+func WriteComment(w io.Writer, comment string) error {
+	_, err := fmt.Fprintf(w, ": %s\n\n", comment)
+	return err
+}
@@ -0,0 +1,28 @@
+package sse
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	openConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sse_open_connections",
+		Help: "Number of open SSE connections",
+	})
+
+	connectedUsers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sse_connected_users",
+		Help: "Number of users with at least one open SSE connection",
+	})
+
+	rejectedConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sse_connections_rejected_total",
+		Help: "SSE connections rejected by a connection limit",
+	}, []string{"scope"})
+
+	reapedConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sse_connections_reaped_total",
+		Help: "SSE connections closed by the server",
+	}, []string{"reason"})
+)
@@ -0,0 +1,71 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RelayChannel is the Redis channel other processes publish on to reach
+// the clients connected to the SSE server
+const RelayChannel = "sse:relay"
+
+type relayEnvelope struct {
+	UserID  uint     `json:"user_id"`
+	Message *Message `json:"message"`
+}
+
+// Publisher sends messages to users from processes that don't hold the
+// SSE connections
+type Publisher interface {
+	Send(ctx context.Context, userID uint, message *Message) error
+}
+
+type redisPublisher struct {
+	client *redis.Client
+}
+
+func NewRedisPublisher(client *redis.Client) Publisher {
+	return &redisPublisher{client: client}
+}
+
+func (p *redisPublisher) Send(ctx context.Context, userID uint, message *Message) error {
+	payload, err := json.Marshal(relayEnvelope{UserID: userID, Message: message})
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.Publish(ctx, RelayChannel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to relay sse message: %w", err)
+	}
+	return nil
+}
+
+// StartRelay forwards the messages published with a Publisher to the
+// connections of manager until ctx is done
+func StartRelay(ctx context.Context, client *redis.Client, manager SSEManager) {
+	subscription := client.Subscribe(ctx, RelayChannel)
+
+	go func() {
+		defer subscription.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case received, ok := <-subscription.Channel():
+				if !ok {
+					return
+				}
+
+				var envelope relayEnvelope
+				if err := json.Unmarshal([]byte(received.Payload), &envelope); err != nil || envelope.Message == nil {
+					continue
+				}
+				manager.Send(envelope.UserID, envelope.Message)
+			}
+		}
+	}()
+}
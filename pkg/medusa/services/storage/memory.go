@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	content     []byte
+	contentType string
+	etag        string
+}
+
+// memoryStorage keeps objects in memory, for running without a bucket.
+// ETags are the MD5 of the content, as S3 reports them for single part
+// uploads, so the same upload always gives the same result.
+type memoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+// NewMemoryStorage creates an in-memory storage. Objects are lost when the
+// process stops.
+func NewMemoryStorage() FileStorage {
+	return &memoryStorage{objects: make(map[string]*memoryObject)}
+}
+
+func (s *memoryStorage) Upload(key string, reader io.Reader, contentType string, size int64) (*FileResult, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	sum := md5.Sum(content)
+	object := &memoryObject{
+		content:     content,
+		contentType: contentType,
+		etag:        `"` + hex.EncodeToString(sum[:]) + `"`,
+	}
+
+	s.mu.Lock()
+	s.objects[key] = object
+	s.mu.Unlock()
+
+	return &FileResult{
+		Key:         key,
+		Size:        int64(len(content)),
+		ContentType: contentType,
+		Etag:        object.etag,
+		Url:         s.GetPublicURL(key),
+	}, nil
+}
+
+func (s *memoryStorage) Download(key string) (io.ReadCloser, error) {
+	object, err := s.get(key)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(object.content)), nil
+}
+
+func (s *memoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStorage) GetPresignedURL(key string, expiry time.Duration) (string, error) {
+	return fmt.Sprintf("%s?expires=%d", s.GetPublicURL(key), int64(expiry.Seconds())), nil
+}
+
+func (s *memoryStorage) GetPublicURL(key string) string {
+	return "https://storage.mock.local/" + key
+}
+
+func (s *memoryStorage) BulkDelete(keys []string) error {
+	s.mu.Lock()
+	for _, key := range keys {
+		delete(s.objects, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStorage) GetFileForDownload(key string) (*FileDownload, error) {
+	object, err := s.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileDownload{
+		Content:     io.NopCloser(bytes.NewReader(object.content)),
+		ContentType: object.contentType,
+		Size:        int64(len(object.content)),
+	}, nil
+}
+
+func (s *memoryStorage) List(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	// Buckets list keys in lexicographic order
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *memoryStorage) CheckAccess() error {
+	return nil
+}
+
+func (s *memoryStorage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryStorage) get(key string) (*memoryObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, exists := s.objects[key]
+	if !exists {
+		return nil, fmt.Errorf("failed to get file: no object %s", key)
+	}
+	return object, nil
+}
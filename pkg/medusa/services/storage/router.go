@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/imlargo/go-api/pkg/medusa/core/tenancy"
+)
+
+// OwnerBucket is the bucket an owner brings for their own files
+type OwnerBucket struct {
+	Provider StorageProvider
+	Config   StorageConfig
+}
+
+// OwnerBucketResolver returns the bucket configured for an owner, or nil
+// when the owner uses the default bucket
+type OwnerBucketResolver func(ctx context.Context, ownerID uint) (*OwnerBucket, error)
+
+// StorageRouter resolves the storage that holds an owner's files
+type StorageRouter interface {
+	ForOwner(ctx context.Context, ownerID uint) (FileStorage, error)
+	Default() FileStorage
+	Invalidate(ctx context.Context, ownerID uint)
+}
+
+type storageRouter struct {
+	defaultStorage FileStorage
+	resolve        OwnerBucketResolver
+
+	mu      sync.RWMutex
+	clients map[string]FileStorage
+}
+
+func NewStorageRouter(defaultStorage FileStorage, resolve OwnerBucketResolver) StorageRouter {
+	return &storageRouter{
+		defaultStorage: defaultStorage,
+		resolve:        resolve,
+		clients:        make(map[string]FileStorage),
+	}
+}
+
+// ForOwner returns the owner's bucket when one is configured, otherwise
+// the default storage. Clients are cached until Invalidate is called.
+func (r *storageRouter) ForOwner(ctx context.Context, ownerID uint) (FileStorage, error) {
+	key := clientKey(ctx, ownerID)
+
+	r.mu.RLock()
+	client, ok := r.clients[key]
+	r.mu.RUnlock()
+	if ok {
+		return client, nil
+	}
+
+	bucket, err := r.resolve(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage for owner %d: %w", ownerID, err)
+	}
+
+	client = r.defaultStorage
+	if bucket != nil {
+		client, err = NewFileStorage(bucket.Provider, bucket.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize storage for owner %d: %w", ownerID, err)
+		}
+	}
+
+	r.mu.Lock()
+	r.clients[key] = client
+	r.mu.Unlock()
+
+	return client, nil
+}
+
+func (r *storageRouter) Default() FileStorage {
+	return r.defaultStorage
+}
+
+// Invalidate drops the cached client of an owner, so the next call picks
+// up configuration changes
+func (r *storageRouter) Invalidate(ctx context.Context, ownerID uint) {
+	r.mu.Lock()
+	delete(r.clients, clientKey(ctx, ownerID))
+	r.mu.Unlock()
+}
+
+// clientKey keeps the clients of each tenant apart, owner ids repeat across
+// tenant schemas
+func clientKey(ctx context.Context, ownerID uint) string {
+	return tenancy.Key(ctx, strconv.FormatUint(uint64(ownerID), 10))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -21,6 +22,9 @@ type FileStorage interface {
 	GetPublicURL(key string) string
 	BulkDelete(keys []string) error
 	GetFileForDownload(key string) (*FileDownload, error)
+	List(prefix string) ([]string, error)
+	CheckAccess() error
+	Ping(ctx context.Context) error
 }
 
 type fileStorage struct {
@@ -251,6 +255,70 @@ func (s *fileStorage) GetFileForDownload(key string) (*FileDownload, error) {
 	}, nil
 }
 
+// List returns the keys of every object under prefix
+func (s *fileStorage) List(prefix string) ([]string, error) {
+	ctx := context.Background()
+
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.BucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+
+	return keys, nil
+}
+
+// CheckAccess verifies the bucket exists and the credentials can write and
+// delete objects in it
+func (s *fileStorage) CheckAccess() error {
+	ctx := context.Background()
+
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.config.BucketName),
+	})
+	if err != nil {
+		return fmt.Errorf("bucket is not reachable: %w", err)
+	}
+
+	probeKey := fmt.Sprintf(".medusa-access-check-%d", time.Now().UnixNano())
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.config.BucketName),
+		Key:           aws.String(probeKey),
+		Body:          strings.NewReader("ok"),
+		ContentLength: aws.Int64(2),
+	})
+	if err != nil {
+		return fmt.Errorf("bucket is not writable: %w", err)
+	}
+
+	if err := s.Delete(probeKey); err != nil {
+		return fmt.Errorf("bucket does not allow deletes: %w", err)
+	}
+
+	return nil
+}
+
+// Ping checks the bucket is reachable without writing to it
+func (s *fileStorage) Ping(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.config.BucketName),
+	})
+	if err != nil {
+		return fmt.Errorf("bucket is not reachable: %w", err)
+	}
+	return nil
+}
+
 // clearStringQuotes removes quotes from strings (commonly found in ETags)
 func clearStringQuotes(s string) string {
 	return quotesRegex.ReplaceAllString(s, "")
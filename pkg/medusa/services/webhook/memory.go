@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// MemorySender keeps requests in memory instead of posting them. URLs are
+// still validated so misconfigured endpoints fail as they would.
+type MemorySender struct {
+	mu   sync.Mutex
+	sent []Request
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(ctx context.Context, request *Request) (int, error) {
+	if err := ValidateURL(request.URL); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, *request)
+	s.mu.Unlock()
+	return http.StatusOK, nil
+}
+
+// Sent returns a copy of the requests sent so far
+func (s *MemorySender) Sent() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.sent...)
+}